@@ -15,6 +15,10 @@ import (
 // Options 分析选项
 type Options struct {
 	ShowHistory bool
+	// 定期生成检查点的周期，为空时仅使用账本中的检查点
+	Periodic Period
+	// 计算各自然周期表现的周期，为空时不计算
+	Performance Period
 }
 
 // Analyse 分析资产数据
@@ -28,58 +32,76 @@ func Analyse(_ context.Context, assets *v1.Assets, opts Options) (report.Report,
 	}
 	r.AddGoodsInfo(assets.Goods...)
 
+	now := time.Now()
+	checkpoints, err := periodicCheckpoints(assets, opts.Periodic, now)
+	if err != nil {
+		return nil, err
+	}
+	r.performance, err = computePerformance(assets, opts.Performance, now)
+	if err != nil {
+		return nil, fmt.Errorf("compute performance error: %w", err)
+	}
+
 	// 统计所有产品持仓情况
 	allGoods := map[string]*Goods{}
 	checkpointI := -1
 	var checkpoint *CheckpointReport
 	var checkpointGoods map[string]*Goods
+	// 记录当前检查点
+	recordCheckpoint := func() {
+		for _, g := range checkpointGoods {
+			checkpoint.Report.goods = append(checkpoint.Report.goods, *g)
+		}
+		r.checkpoints = append(r.checkpoints, *checkpoint)
+	}
+	// 记录当前检查点，并换下一个检查点，检查点用完后为截至当前的检查点
+	nextCheckpoint := func() {
+		if checkpoint != nil {
+			recordCheckpoint()
+		}
+
+		checkpointI++
+		if checkpointI < len(checkpoints) {
+			checkpoint = &CheckpointReport{
+				Date: checkpoints[checkpointI].Date,
+			}
+			for _, info := range checkpoints[checkpointI].Goods {
+				detail, _ := r.GoodsInfo(info.Name)
+				checkpoint.Report.AddGoodsInfo(v1.GoodsInfo{
+					Name:         info.Name,
+					Code:         detail.Code,
+					Risk:         detail.Risk,
+					Price:        info.Price,
+					Base:         detail.Base,
+					IgnoreReturn: detail.IgnoreReturn,
+				})
+			}
+		} else {
+			checkpoint = &CheckpointReport{
+				Date: v1.Date{Time: time.Now()},
+			}
+			checkpoint.Report.AddGoodsInfo(assets.Goods...)
+		}
+		checkpointGoods = map[string]*Goods{}
+	}
 	for _, t := range assets.Transactions {
 		addToGoods(allGoods, t.From, true, t)
 		addToGoods(allGoods, t.To, false, t)
 
-		if checkpoint == nil || (checkpointI < len(assets.Checkpoints) && t.Date.After(checkpoint.Date.Time)) {
-			// 记录当前检查点
-			if checkpoint != nil {
-				for _, g := range checkpointGoods {
-					checkpoint.Report.goods = append(checkpoint.Report.goods, *g)
-				}
-				r.checkpoints = append(r.checkpoints, *checkpoint)
-			}
-
-			// 换下一个检查点
-			checkpointI++
-			if checkpointI < len(assets.Checkpoints) {
-				checkpoint = &CheckpointReport{
-					Date: assets.Checkpoints[checkpointI].Date,
-				}
-				for _, info := range assets.Checkpoints[checkpointI].Goods {
-					detail, _ := r.GoodsInfo(info.Name)
-					checkpoint.Report.AddGoodsInfo(v1.GoodsInfo{
-						Name:         info.Name,
-						Code:         detail.Code,
-						Risk:         detail.Risk,
-						Price:        info.Price,
-						Base:         detail.Base,
-						IgnoreReturn: detail.IgnoreReturn,
-					})
-				}
-			} else {
-				checkpoint = &CheckpointReport{
-					Date: v1.Date{Time: time.Now()},
-				}
-				checkpoint.Report.AddGoodsInfo(assets.Goods...)
-			}
-			checkpointGoods = map[string]*Goods{}
+		// 两笔交易之间可能有多个检查点，依次切换到交易所属的检查点
+		for checkpoint == nil || (checkpointI < len(checkpoints) && t.Date.After(checkpoint.Date.Time)) {
+			nextCheckpoint()
 		}
 
 		addToGoods(checkpointGoods, t.From, true, t)
 		addToGoods(checkpointGoods, t.To, false, t)
 	}
 	if checkpoint != nil {
-		for _, g := range checkpointGoods {
-			checkpoint.Report.goods = append(checkpoint.Report.goods, *g)
+		// 最后一笔交易之后的检查点没有交易，持仓由上一检查点结转
+		for checkpointI < len(checkpoints) {
+			nextCheckpoint()
 		}
-		r.checkpoints = append(r.checkpoints, *checkpoint)
+		recordCheckpoint()
 	}
 
 	// 添加产品记录
@@ -88,8 +110,7 @@ func Analyse(_ context.Context, assets *v1.Assets, opts Options) (report.Report,
 	}
 
 	// 补充完成
-	err := r.Complete()
-	if err != nil {
+	if err := r.Complete(); err != nil {
 		return nil, fmt.Errorf("complete report error: %w", err)
 	}
 
@@ -0,0 +1,199 @@
+package assets
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// Period 定期检查点的周期
+type Period string
+
+// Period 的可选值
+const (
+	PeriodNone    Period = ""
+	PeriodMonth   Period = "month"
+	PeriodQuarter Period = "quarter"
+	PeriodYear    Period = "year"
+)
+
+// NewCheckpoint 生成指定日期的检查点
+//
+// 包含当日持有的商品，以及自上一检查点以来的交易涉及的商品（计算该期损益需要其价格）。
+// 价格取价格记录和已有检查点中当日及之前最近的价格，没有时使用商品信息中的价格
+func NewCheckpoint(assets *v1.Assets, date v1.Date) (v1.Checkpoint, error) {
+	return newCheckpoint(assets, assets.Checkpoints, newPriceBook(assets), date)
+}
+
+// newCheckpoint 基于已有检查点 checkpoints 生成指定日期的检查点
+func newCheckpoint(assets *v1.Assets, checkpoints []v1.Checkpoint, prices *priceBook, date v1.Date) (v1.Checkpoint, error) {
+	// 上一检查点
+	var last time.Time
+	for _, cp := range checkpoints {
+		if cp.Date.Before(date.Time) && cp.Date.After(last) {
+			last = cp.Date.Time
+		}
+	}
+
+	// 当日持仓及本期交易涉及的商品
+	type key struct{ custodian, name string }
+	quantities := map[key]decimal.Decimal{}
+	included := map[string]bool{}
+	for _, t := range assets.Transactions {
+		if t.Date.After(date.Time) {
+			continue
+		}
+		if t.From != nil {
+			k := key{custodian: t.From.Custodian, name: t.From.Name}
+			quantities[k] = quantities[k].Sub(t.From.Quantity)
+		}
+		if t.To != nil {
+			k := key{custodian: t.To.Custodian, name: t.To.Name}
+			quantities[k] = quantities[k].Add(t.To.Quantity)
+		}
+		if t.Date.After(last) {
+			for _, g := range []*v1.Goods{t.From, t.To} {
+				if g != nil {
+					included[g.Name] = true
+				}
+			}
+		}
+	}
+	for k, quantity := range quantities {
+		if !quantity.IsZero() {
+			included[k.name] = true
+		}
+	}
+
+	// 按商品信息中的顺序排列，不在商品信息中的按名称排序
+	var names []string
+	for _, info := range assets.Goods {
+		if included[info.Name] {
+			names = append(names, info.Name)
+			delete(included, info.Name)
+		}
+	}
+	others := make([]string, 0, len(included))
+	for name := range included {
+		others = append(others, name)
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	ret := v1.Checkpoint{Date: date}
+	for _, name := range names {
+		price, ok := prices.at(name, date.Time)
+		if !ok {
+			return v1.Checkpoint{}, fmt.Errorf("price of goods %q at %s not found", name, date)
+		}
+		ret.Goods = append(ret.Goods, v1.CheckpointGoodsInfo{Name: name, Price: price})
+	}
+	return ret, nil
+}
+
+// periodicCheckpoints 生成从第一笔交易到 now 前一天的各周期末的检查点，已有检查点的日期跳过
+//
+// 返回包含已有检查点在内的所有检查点，按日期排序
+func periodicCheckpoints(assets *v1.Assets, period Period, now time.Time) ([]v1.Checkpoint, error) {
+	ret := append([]v1.Checkpoint(nil), assets.Checkpoints...)
+	if period == PeriodNone || len(assets.Transactions) == 0 {
+		return ret, nil
+	}
+	months, err := periodMonths(period)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]bool{}
+	for _, cp := range assets.Checkpoints {
+		existing[cp.Date.String()] = true
+	}
+	first := assets.Transactions[0].Date.Time
+	for _, t := range assets.Transactions {
+		if t.Date.Before(first) {
+			first = t.Date.Time
+		}
+	}
+
+	prices := newPriceBook(assets)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, first.Location())
+	// 第一个周期末：第一笔交易所在周期的最后一天
+	start := periodStart(first, months)
+	for end := start.AddDate(0, months, -1); end.Before(today); end = start.AddDate(0, months, -1) {
+		date := v1.Date{Time: end}
+		if !existing[date.String()] {
+			sortCheckpoints(ret)
+			cp, err := newCheckpoint(assets, ret, prices, date)
+			if err != nil {
+				return nil, fmt.Errorf("generate checkpoint at %s error: %w", date, err)
+			}
+			ret = append(ret, cp)
+		}
+		start = start.AddDate(0, months, 0)
+	}
+	sortCheckpoints(ret)
+	return ret, nil
+}
+
+// sortCheckpoints 按日期对检查点排序
+func sortCheckpoints(checkpoints []v1.Checkpoint) {
+	sort.SliceStable(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Date.Before(checkpoints[j].Date.Time)
+	})
+}
+
+// priceBook 商品价格查询
+type priceBook struct {
+	// 商品信息中的价格
+	current map[string]decimal.Decimal
+	// 各商品的价格序列，按日期排序
+	series map[string][]v1.PriceRecord
+}
+
+// newPriceBook 创建 priceBook
+//
+// 价格序列包括价格记录和检查点中的价格，同一日期两者都有时以价格记录为准
+func newPriceBook(assets *v1.Assets) *priceBook {
+	b := &priceBook{
+		current: map[string]decimal.Decimal{},
+		series:  map[string][]v1.PriceRecord{},
+	}
+	for _, info := range assets.Goods {
+		b.current[info.Name] = info.Price
+	}
+	for _, cp := range assets.Checkpoints {
+		for _, g := range cp.Goods {
+			b.series[g.Name] = append(b.series[g.Name], v1.PriceRecord{Date: cp.Date, Name: g.Name, Price: g.Price})
+		}
+	}
+	// 在检查点价格之后添加，排序后同一日期的价格记录在后，查询时优先
+	for _, p := range assets.Prices {
+		b.series[p.Name] = append(b.series[p.Name], p)
+	}
+	for _, s := range b.series {
+		sort.SliceStable(s, func(i, j int) bool {
+			return s[i].Date.Before(s[j].Date.Time)
+		})
+	}
+	return b
+}
+
+// at 返回商品在指定日期的价格
+//
+// 取价格序列中该日期及之前最近的价格，没有时使用商品信息中的价格
+func (b *priceBook) at(name string, date time.Time) (decimal.Decimal, bool) {
+	s := b.series[name]
+	// 第一个日期在 date 之后的价格
+	i := sort.Search(len(s), func(i int) bool {
+		return s[i].Date.After(date)
+	})
+	if i > 0 {
+		return s[i-1].Price, true
+	}
+	price, ok := b.current[name]
+	return price, ok
+}
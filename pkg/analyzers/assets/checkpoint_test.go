@@ -0,0 +1,109 @@
+package assets
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// testDate 解析 YYYY-MM-DD 格式的日期
+func testDate(t *testing.T, s string) v1.Date {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		t.Fatalf("parse date %q error: %v", s, err)
+	}
+	return v1.Date{Time: d}
+}
+
+// TestNewCheckpoint 测试 NewCheckpoint 方法
+func TestNewCheckpoint(t *testing.T) {
+	assets := &v1.Assets{
+		Goods: []v1.GoodsInfo{
+			{Name: "CNY", Price: decimal.New(1, 0), Base: true},
+			{Name: "USD", Price: decimal.RequireFromString("7.2"), Base: true},
+			{Name: "AAPL", Price: decimal.New(200, 0)},
+		},
+		Transactions: []v1.Transaction{
+			{Date: testDate(t, "2024-01-02"), To: &v1.Goods{Name: "CNY", Custodian: "Bank", Quantity: decimal.New(10000, 0)}},
+			{Date: testDate(t, "2024-01-03"), To: &v1.Goods{Name: "USD", Custodian: "IB", Quantity: decimal.New(100, 0)}},
+			{
+				Date: testDate(t, "2024-02-02"),
+				From: &v1.Goods{Name: "USD", Custodian: "IB", Quantity: decimal.New(100, 0)},
+				To:   &v1.Goods{Name: "AAPL", Custodian: "IB", Quantity: decimal.New(1, 0)},
+			},
+		},
+		Checkpoints: []v1.Checkpoint{{
+			Date: testDate(t, "2024-01-31"),
+			Goods: []v1.CheckpointGoodsInfo{
+				{Name: "CNY", Price: decimal.New(1, 0)},
+				{Name: "USD", Price: decimal.RequireFromString("7.1")},
+			},
+		}},
+		Prices: []v1.PriceRecord{
+			{Date: testDate(t, "2024-02-15"), Name: "AAPL", Price: decimal.New(180, 0)},
+			{Date: testDate(t, "2024-01-10"), Name: "AAPL", Price: decimal.New(170, 0)},
+		},
+	}
+
+	cases := []struct {
+		date     string
+		expected []v1.CheckpointGoodsInfo
+	}{
+		// 仅包含当日持有的商品，没有价格序列时使用商品信息中的价格
+		{date: "2024-01-02", expected: []v1.CheckpointGoodsInfo{
+			{Name: "CNY", Price: decimal.New(1, 0)},
+		}},
+		// USD 已全部卖出，但本期交易涉及，仍需包含；价格取价格记录和检查点中当日及之前最近的价格
+		{date: "2024-02-20", expected: []v1.CheckpointGoodsInfo{
+			{Name: "CNY", Price: decimal.New(1, 0)},
+			{Name: "USD", Price: decimal.RequireFromString("7.1")},
+			{Name: "AAPL", Price: decimal.New(180, 0)},
+		}},
+		{date: "2024-02-14", expected: []v1.CheckpointGoodsInfo{
+			{Name: "CNY", Price: decimal.New(1, 0)},
+			{Name: "USD", Price: decimal.RequireFromString("7.1")},
+			{Name: "AAPL", Price: decimal.New(170, 0)},
+		}},
+	}
+	for _, c := range cases {
+		cp, err := NewCheckpoint(assets, testDate(t, c.date))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.date, err)
+			continue
+		}
+		if !reflect.DeepEqual(cp.Goods, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.date, c.expected, cp.Goods)
+		}
+	}
+
+	// 定期检查点，跳过已有检查点的日期
+	checkpoints, err := periodicCheckpoints(assets, PeriodMonth, testDate(t, "2024-03-31").Time)
+	if err != nil {
+		t.Fatalf("generate periodic checkpoints error: %v", err)
+	}
+	var dates []string
+	for _, cp := range checkpoints {
+		dates = append(dates, cp.Date.String())
+	}
+	if expected := []string{"2024-01-31", "2024-02-29"}; !reflect.DeepEqual(dates, expected) {
+		t.Errorf("expected checkpoints at %v, got %v", expected, dates)
+	}
+	// 上一检查点之后 USD 已卖出，仍需 USD 的价格计算本期损益
+	if n := len(checkpoints[1].Goods); n != 3 {
+		t.Errorf("expected 3 goods in generated checkpoint, got %d", n)
+	}
+
+	// 分析时使用定期检查点
+	r, err := Analyse(context.Background(), assets, Options{Periodic: PeriodQuarter})
+	if err != nil {
+		t.Fatalf("analyse error: %v", err)
+	}
+	if n := len(r.(*Report).Checkpoints()); n < 3 {
+		t.Errorf("expected at least 3 checkpoints, got %d", n)
+	}
+}
@@ -0,0 +1,176 @@
+package assets
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// PeriodPerformance 一个自然周期内的资产表现
+type PeriodPerformance struct {
+	// 周期名，如 2024 、 2024-Q1 、 2024-01
+	Period string `json:"period" yaml:"period"`
+	// 开始日期
+	Start v1.Date `json:"start" yaml:"start"`
+	// 结束日期（当前周期为当天）
+	End v1.Date `json:"end" yaml:"end"`
+	// 期初价值
+	StartValue decimal.Decimal `json:"startValue" yaml:"startValue"`
+	// 净投入（流入减流出）
+	NetContributions decimal.Decimal `json:"netContributions" yaml:"netContributions"`
+	// 期末价值
+	EndValue decimal.Decimal `json:"endValue" yaml:"endValue"`
+	// 损益
+	ProfitAndLoss decimal.Decimal `json:"profitAndLoss" yaml:"profitAndLoss"`
+	// 收益率（ Modified Dietz ）
+	RateOfReturn decimal.Decimal `json:"rateOfReturn" yaml:"rateOfReturn"`
+}
+
+// periodMonths 返回周期包含的月数
+func periodMonths(period Period) (int, error) {
+	switch period {
+	case PeriodMonth:
+		return 1, nil
+	case PeriodQuarter:
+		return 3, nil
+	case PeriodYear:
+		return 12, nil
+	}
+	return 0, fmt.Errorf("unsupported period: %q", period)
+}
+
+// periodStart 返回 t 所在周期的第一天
+func periodStart(t time.Time, months int) time.Time {
+	return time.Date(t.Year(), t.Month()-(t.Month()-1)%time.Month(months), 1, 0, 0, 0, 0, t.Location())
+}
+
+// periodName 返回从 start 开始的周期的名称
+func periodName(start time.Time, period Period) string {
+	switch period {
+	case PeriodMonth:
+		return start.Format("2006-01")
+	case PeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	}
+	return start.Format("2006")
+}
+
+// computePerformance 计算从第一笔交易所在周期到 now 所在周期的各周期表现
+//
+// 没有源商品或目标商品的交易视为资产的流入或流出，按交易日的价格计入净投入，其它交易（如买卖、分红）为资产内部变化。
+// 价格取价格记录和检查点中当日及之前最近的价格，没有时使用商品信息中的价格。
+// 收益率为 Modified Dietz 收益率，即损益除以期初价值与按剩余天数加权的净投入之和
+func computePerformance(assets *v1.Assets, period Period, now time.Time) ([]PeriodPerformance, error) {
+	if period == PeriodNone || len(assets.Transactions) == 0 {
+		return nil, nil
+	}
+	months, err := periodMonths(period)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := newPriceBook(assets)
+	base := map[string]bool{}
+	for _, info := range assets.Goods {
+		base[info.Name] = info.Base
+	}
+	type key struct{ custodian, name string }
+	quantities := map[key]decimal.Decimal{}
+	// value 返回当前持仓在指定日期的价值，不含负债（负的基础商品）
+	value := func(date time.Time) (decimal.Decimal, error) {
+		total := decimal.Zero
+		for k, quantity := range quantities {
+			if quantity.IsZero() {
+				continue
+			}
+			price, ok := prices.at(k.name, date)
+			if !ok {
+				return decimal.Zero, fmt.Errorf("price of goods %q at %s not found", k.name, date.Format(time.DateOnly))
+			}
+			v := quantity.Mul(price)
+			if !base[k.name] || v.IsPositive() {
+				total = total.Add(v)
+			}
+		}
+		return total, nil
+	}
+	// flow 返回商品在交易日的价值
+	flow := func(g *v1.Goods, date time.Time) (decimal.Decimal, error) {
+		price, ok := prices.at(g.Name, date)
+		if !ok {
+			return decimal.Zero, fmt.Errorf("price of goods %q at %s not found", g.Name, date.Format(time.DateOnly))
+		}
+		return g.Quantity.Mul(price), nil
+	}
+
+	transactions := assets.Transactions
+	first := transactions[0].Date.Time
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, first.Location())
+
+	var ret []PeriodPerformance
+	i := 0
+	for start := periodStart(first, months); !start.After(today); start = start.AddDate(0, months, 0) {
+		end := start.AddDate(0, months, -1)
+		if end.After(today) {
+			end = today
+		}
+		// 期初价值按前一天的价格计算
+		valueDate := start.AddDate(0, 0, -1)
+		startValue, err := value(valueDate)
+		if err != nil {
+			return nil, err
+		}
+		days := decimal.NewFromInt(int64(end.Sub(valueDate).Hours() / 24))
+
+		netContributions := decimal.Zero
+		weightedContributions := decimal.Zero
+		for ; i < len(transactions) && !transactions[i].Date.After(end); i++ {
+			t := transactions[i]
+			var amount decimal.Decimal
+			switch {
+			case t.From == nil && t.To != nil:
+				amount, err = flow(t.To, t.Date.Time)
+			case t.From != nil && t.To == nil:
+				amount, err = flow(t.From, t.Date.Time)
+				amount = amount.Neg()
+			}
+			if err != nil {
+				return nil, err
+			}
+			netContributions = netContributions.Add(amount)
+			weight := decimal.NewFromInt(int64(end.Sub(t.Date.Time).Hours() / 24)).Div(days)
+			weightedContributions = weightedContributions.Add(amount.Mul(weight))
+
+			if t.From != nil {
+				k := key{custodian: t.From.Custodian, name: t.From.Name}
+				quantities[k] = quantities[k].Sub(t.From.Quantity)
+			}
+			if t.To != nil {
+				k := key{custodian: t.To.Custodian, name: t.To.Name}
+				quantities[k] = quantities[k].Add(t.To.Quantity)
+			}
+		}
+
+		endValue, err := value(end)
+		if err != nil {
+			return nil, err
+		}
+		p := PeriodPerformance{
+			Period:           periodName(start, period),
+			Start:            v1.Date{Time: start},
+			End:              v1.Date{Time: end},
+			StartValue:       startValue,
+			NetContributions: netContributions,
+			EndValue:         endValue,
+			ProfitAndLoss:    endValue.Sub(startValue).Sub(netContributions),
+		}
+		if denominator := startValue.Add(weightedContributions); denominator.IsPositive() {
+			p.RateOfReturn = p.ProfitAndLoss.DivRound(denominator, 6)
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
@@ -0,0 +1,86 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestComputePerformance 测试 computePerformance 方法
+func TestComputePerformance(t *testing.T) {
+	assets := &v1.Assets{
+		Goods: []v1.GoodsInfo{
+			{Name: "CNY", Price: decimal.New(1, 0), Base: true},
+			{Name: "FUND", Price: decimal.New(120, 0)},
+		},
+		Transactions: []v1.Transaction{
+			{Date: testDate(t, "2024-01-01"), To: &v1.Goods{Name: "CNY", Quantity: decimal.New(1000, 0)}},
+			{
+				Date: testDate(t, "2024-01-01"),
+				From: &v1.Goods{Name: "CNY", Quantity: decimal.New(1000, 0)},
+				To:   &v1.Goods{Name: "FUND", Quantity: decimal.New(10, 0)},
+			},
+			{Date: testDate(t, "2024-02-15"), To: &v1.Goods{Name: "CNY", Quantity: decimal.New(500, 0)}},
+			{Date: testDate(t, "2024-02-20"), From: &v1.Goods{Name: "CNY", Quantity: decimal.New(200, 0)}},
+		},
+		Prices: []v1.PriceRecord{
+			{Date: testDate(t, "2024-01-01"), Name: "FUND", Price: decimal.New(100, 0)},
+			{Date: testDate(t, "2024-01-31"), Name: "FUND", Price: decimal.New(110, 0)},
+		},
+	}
+
+	ret, err := computePerformance(assets, PeriodMonth, testDate(t, "2024-02-10").Time)
+	if err != nil {
+		t.Fatalf("compute performance error: %v", err)
+	}
+	if len(ret) != 2 {
+		t.Fatalf("expected 2 periods, got %d", len(ret))
+	}
+
+	// 1 月：投入 1000 ，期末 10*110
+	jan := ret[0]
+	if jan.Period != "2024-01" || jan.End.String() != "2024-01-31" {
+		t.Errorf("unexpected period: %s ~ %s (%s)", jan.Start, jan.End, jan.Period)
+	}
+	for name, c := range map[string][2]decimal.Decimal{
+		"startValue":       {jan.StartValue, decimal.Zero},
+		"netContributions": {jan.NetContributions, decimal.New(1000, 0)},
+		"endValue":         {jan.EndValue, decimal.New(1100, 0)},
+		"profitAndLoss":    {jan.ProfitAndLoss, decimal.New(100, 0)},
+		// 100 / (1000 * 30/31)
+		"rateOfReturn": {jan.RateOfReturn, decimal.RequireFromString("0.103333")},
+	} {
+		if !c[0].Equal(c[1]) {
+			t.Errorf("2024-01 %s: expected %s, got %s", name, c[1], c[0])
+		}
+	}
+
+	// 2 月为当前周期，截至当天，之后的交易不计入
+	feb := ret[1]
+	if feb.End.String() != "2024-02-10" || !feb.NetContributions.IsZero() || !feb.StartValue.Equal(jan.EndValue) {
+		t.Errorf("unexpected current period: %+v", feb)
+	}
+
+	// 检查点中的价格也计入价格序列
+	assets.Checkpoints = []v1.Checkpoint{{
+		Date: testDate(t, "2024-02-29"),
+		Goods: []v1.CheckpointGoodsInfo{
+			{Name: "CNY", Price: decimal.New(1, 0)},
+			{Name: "FUND", Price: decimal.New(130, 0)},
+		},
+	}}
+	if ret, err := computePerformance(assets, PeriodMonth, testDate(t, "2024-03-10").Time); err != nil {
+		t.Errorf("compute performance with checkpoints error: %v", err)
+	} else if len(ret) != 3 || !ret[1].EndValue.Equal(decimal.New(1600, 0)) {
+		t.Errorf("unexpected performance with checkpoints: %+v", ret)
+	}
+	assets.Checkpoints = nil
+
+	if ret, err := computePerformance(assets, PeriodYear, testDate(t, "2024-12-31").Time); err != nil {
+		t.Errorf("compute yearly performance error: %v", err)
+	} else if len(ret) != 1 || ret[0].Period != "2024" || !ret[0].NetContributions.Equal(decimal.New(1300, 0)) {
+		t.Errorf("unexpected yearly performance: %+v", ret)
+	}
+}
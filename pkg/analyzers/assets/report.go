@@ -30,6 +30,7 @@ type Report struct {
 	annualizedRateOfReturn decimal.Decimal
 
 	checkpoints []CheckpointReport
+	performance []PeriodPerformance
 }
 
 // Goods 商品
@@ -68,6 +69,16 @@ type Goods struct {
 
 var _ report.Report = &Report{}
 
+// Transactions 返回关于该商品的交易
+func (g *Goods) Transactions() []v1.Transaction {
+	if g.transactions == nil {
+		return nil
+	}
+	ret := make([]v1.Transaction, len(g.transactions))
+	copy(ret, g.transactions)
+	return ret
+}
+
 // AddGoodsInfo 添加商品信息
 func (r *Report) AddGoodsInfo(goodsInfos ...v1.GoodsInfo) {
 	if len(goodsInfos) == 0 {
@@ -176,7 +187,11 @@ func (r *Report) completeTotalProfitAndLoss() error {
 	}
 
 	r.profitAndLoss = totalReturn.Sub(totalCost)
-	r.rateOfReturn = totalReturn.Sub(totalCost).DivRound(totalCost, 6)
+	r.rateOfReturn = decimal.Zero
+	if !totalCost.IsZero() {
+		// 仅持有基础商品时没有成本
+		r.rateOfReturn = totalReturn.Sub(totalCost).DivRound(totalCost, 6)
+	}
 	r.annualizedRateOfReturn = rateofreturn.XIRR(cashFlow)
 	return nil
 }
@@ -317,6 +332,66 @@ func (r *Report) Risks() []Goods {
 	return ret
 }
 
+// CustodianValue 托管机构的资产分布
+type CustodianValue struct {
+	// 托管机构
+	Custodian string `json:"custodian" yaml:"custodian"`
+	// 各基础商品（货币）的价值
+	BaseGoods map[string]decimal.Decimal `json:"baseGoods,omitempty" yaml:"baseGoods,omitempty"`
+	// 其它商品的总价值
+	Others decimal.Decimal `json:"others" yaml:"others"`
+	// 总价值（不含负债）
+	Total decimal.Decimal `json:"total" yaml:"total"`
+	// 占比
+	Ratio decimal.Decimal `json:"ratio" yaml:"ratio"`
+}
+
+// BaseGoods 返回所有基础商品（货币）名，按名称排序
+func (r *Report) BaseGoods() []string {
+	var ret []string
+	for _, info := range r.goodsInfos {
+		if !info.Base {
+			continue
+		}
+		ret = append(ret, info.Name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Custodians 返回按托管机构分组的资产分布，按总价值从大到小排序
+func (r *Report) Custodians() []CustodianValue {
+	var ret []CustodianValue
+	indexes := map[string]int{}
+	totalValue := decimal.Zero
+	for _, g := range r.HoldingGoods() {
+		i, ok := indexes[g.Custodian]
+		if !ok {
+			i = len(ret)
+			indexes[g.Custodian] = i
+			ret = append(ret, CustodianValue{Custodian: g.Custodian, BaseGoods: map[string]decimal.Decimal{}})
+		}
+		if g.Base {
+			ret[i].BaseGoods[g.Name] = ret[i].BaseGoods[g.Name].Add(g.Value)
+		} else {
+			ret[i].Others = ret[i].Others.Add(g.Value)
+		}
+		if !g.Base || g.Value.IsPositive() {
+			ret[i].Total = ret[i].Total.Add(g.Value)
+			totalValue = totalValue.Add(g.Value)
+		}
+	}
+	if !totalValue.IsZero() {
+		for i := range ret {
+			ret[i].Ratio = ret[i].Total.Div(totalValue)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[j].Total.LessThan(ret[i].Total)
+	})
+	return ret
+}
+
 // Checkpoints 返回所有检查点报告
 func (r *Report) Checkpoints() []CheckpointReport {
 	if len(r.checkpoints) == 0 {
@@ -327,6 +402,16 @@ func (r *Report) Checkpoints() []CheckpointReport {
 	return ret
 }
 
+// Performance 返回各自然周期的表现
+func (r *Report) Performance() []PeriodPerformance {
+	if len(r.performance) == 0 {
+		return nil
+	}
+	ret := make([]PeriodPerformance, len(r.performance))
+	copy(ret, r.performance)
+	return ret
+}
+
 // TotalValue 返回资产总价值
 func (r *Report) TotalValue() decimal.Decimal {
 	return r.totalValue
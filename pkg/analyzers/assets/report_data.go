@@ -0,0 +1,94 @@
+package assets
+
+import (
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// ReportData 资产报告数据
+type ReportData struct {
+	// 所有商品
+	AllGoods []Goods `json:"allGoods" yaml:"allGoods"`
+	// 持仓
+	Holding []Goods `json:"holding" yaml:"holding"`
+	// 风险分布
+	Risks []RiskValue `json:"risks" yaml:"risks"`
+	// 托管机构分布
+	Custodians []CustodianValue `json:"custodians" yaml:"custodians"`
+	// 检查点
+	Checkpoints []CheckpointData `json:"checkpoints,omitempty" yaml:"checkpoints,omitempty"`
+	// 各自然周期的表现
+	Performance []PeriodPerformance `json:"performance,omitempty" yaml:"performance,omitempty"`
+	// 总体情况
+	Total Summary `json:"total" yaml:"total"`
+}
+
+// RiskValue 风险级别的资产分布
+type RiskValue struct {
+	// 风险
+	Risk v1.RiskLevel `json:"risk" yaml:"risk"`
+	// 总价值
+	Value decimal.Decimal `json:"value" yaml:"value"`
+	// 占比
+	Ratio decimal.Decimal `json:"ratio" yaml:"ratio"`
+}
+
+// CheckpointData 检查点数据
+type CheckpointData struct {
+	// 日期
+	Date v1.Date `json:"date" yaml:"date"`
+	// 总体情况
+	Summary `json:",inline" yaml:",inline"`
+}
+
+// Summary 总体情况
+type Summary struct {
+	// 总价值
+	Value decimal.Decimal `json:"value" yaml:"value"`
+	// 损益
+	ProfitAndLoss decimal.Decimal `json:"profitAndLoss" yaml:"profitAndLoss"`
+	// 收益率
+	RateOfReturn decimal.Decimal `json:"rateOfReturn" yaml:"rateOfReturn"`
+	// 年化收益率
+	AnnualizedRateOfReturn decimal.Decimal `json:"annualizedRateOfReturn" yaml:"annualizedRateOfReturn"`
+}
+
+// Data 返回报告数据
+func (r *Report) Data() interface{} {
+	return r.ReportData()
+}
+
+// ReportData 返回报告数据
+func (r *Report) ReportData() ReportData {
+	ret := ReportData{
+		AllGoods:    []Goods{},
+		Holding:     r.HoldingGoods(),
+		Custodians:  r.Custodians(),
+		Total:       r.summary(),
+		Performance: r.Performance(),
+	}
+	for _, g := range r.AllGoods() {
+		if g.Quantity.IsZero() && !r.showHistory {
+			continue
+		}
+		ret.AllGoods = append(ret.AllGoods, g)
+	}
+	for _, g := range r.Risks() {
+		ret.Risks = append(ret.Risks, RiskValue{Risk: g.Risk, Value: g.Value, Ratio: g.Ratio})
+	}
+	for _, cp := range r.Checkpoints() {
+		ret.Checkpoints = append(ret.Checkpoints, CheckpointData{Date: cp.Date, Summary: cp.Report.summary()})
+	}
+	return ret
+}
+
+// summary 返回总体情况
+func (r *Report) summary() Summary {
+	return Summary{
+		Value:                  r.totalValue,
+		ProfitAndLoss:          r.profitAndLoss,
+		RateOfReturn:           r.rateOfReturn,
+		AnnualizedRateOfReturn: r.annualizedRateOfReturn,
+	}
+}
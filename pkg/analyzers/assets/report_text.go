@@ -3,7 +3,6 @@ package assets
 import (
 	"fmt"
 	"io"
-	"sort"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/shopspring/decimal"
@@ -18,6 +17,7 @@ func (r *Report) Text(w io.Writer, opts report.TextOptions) error {
 	r.textRisks(w)
 	r.textCustodians(w)
 	r.textCheckpoints(w)
+	r.textPerformance(w)
 	r.textTotalProfitAndLoss(w)
 
 	return nil
@@ -144,74 +144,35 @@ func (r *Report) textRisks(w io.Writer) {
 
 // textCustodians 输出文本形式的关于托管机构分布的报告
 func (r *Report) textCustodians(w io.Writer) {
-	// 基础商品
-	var baseGoods []string
-	for _, info := range r.goodsInfos {
-		if !info.Base {
-			continue
-		}
-		baseGoods = append(baseGoods, info.Name)
-	}
-	sort.Strings(baseGoods)
-
-	// 按托管机构分组统计资产总价
-	var custodians []string
-	totalValue := decimal.Zero
-	groupByCustodian := map[string]map[string]decimal.Decimal{}
-	for _, g := range r.HoldingGoods() {
-		name := g.Name
-		if !g.Base {
-			name = "others"
-		}
-		if groupByCustodian[g.Custodian] == nil {
-			groupByCustodian[g.Custodian] = map[string]decimal.Decimal{}
-			custodians = append(custodians, g.Custodian)
-		}
-		groupByCustodian[g.Custodian][name] = groupByCustodian[g.Custodian][name].Add(g.Value)
-		if !g.Base || g.Value.IsPositive() {
-			groupByCustodian[g.Custodian]["total"] = groupByCustodian[g.Custodian]["total"].Add(g.Value)
-			totalValue = totalValue.Add(g.Value)
-		}
-	}
-	sort.Slice(custodians, func(i, j int) bool {
-		return groupByCustodian[custodians[j]]["total"].LessThan(groupByCustodian[custodians[i]]["total"])
-	})
+	baseGoods := r.BaseGoods()
 
 	// 组装表格
-	columnAlignment := []int{tablewriter.ALIGN_LEFT}
 	header := []string{"Custodian"}
-	var data [][]string
-	for i, custodian := range custodians {
-		goods := groupByCustodian[custodian]
-		line := []string{custodian}
+	columnAlignment := []int{tablewriter.ALIGN_LEFT}
+	for _, name := range baseGoods {
+		header = append(header, name)
+		columnAlignment = append(columnAlignment, tablewriter.ALIGN_RIGHT)
+	}
+	header = append(header, "Others")
+	columnAlignment = append(columnAlignment, tablewriter.ALIGN_RIGHT)
+	if len(baseGoods) != 0 {
+		header = append(header, "Total")
+		columnAlignment = append(columnAlignment, tablewriter.ALIGN_RIGHT)
+	}
+	header = append(header, "Ratio")
+	columnAlignment = append(columnAlignment, tablewriter.ALIGN_RIGHT)
 
+	var data [][]string
+	for _, c := range r.Custodians() {
+		line := []string{c.Custodian}
 		for _, name := range baseGoods {
-			line = append(line, goods[name].StringFixedBank(2))
-			if i == 0 {
-				header = append(header, name)
-				columnAlignment = append(columnAlignment, tablewriter.ALIGN_RIGHT)
-			}
+			line = append(line, c.BaseGoods[name].StringFixedBank(2))
 		}
-
-		line = append(line, goods["others"].StringFixedBank(2))
-		if i == 0 {
-			header = append(header, "Others")
-			columnAlignment = append(columnAlignment, tablewriter.ALIGN_RIGHT)
-		}
-
+		line = append(line, c.Others.StringFixedBank(2))
 		if len(baseGoods) != 0 {
-			line = append(line, goods["total"].StringFixedBank(2))
-			if i == 0 {
-				header = append(header, "Total")
-				columnAlignment = append(columnAlignment, tablewriter.ALIGN_RIGHT)
-			}
-		}
-		line = append(line, goods["total"].Div(totalValue).Shift(2).StringFixedBank(2)+"%")
-		if i == 0 {
-			header = append(header, "Ratio")
-			columnAlignment = append(columnAlignment, tablewriter.ALIGN_RIGHT)
+			line = append(line, c.Total.StringFixedBank(2))
 		}
-
+		line = append(line, c.Ratio.Shift(2).StringFixedBank(2)+"%")
 		data = append(data, line)
 	}
 	table := tablewriter.NewWriter(w)
@@ -251,6 +212,37 @@ func (r *Report) textCheckpoints(w io.Writer) {
 	_, _ = fmt.Fprintln(w)
 }
 
+// textPerformance 输出文本形式的各自然周期表现报告
+func (r *Report) textPerformance(w io.Writer) {
+	if len(r.performance) == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Period", "Start Value", "Net Contributions", "End Value", "P/L", "Return"})
+	table.SetColumnAlignment([]int{
+		tablewriter.ALIGN_LEFT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+	})
+	for _, p := range r.performance {
+		table.Append([]string{
+			p.Period,
+			p.StartValue.StringFixedBank(2),
+			p.NetContributions.StringFixedBank(2),
+			p.EndValue.StringFixedBank(2),
+			p.ProfitAndLoss.StringFixedBank(2),
+			p.RateOfReturn.Mul(decimal.New(100, 0)).StringFixedBank(2) + "%",
+		})
+	}
+
+	_, _ = fmt.Fprintln(w, "Performance:")
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
 // textTotalProfitAndLoss 输出文本形式的关于总体损益情况的报告
 func (r *Report) textTotalProfitAndLoss(w io.Writer) {
 	table := tablewriter.NewWriter(w)
@@ -7,13 +7,27 @@ import (
 	"github.com/yhlooo/dragon-acct/pkg/report"
 )
 
+// Options 分析选项
+type Options struct {
+	// 个人所得税计算选项，为 nil 时不计算
+	Tax *TaxOptions
+	// 社会保险和住房公积金计算选项，为 nil 时不计算
+	Insurance *InsuranceOptions
+}
+
 // Analyse 分析收入数据
-func Analyse(_ context.Context, income *v1.Income) (report.Report, error) {
+func Analyse(_ context.Context, income *v1.Income, opts Options) (report.Report, error) {
 	details := make([]IncomeItem, len(income.Details))
 	for i, item := range income.Details {
 		details[i].IncomeItem = item
 	}
 	r := &Report{details: details}
 	r.Complete()
+	if opts.Tax != nil {
+		r.tax = computeTax(r.details, *opts.Tax)
+	}
+	if opts.Insurance != nil {
+		r.insurance = computeInsurance(r.details, *opts.Insurance)
+	}
 	return r, nil
 }
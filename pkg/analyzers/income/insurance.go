@@ -0,0 +1,213 @@
+package income
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// HousingFundCurrency 住房公积金的货币
+const HousingFundCurrency = "CNY"
+
+// ContributionRates 各项社会保险和住房公积金的缴费比例
+type ContributionRates struct {
+	// 养老保险
+	Pension decimal.Decimal
+	// 医疗保险
+	Medical decimal.Decimal
+	// 失业保险
+	Unemployment decimal.Decimal
+	// 住房公积金
+	HousingFund decimal.Decimal
+}
+
+// InsuranceOptions 社会保险和住房公积金计算选项
+type InsuranceOptions struct {
+	// 固定的缴费基数，为零时按当月工资计算
+	Base decimal.Decimal
+	// 社会保险缴费基数下限和上限，为零时不限制
+	SocialBaseMin decimal.Decimal
+	SocialBaseMax decimal.Decimal
+	// 住房公积金缴费基数下限和上限，为零时不限制
+	HousingFundBaseMin decimal.Decimal
+	HousingFundBaseMax decimal.Decimal
+	// 个人缴费比例
+	EmployeeRates ContributionRates
+	// 单位缴费比例
+	EmployerRates ContributionRates
+	// 住房公积金期初余额
+	HousingFundOpeningBalance decimal.Decimal
+	// 住房公积金账户的托管机构，不为空时住房公积金作为该托管机构持有的资产
+	HousingFundCustodian string
+}
+
+// Contributions 各项社会保险和住房公积金的缴费金额
+type Contributions struct {
+	// 养老保险
+	Pension decimal.Decimal `json:"pension" yaml:"pension"`
+	// 医疗保险
+	Medical decimal.Decimal `json:"medical" yaml:"medical"`
+	// 失业保险
+	Unemployment decimal.Decimal `json:"unemployment" yaml:"unemployment"`
+	// 住房公积金
+	HousingFund decimal.Decimal `json:"housingFund" yaml:"housingFund"`
+	// 合计
+	Total decimal.Decimal `json:"total" yaml:"total"`
+}
+
+// InsuranceMonth 一个月的社会保险和住房公积金缴费
+type InsuranceMonth struct {
+	// 月份，如 2024-01
+	Month string `json:"month" yaml:"month"`
+	// 社会保险缴费基数
+	SocialBase decimal.Decimal `json:"socialBase" yaml:"socialBase"`
+	// 住房公积金缴费基数
+	HousingFundBase decimal.Decimal `json:"housingFundBase" yaml:"housingFundBase"`
+	// 个人缴费
+	Employee Contributions `json:"employee" yaml:"employee"`
+	// 单位缴费
+	Employer Contributions `json:"employer" yaml:"employer"`
+	// 记录的保险和住房公积金
+	Recorded decimal.Decimal `json:"recorded" yaml:"recorded"`
+	// 记录的保险和住房公积金与计算的个人缴费合计之差
+	Difference decimal.Decimal `json:"difference" yaml:"difference"`
+	// 住房公积金余额（不含利息）
+	HousingFundBalance decimal.Decimal `json:"housingFundBalance" yaml:"housingFundBalance"`
+
+	// 当月最后一笔收入项的日期
+	date v1.Date
+}
+
+// computeInsurance 按缴费基数和比例拆分每月的社会保险和住房公积金
+//
+// 只统计记录了保险和住房公积金的收入项，缴费基数为当月这些收入项的税前总额之和，
+// 住房公积金余额为期初余额加上个人和单位缴存的住房公积金
+func computeInsurance(details []IncomeItem, opts InsuranceOptions) []InsuranceMonth {
+	index := map[string]int{}
+	var months []InsuranceMonth
+	for _, item := range details {
+		if item.InsuranceAndHF.IsZero() {
+			continue
+		}
+		month := item.Date.Format("2006-01")
+		i, ok := index[month]
+		if !ok {
+			i = len(months)
+			index[month] = i
+			months = append(months, InsuranceMonth{Month: month})
+		}
+		// 暂存当月工资
+		months[i].SocialBase = months[i].SocialBase.Add(item.Gross)
+		months[i].Recorded = months[i].Recorded.Add(item.InsuranceAndHF)
+		if item.Date.After(months[i].date.Time) {
+			months[i].date = item.Date
+		}
+	}
+	sort.Slice(months, func(i, j int) bool {
+		return months[i].Month < months[j].Month
+	})
+
+	balance := opts.HousingFundOpeningBalance
+	for i := range months {
+		m := &months[i]
+		base := m.SocialBase
+		if !opts.Base.IsZero() {
+			base = opts.Base
+		}
+		m.SocialBase = clamp(base, opts.SocialBaseMin, opts.SocialBaseMax)
+		m.HousingFundBase = clamp(base, opts.HousingFundBaseMin, opts.HousingFundBaseMax)
+		m.Employee = contributions(m.SocialBase, m.HousingFundBase, opts.EmployeeRates)
+		m.Employer = contributions(m.SocialBase, m.HousingFundBase, opts.EmployerRates)
+		m.Difference = m.Recorded.Sub(m.Employee.Total)
+		balance = balance.Add(m.Employee.HousingFund).Add(m.Employer.HousingFund)
+		m.HousingFundBalance = balance
+	}
+	return months
+}
+
+// HousingFundTransactions 返回住房公积金缴存对应的资产交易
+//
+// 期初余额和每月个人及单位缴存的住房公积金作为流入 opts.HousingFundCustodian 的货币，
+// 日期为当月最后一笔收入项的日期。未指定托管机构时返回 nil
+func HousingFundTransactions(income *v1.Income, opts InsuranceOptions) []v1.Transaction {
+	if opts.HousingFundCustodian == "" {
+		return nil
+	}
+	details := make([]IncomeItem, len(income.Details))
+	for i, item := range income.Details {
+		details[i].IncomeItem = item
+	}
+	months := computeInsurance(details, opts)
+
+	var ret []v1.Transaction
+	deposit := func(date v1.Date, quantity decimal.Decimal, reason, comment string) {
+		if quantity.IsZero() {
+			return
+		}
+		ret = append(ret, v1.Transaction{
+			Date: date,
+			To: &v1.Goods{
+				Quantity:  quantity,
+				Name:      HousingFundCurrency,
+				Custodian: opts.HousingFundCustodian,
+			},
+			Reason:  reason,
+			Comment: comment,
+		})
+	}
+	if len(months) > 0 {
+		deposit(months[0].date, opts.HousingFundOpeningBalance, "住房公积金期初余额", "")
+	}
+	for _, m := range months {
+		deposit(m.date, m.Employee.HousingFund.Add(m.Employer.HousingFund), "住房公积金缴存", m.Month)
+	}
+	return ret
+}
+
+// AddHousingFund 将住房公积金缴存作为交易添加到 assets ，商品信息中没有住房公积金的货币时添加
+//
+// opts 为 nil 或未指定住房公积金托管机构时不修改 assets 。添加时复制交易和商品信息，不修改原有的切片
+func AddHousingFund(assets *v1.Assets, income *v1.Income, opts *InsuranceOptions) {
+	if opts == nil {
+		return
+	}
+	transactions := HousingFundTransactions(income, *opts)
+	if len(transactions) == 0 {
+		return
+	}
+	assets.Transactions = append(append([]v1.Transaction(nil), assets.Transactions...), transactions...)
+	for _, info := range assets.Goods {
+		if info.Name == HousingFundCurrency {
+			return
+		}
+	}
+	assets.Goods = append(
+		append([]v1.GoodsInfo(nil), assets.Goods...),
+		v1.GoodsInfo{Name: HousingFundCurrency, Price: decimal.NewFromInt(1), Base: true},
+	)
+}
+
+// contributions 计算各项缴费金额
+func contributions(socialBase, housingFundBase decimal.Decimal, rates ContributionRates) Contributions {
+	ret := Contributions{
+		Pension:      socialBase.Mul(rates.Pension).RoundBank(2),
+		Medical:      socialBase.Mul(rates.Medical).RoundBank(2),
+		Unemployment: socialBase.Mul(rates.Unemployment).RoundBank(2),
+		HousingFund:  housingFundBase.Mul(rates.HousingFund).RoundBank(2),
+	}
+	ret.Total = ret.Pension.Add(ret.Medical).Add(ret.Unemployment).Add(ret.HousingFund)
+	return ret
+}
+
+// clamp 将 d 限制在 [lower, upper] 范围内， lower 或 upper 为零时不限制对应方向
+func clamp(d, lower, upper decimal.Decimal) decimal.Decimal {
+	if !lower.IsZero() && d.LessThan(lower) {
+		return lower
+	}
+	if !upper.IsZero() && d.GreaterThan(upper) {
+		return upper
+	}
+	return d
+}
@@ -0,0 +1,137 @@
+package income
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestComputeInsurance 测试 computeInsurance 方法
+func TestComputeInsurance(t *testing.T) {
+	item := func(date string, gross, insuranceAndHF int64) IncomeItem {
+		d, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			t.Fatalf("parse date %q error: %v", date, err)
+		}
+		return IncomeItem{IncomeItem: v1.IncomeItem{
+			Date:           v1.Date{Time: d},
+			Gross:          decimal.New(gross, 0),
+			InsuranceAndHF: decimal.New(insuranceAndHF, 0),
+		}}
+	}
+	rates := ContributionRates{
+		Pension:      decimal.New(8, -2),
+		Medical:      decimal.New(2, -2),
+		Unemployment: decimal.New(5, -3),
+		HousingFund:  decimal.New(12, -2),
+	}
+	opts := InsuranceOptions{
+		SocialBaseMin:             decimal.New(6000, 0),
+		SocialBaseMax:             decimal.New(30000, 0),
+		HousingFundBaseMin:        decimal.New(2000, 0),
+		HousingFundBaseMax:        decimal.New(30000, 0),
+		EmployeeRates:             rates,
+		EmployerRates:             rates,
+		HousingFundOpeningBalance: decimal.New(1000, 0),
+	}
+
+	ret := computeInsurance([]IncomeItem{
+		item("2024-02-10", 50000, 6450),
+		item("2024-01-10", 5000, 1050),
+		// 没有保险和住房公积金的收入项不计入
+		item("2024-01-20", 20000, 0),
+	}, opts)
+	if len(ret) != 2 {
+		t.Fatalf("expected 2 months, got %d", len(ret))
+	}
+
+	jan := ret[0]
+	if jan.Month != "2024-01" || !jan.SocialBase.Equal(decimal.New(6000, 0)) || !jan.HousingFundBase.Equal(decimal.New(5000, 0)) {
+		t.Errorf("unexpected 2024-01 base: %s, %s", jan.SocialBase, jan.HousingFundBase)
+	}
+	// 6000 * 10.5% + 5000 * 12%
+	if !jan.Employee.Total.Equal(decimal.New(1230, 0)) || !jan.Difference.Equal(decimal.New(-180, 0)) {
+		t.Errorf("unexpected 2024-01 contributions: %+v", jan)
+	}
+
+	feb := ret[1]
+	if !feb.SocialBase.Equal(decimal.New(30000, 0)) || !feb.Employee.Total.Equal(decimal.New(6750, 0)) {
+		t.Errorf("unexpected 2024-02 contributions: %+v", feb)
+	}
+	// 1000 + 600 * 2 + 3600 * 2
+	if !feb.HousingFundBalance.Equal(decimal.New(9400, 0)) {
+		t.Errorf("expected housing fund balance 9400, got %s", feb.HousingFundBalance)
+	}
+
+	// 固定缴费基数
+	opts.Base = decimal.New(10000, 0)
+	ret = computeInsurance([]IncomeItem{item("2024-01-10", 50000, 2250)}, opts)
+	if !ret[0].SocialBase.Equal(opts.Base) || !ret[0].Difference.IsZero() {
+		t.Errorf("unexpected contributions with fixed base: %+v", ret[0])
+	}
+}
+
+// TestAddHousingFund 测试 AddHousingFund 方法
+func TestAddHousingFund(t *testing.T) {
+	date := func(s string) v1.Date {
+		d, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			t.Fatalf("parse date %q error: %v", s, err)
+		}
+		return v1.Date{Time: d}
+	}
+	income := &v1.Income{Details: []v1.IncomeItem{
+		{Date: date("2024-01-10"), Gross: decimal.New(5000, 0), InsuranceAndHF: decimal.New(1050, 0)},
+		{Date: date("2024-01-25"), Gross: decimal.New(1000, 0), InsuranceAndHF: decimal.New(100, 0)},
+		{Date: date("2024-02-10"), Gross: decimal.New(50000, 0), InsuranceAndHF: decimal.New(6450, 0)},
+	}}
+	rates := ContributionRates{HousingFund: decimal.New(12, -2)}
+	opts := &InsuranceOptions{
+		HousingFundBaseMax:        decimal.New(30000, 0),
+		EmployeeRates:             rates,
+		EmployerRates:             rates,
+		HousingFundOpeningBalance: decimal.New(1000, 0),
+	}
+	goods := []v1.GoodsInfo{{Name: "USD", Price: decimal.New(7, 0), Base: true}}
+	assets := &v1.Assets{Goods: goods}
+
+	// 未指定托管机构时不添加
+	AddHousingFund(assets, income, opts)
+	if len(assets.Transactions) != 0 || len(assets.Goods) != 1 {
+		t.Errorf("unexpected assets without housing fund custodian: %+v", assets)
+	}
+
+	opts.HousingFundCustodian = "住房公积金"
+	AddHousingFund(assets, income, opts)
+	expected := []struct {
+		date     string
+		quantity int64
+		reason   string
+	}{
+		{date: "2024-01-25", quantity: 1000, reason: "住房公积金期初余额"},
+		// 6000 * 12% * 2
+		{date: "2024-01-25", quantity: 1440, reason: "住房公积金缴存"},
+		// 30000 * 12% * 2
+		{date: "2024-02-10", quantity: 7200, reason: "住房公积金缴存"},
+	}
+	if len(assets.Transactions) != len(expected) {
+		t.Fatalf("unexpected transactions: %+v (expected %d transactions)", assets.Transactions, len(expected))
+	}
+	for i, e := range expected {
+		tx := assets.Transactions[i]
+		if tx.Date.String() != e.date || tx.From != nil || tx.To == nil || tx.Reason != e.reason ||
+			!tx.To.Quantity.Equal(decimal.New(e.quantity, 0)) ||
+			tx.To.Name != HousingFundCurrency || tx.To.Custodian != "住房公积金" {
+			t.Errorf("unexpected transaction %d: %+v (expected: %+v)", i, tx, e)
+		}
+	}
+	if len(assets.Goods) != 2 || assets.Goods[1].Name != HousingFundCurrency || !assets.Goods[1].Base {
+		t.Errorf("unexpected goods: %+v", assets.Goods)
+	}
+	if len(goods) != 1 {
+		t.Errorf("original goods modified: %+v", goods)
+	}
+}
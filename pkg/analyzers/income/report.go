@@ -11,7 +11,9 @@ import (
 
 // Report 收入报告
 type Report struct {
-	details []IncomeItem
+	details   []IncomeItem
+	tax       *TaxReport
+	insurance []InsuranceMonth
 }
 
 var _ report.Report = &Report{}
@@ -20,13 +22,13 @@ var _ report.Report = &Report{}
 //
 //goland:noinspection GoNameStartsWithPackageName
 type IncomeItem struct {
-	v1.IncomeItem
+	v1.IncomeItem `json:",inline" yaml:",inline"`
 
-	TagValue string
+	TagValue string `json:"-" yaml:"-"`
 	// 到手收入
-	TakeHome decimal.Decimal
+	TakeHome decimal.Decimal `json:"takeHome" yaml:"takeHome"`
 	// 用于消费的数量
-	Consumption decimal.Decimal
+	Consumption decimal.Decimal `json:"consumption" yaml:"consumption"`
 }
 
 // Complete 补充完成
@@ -37,6 +39,26 @@ func (r *Report) Complete() {
 	}
 }
 
+// Details 返回收入明细
+func (r *Report) Details() []IncomeItem {
+	if r.details == nil {
+		return nil
+	}
+	ret := make([]IncomeItem, len(r.details))
+	copy(ret, r.details)
+	return ret
+}
+
+// Tax 返回个人所得税报告，未计算时返回 nil
+func (r *Report) Tax() *TaxReport {
+	return r.tax
+}
+
+// Insurance 返回每月社会保险和住房公积金缴费，未计算时返回 nil
+func (r *Report) Insurance() []InsuranceMonth {
+	return r.insurance
+}
+
 // GroupByTags 返回按标签聚合的收入数据
 func (r *Report) GroupByTags() map[string][]IncomeItem {
 	var tagsMap map[string]map[string]IncomeItem
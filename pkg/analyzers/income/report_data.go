@@ -0,0 +1,84 @@
+package income
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReportData 收入报告数据
+type ReportData struct {
+	// 收入明细
+	Details []IncomeItem `json:"details" yaml:"details"`
+	// 按年汇总的收入
+	Yearly []Rollup `json:"yearly,omitempty" yaml:"yearly,omitempty"`
+	// 按月汇总的收入
+	Monthly []Rollup `json:"monthly,omitempty" yaml:"monthly,omitempty"`
+	// 个人所得税
+	Tax *TaxReport `json:"tax,omitempty" yaml:"tax,omitempty"`
+	// 每月社会保险和住房公积金缴费
+	Insurance []InsuranceMonth `json:"insurance,omitempty" yaml:"insurance,omitempty"`
+	// 按标签聚合的收入
+	Groups []TagGroup `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// TagGroup 按标签聚合的收入
+type TagGroup struct {
+	// 标签名
+	Tag string `json:"tag" yaml:"tag"`
+	// 标签值
+	TagValue string `json:"tagValue" yaml:"tagValue"`
+	// 税前总额
+	Gross decimal.Decimal `json:"gross" yaml:"gross"`
+	// 保险和住房公积金
+	InsuranceAndHF decimal.Decimal `json:"insuranceAndHF" yaml:"insuranceAndHF"`
+	// 税
+	Tax decimal.Decimal `json:"tax" yaml:"tax"`
+	// 到手收入
+	TakeHome decimal.Decimal `json:"takeHome" yaml:"takeHome"`
+	// 消费比例
+	ConsumptionProportion decimal.Decimal `json:"consumptionProportion" yaml:"consumptionProportion"`
+	// 用于消费的数量
+	Consumption decimal.Decimal `json:"consumption" yaml:"consumption"`
+}
+
+// Data 返回报告数据
+func (r *Report) Data() interface{} {
+	return r.ReportData()
+}
+
+// ReportData 返回报告数据
+func (r *Report) ReportData() ReportData {
+	ret := ReportData{
+		Details:   r.Details(),
+		Yearly:    r.ByYear(),
+		Monthly:   r.ByMonth(),
+		Tax:       r.Tax(),
+		Insurance: r.Insurance(),
+	}
+	if ret.Details == nil {
+		ret.Details = []IncomeItem{}
+	}
+
+	groups := r.GroupByTags()
+	tags := make([]string, 0, len(groups))
+	for tag := range groups {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		for _, item := range groups[tag] {
+			ret.Groups = append(ret.Groups, TagGroup{
+				Tag:                   tag,
+				TagValue:              item.TagValue,
+				Gross:                 item.Gross,
+				InsuranceAndHF:        item.InsuranceAndHF,
+				Tax:                   item.Tax,
+				TakeHome:              item.TakeHome,
+				ConsumptionProportion: item.ConsumptionProportion,
+				Consumption:           item.Consumption,
+			})
+		}
+	}
+	return ret
+}
@@ -13,8 +13,12 @@ import (
 )
 
 // Text 输出文本形式的报告
-func (r *Report) Text(w io.Writer, _ report.TextOptions) error {
+func (r *Report) Text(w io.Writer, opts report.TextOptions) error {
 	r.textDetails(w)
+	r.textRollups(w, "By Year:", r.ByYear(), false)
+	r.textRollups(w, "By Month:", r.ByMonth(), true)
+	r.textTax(w, opts)
+	r.textInsurance(w)
 	r.textGroupByTags(w)
 
 	return nil
@@ -65,6 +69,187 @@ func (r *Report) textDetails(w io.Writer) {
 	_, _ = fmt.Fprintln(w)
 }
 
+// textRollups 输出文本形式的按周期汇总的收入报告， withMoM 为 true 时输出环比变化
+func (r *Report) textRollups(w io.Writer, title string, rollups []Rollup, withMoM bool) {
+	if len(rollups) == 0 {
+		return
+	}
+	header := []string{
+		"Period",
+		"Gross", "Insurance & HF", "Tax", "Take Home", "Consumption",
+		"Effective Tax Rate", "Gross YoY",
+	}
+	if withMoM {
+		header = append(header, "Gross MoM")
+	}
+	alignment := make([]int, len(header))
+	alignment[0] = tablewriter.ALIGN_LEFT
+	for i := 1; i < len(alignment); i++ {
+		alignment[i] = tablewriter.ALIGN_RIGHT
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.SetColumnAlignment(alignment)
+	for _, item := range rollups {
+		row := []string{
+			item.Period,
+			item.Gross.StringFixedBank(2),
+			item.InsuranceAndHF.StringFixedBank(2),
+			item.Tax.StringFixedBank(2),
+			item.TakeHome.StringFixedBank(2),
+			item.Consumption.StringFixedBank(2),
+			item.EffectiveTaxRate.Mul(decimal.New(100, 0)).StringFixedBank(2) + "%",
+			percentOrDash(item.GrossYoY),
+		}
+		if withMoM {
+			row = append(row, percentOrDash(item.GrossMoM))
+		}
+		table.Append(row)
+	}
+
+	_, _ = fmt.Fprintln(w, title)
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+// percentOrDash 将比例格式化为百分数，为 nil 时返回 "-"
+func percentOrDash(d *decimal.Decimal) string {
+	if d == nil {
+		return "-"
+	}
+	return d.Mul(decimal.New(100, 0)).StringFixedBank(2) + "%"
+}
+
+// textTax 输出文本形式的个人所得税报告
+func (r *Report) textTax(w io.Writer, opts report.TextOptions) {
+	if r.tax == nil || len(r.tax.Withholding) == 0 {
+		return
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{
+		"Month", "Employer",
+		"Salary", "Bonus", "Insurance & HF", "Cumulative Taxable",
+		"Expected Tax", "Recorded Tax", "Difference",
+	})
+	table.SetColumnAlignment([]int{
+		tablewriter.ALIGN_LEFT,
+		tablewriter.ALIGN_LEFT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+	})
+	for _, item := range r.tax.Withholding {
+		difference := item.Difference.StringFixedBank(2)
+		if item.Mismatch {
+			difference += " !"
+		}
+		row := []string{
+			item.Month,
+			item.Employer,
+			item.Salary.StringFixedBank(2),
+			item.Bonus.StringFixedBank(2),
+			item.InsuranceAndHF.StringFixedBank(2),
+			item.CumulativeTaxable.StringFixedBank(2),
+			item.ExpectedTax.StringFixedBank(2),
+			item.RecordedTax.StringFixedBank(2),
+			difference,
+		}
+		if opts.WithColor && item.Mismatch {
+			colors := make([]tablewriter.Colors, len(row))
+			colors[len(row)-1] = tablewriter.Colors{tablewriter.FgRedColor}
+			table.Rich(row, colors)
+		} else {
+			table.Append(row)
+		}
+	}
+	_, _ = fmt.Fprintln(w, "Income Tax Withholding:")
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+
+	table = tablewriter.NewWriter(w)
+	table.SetHeader([]string{
+		"Year",
+		"Income", "Deductions", "Taxable",
+		"Comprehensive Tax", "Bonus Tax", "Annual Tax", "Withheld", "Payable",
+	})
+	table.SetColumnAlignment([]int{
+		tablewriter.ALIGN_LEFT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+	})
+	for _, item := range r.tax.Settlements {
+		table.Append([]string{
+			item.Year,
+			item.Income.StringFixedBank(2),
+			item.Deductions.StringFixedBank(2),
+			item.Taxable.StringFixedBank(2),
+			item.ComprehensiveTax.StringFixedBank(2),
+			item.BonusTax.StringFixedBank(2),
+			item.AnnualTax.StringFixedBank(2),
+			item.Withheld.StringFixedBank(2),
+			item.Payable.StringFixedBank(2),
+		})
+	}
+	_, _ = fmt.Fprintln(w, "Income Tax Settlement (negative payable is refund):")
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+// textInsurance 输出文本形式的社会保险和住房公积金报告
+func (r *Report) textInsurance(w io.Writer) {
+	if len(r.insurance) == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{
+		"Month", "SI Base", "HF Base",
+		"Pension", "Medical", "Unemployment", "Housing Fund",
+		"Employee", "Employer", "Recorded", "Difference", "HF Balance",
+	})
+	alignment := make([]int, 12)
+	alignment[0] = tablewriter.ALIGN_LEFT
+	for i := 1; i < len(alignment); i++ {
+		alignment[i] = tablewriter.ALIGN_RIGHT
+	}
+	table.SetColumnAlignment(alignment)
+	// split 格式化个人和单位缴费
+	split := func(employee, employer decimal.Decimal) string {
+		return employee.StringFixedBank(2) + " / " + employer.StringFixedBank(2)
+	}
+	for _, m := range r.insurance {
+		table.Append([]string{
+			m.Month,
+			m.SocialBase.StringFixedBank(2),
+			m.HousingFundBase.StringFixedBank(2),
+			split(m.Employee.Pension, m.Employer.Pension),
+			split(m.Employee.Medical, m.Employer.Medical),
+			split(m.Employee.Unemployment, m.Employer.Unemployment),
+			split(m.Employee.HousingFund, m.Employer.HousingFund),
+			m.Employee.Total.StringFixedBank(2),
+			m.Employer.Total.StringFixedBank(2),
+			m.Recorded.StringFixedBank(2),
+			m.Difference.StringFixedBank(2),
+			m.HousingFundBalance.StringFixedBank(2),
+		})
+	}
+
+	_, _ = fmt.Fprintln(w, "Social Insurance & Housing Fund (employee / employer):")
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
 // textGroupByTags 输出文本格式的按标签聚合的收入报告
 func (r *Report) textGroupByTags(w io.Writer) {
 	data := r.GroupByTags()
@@ -0,0 +1,101 @@
+package income
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rollup 一个自然周期内的收入汇总
+type Rollup struct {
+	// 周期名，如 2024 、 2024-01
+	Period string `json:"period" yaml:"period"`
+	// 税前总额
+	Gross decimal.Decimal `json:"gross" yaml:"gross"`
+	// 保险和住房公积金
+	InsuranceAndHF decimal.Decimal `json:"insuranceAndHF" yaml:"insuranceAndHF"`
+	// 税
+	Tax decimal.Decimal `json:"tax" yaml:"tax"`
+	// 到手收入
+	TakeHome decimal.Decimal `json:"takeHome" yaml:"takeHome"`
+	// 用于消费的数量
+	Consumption decimal.Decimal `json:"consumption" yaml:"consumption"`
+	// 实际税率（税 / 税前总额）
+	EffectiveTaxRate decimal.Decimal `json:"effectiveTaxRate" yaml:"effectiveTaxRate"`
+	// 税前总额同比变化，上年同期没有收入时为空
+	GrossYoY *decimal.Decimal `json:"grossYoY,omitempty" yaml:"grossYoY,omitempty"`
+	// 税前总额环比变化，仅月度汇总，上月没有收入时为空
+	GrossMoM *decimal.Decimal `json:"grossMoM,omitempty" yaml:"grossMoM,omitempty"`
+}
+
+// ByYear 返回按年汇总的收入，按年份排序
+func (r *Report) ByYear() []Rollup {
+	ret := r.rollup("2006")
+	index := rollupIndex(ret)
+	for i := range ret {
+		year, _ := time.Parse("2006", ret[i].Period)
+		ret[i].GrossYoY = grossChange(ret[i], ret, index, year.AddDate(-1, 0, 0).Format("2006"))
+	}
+	return ret
+}
+
+// ByMonth 返回按月汇总的收入，按月份排序
+func (r *Report) ByMonth() []Rollup {
+	ret := r.rollup("2006-01")
+	index := rollupIndex(ret)
+	for i := range ret {
+		month, _ := time.Parse("2006-01", ret[i].Period)
+		ret[i].GrossYoY = grossChange(ret[i], ret, index, month.AddDate(-1, 0, 0).Format("2006-01"))
+		ret[i].GrossMoM = grossChange(ret[i], ret, index, month.AddDate(0, -1, 0).Format("2006-01"))
+	}
+	return ret
+}
+
+// rollup 按日期格式化为 layout 后的周期汇总收入
+func (r *Report) rollup(layout string) []Rollup {
+	var ret []Rollup
+	index := map[string]int{}
+	for _, item := range r.details {
+		period := item.Date.Format(layout)
+		i, ok := index[period]
+		if !ok {
+			i = len(ret)
+			index[period] = i
+			ret = append(ret, Rollup{Period: period})
+		}
+		ret[i].Gross = ret[i].Gross.Add(item.Gross)
+		ret[i].InsuranceAndHF = ret[i].InsuranceAndHF.Add(item.InsuranceAndHF)
+		ret[i].Tax = ret[i].Tax.Add(item.Tax)
+		ret[i].TakeHome = ret[i].TakeHome.Add(item.TakeHome)
+		ret[i].Consumption = ret[i].Consumption.Add(item.Consumption)
+	}
+	for i := range ret {
+		if ret[i].Gross.IsPositive() {
+			ret[i].EffectiveTaxRate = ret[i].Tax.DivRound(ret[i].Gross, 6)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Period < ret[j].Period
+	})
+	return ret
+}
+
+// rollupIndex 返回周期名到汇总下标的索引
+func rollupIndex(rollups []Rollup) map[string]int {
+	ret := make(map[string]int, len(rollups))
+	for i, item := range rollups {
+		ret[item.Period] = i
+	}
+	return ret
+}
+
+// grossChange 返回 cur 相对于周期 prev 的税前总额变化比例，周期 prev 没有收入时返回 nil
+func grossChange(cur Rollup, rollups []Rollup, index map[string]int, prev string) *decimal.Decimal {
+	i, ok := index[prev]
+	if !ok || !rollups[i].Gross.IsPositive() {
+		return nil
+	}
+	change := cur.Gross.Sub(rollups[i].Gross).DivRound(rollups[i].Gross, 6)
+	return &change
+}
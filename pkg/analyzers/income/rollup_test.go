@@ -0,0 +1,54 @@
+package income
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestReport_ByMonth 测试 Report.ByMonth 和 Report.ByYear 方法
+func TestReport_ByMonth(t *testing.T) {
+	item := func(date string, gross, tax int64) v1.IncomeItem {
+		d, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			t.Fatalf("parse date %q error: %v", date, err)
+		}
+		return v1.IncomeItem{Date: v1.Date{Time: d}, Gross: decimal.New(gross, 0), Tax: decimal.New(tax, 0)}
+	}
+	r, err := Analyse(context.Background(), &v1.Income{Details: []v1.IncomeItem{
+		item("2025-01-10", 12000, 600),
+		item("2024-01-10", 10000, 300),
+		item("2024-02-10", 8000, 200),
+		item("2024-02-20", 2000, 100),
+	}}, Options{})
+	if err != nil {
+		t.Fatalf("analyse error: %v", err)
+	}
+	report := r.(*Report)
+
+	months := report.ByMonth()
+	if len(months) != 3 {
+		t.Fatalf("expected 3 months, got %d", len(months))
+	}
+	feb := months[1]
+	if feb.Period != "2024-02" || !feb.Gross.Equal(decimal.New(10000, 0)) || !feb.EffectiveTaxRate.Equal(decimal.New(3, -2)) {
+		t.Errorf("unexpected 2024-02 rollup: %+v", feb)
+	}
+	if feb.GrossMoM == nil || !feb.GrossMoM.IsZero() || feb.GrossYoY != nil {
+		t.Errorf("unexpected 2024-02 changes: mom %v, yoy %v", feb.GrossMoM, feb.GrossYoY)
+	}
+	jan := months[2]
+	if jan.GrossMoM != nil || jan.GrossYoY == nil || !jan.GrossYoY.Equal(decimal.New(2, -1)) {
+		t.Errorf("unexpected 2025-01 changes: mom %v, yoy %v", jan.GrossMoM, jan.GrossYoY)
+	}
+
+	years := report.ByYear()
+	if len(years) != 2 || years[0].Period != "2024" || years[1].GrossYoY == nil ||
+		!years[1].GrossYoY.Equal(decimal.New(-4, -1)) {
+		t.Errorf("unexpected yearly rollups: %+v", years)
+	}
+}
@@ -0,0 +1,205 @@
+package income
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yhlooo/dragon-acct/pkg/utils/incometax"
+)
+
+// BonusMethod 全年一次性奖金计税方式
+type BonusMethod string
+
+// BonusMethod 的可选值
+const (
+	// BonusSeparate 单独计税
+	BonusSeparate BonusMethod = "separate"
+	// BonusCombined 并入当月工资薪金按累计预扣法计税
+	BonusCombined BonusMethod = "combined"
+)
+
+// taxTolerance 记录的税与计算的税允许的差额
+var taxTolerance = decimal.New(1, -2)
+
+// TaxOptions 个人所得税计算选项
+type TaxOptions struct {
+	// 每月专项附加扣除
+	SpecialDeductions decimal.Decimal
+	// 标识全年一次性奖金的标签名和标签值
+	BonusTagKey   string
+	BonusTagValue string
+	// 全年一次性奖金计税方式
+	BonusMethod BonusMethod
+	// 标识扣缴义务人（任职受雇单位）的标签名，为空时视为同一扣缴义务人
+	EmployerTagKey string
+}
+
+// TaxReport 个人所得税报告
+type TaxReport struct {
+	// 每月预扣预缴
+	Withholding []TaxWithholding `json:"withholding" yaml:"withholding"`
+	// 年度汇算
+	Settlements []TaxSettlement `json:"settlements" yaml:"settlements"`
+}
+
+// TaxWithholding 一个扣缴义务人一个月的预扣预缴
+type TaxWithholding struct {
+	// 月份，如 2024-01
+	Month string `json:"month" yaml:"month"`
+	// 扣缴义务人
+	Employer string `json:"employer,omitempty" yaml:"employer,omitempty"`
+	// 工资薪金（不含单独计税的奖金）
+	Salary decimal.Decimal `json:"salary" yaml:"salary"`
+	// 全年一次性奖金
+	Bonus decimal.Decimal `json:"bonus,omitempty" yaml:"bonus,omitempty"`
+	// 保险和住房公积金（专项扣除）
+	InsuranceAndHF decimal.Decimal `json:"insuranceAndHF" yaml:"insuranceAndHF"`
+	// 累计预扣预缴应纳税所得额
+	CumulativeTaxable decimal.Decimal `json:"cumulativeTaxable" yaml:"cumulativeTaxable"`
+	// 计算的应预扣税额
+	ExpectedTax decimal.Decimal `json:"expectedTax" yaml:"expectedTax"`
+	// 记录的税额
+	RecordedTax decimal.Decimal `json:"recordedTax" yaml:"recordedTax"`
+	// 记录的税额与计算的税额之差
+	Difference decimal.Decimal `json:"difference" yaml:"difference"`
+	// 记录的税额与计算的税额不一致
+	Mismatch bool `json:"mismatch,omitempty" yaml:"mismatch,omitempty"`
+}
+
+// TaxSettlement 一个纳税年度的综合所得汇算
+type TaxSettlement struct {
+	// 年份
+	Year string `json:"year" yaml:"year"`
+	// 综合所得收入（不含单独计税的奖金）
+	Income decimal.Decimal `json:"income" yaml:"income"`
+	// 减除费用、专项扣除和专项附加扣除
+	Deductions decimal.Decimal `json:"deductions" yaml:"deductions"`
+	// 应纳税所得额
+	Taxable decimal.Decimal `json:"taxable" yaml:"taxable"`
+	// 综合所得应纳税额
+	ComprehensiveTax decimal.Decimal `json:"comprehensiveTax" yaml:"comprehensiveTax"`
+	// 全年一次性奖金单独计税的税额
+	BonusTax decimal.Decimal `json:"bonusTax" yaml:"bonusTax"`
+	// 全年应纳税额
+	AnnualTax decimal.Decimal `json:"annualTax" yaml:"annualTax"`
+	// 已缴税额（记录的税额）
+	Withheld decimal.Decimal `json:"withheld" yaml:"withheld"`
+	// 应补税额，为负时为应退税额
+	Payable decimal.Decimal `json:"payable" yaml:"payable"`
+}
+
+// taxMonthKey 预扣预缴的统计单位
+type taxMonthKey struct {
+	year     int
+	month    time.Month
+	employer string
+}
+
+// computeTax 按累计预扣法计算工资薪金的预扣预缴税额和年度汇算
+//
+// 每个扣缴义务人分别累计，累计减除费用和专项附加扣除按当年在该单位第一次领取工资的月份起计算。
+// 单独计税时一年内的多笔奖金在汇算时合并为一笔计税
+func computeTax(details []IncomeItem, opts TaxOptions) *TaxReport {
+	withholding := map[taxMonthKey]*TaxWithholding{}
+	var keys []taxMonthKey
+	isBonus := func(item IncomeItem) bool {
+		return opts.BonusTagKey != "" && item.Tags[opts.BonusTagKey] == opts.BonusTagValue
+	}
+	for _, item := range details {
+		k := taxMonthKey{year: item.Date.Year(), month: item.Date.Month()}
+		if opts.EmployerTagKey != "" {
+			k.employer = item.Tags[opts.EmployerTagKey]
+		}
+		w, ok := withholding[k]
+		if !ok {
+			w = &TaxWithholding{
+				Month:    item.Date.Format("2006-01"),
+				Employer: k.employer,
+			}
+			withholding[k] = w
+			keys = append(keys, k)
+		}
+		if isBonus(item) && opts.BonusMethod == BonusSeparate {
+			w.Bonus = w.Bonus.Add(item.Gross)
+		} else {
+			w.Salary = w.Salary.Add(item.Gross)
+		}
+		w.InsuranceAndHF = w.InsuranceAndHF.Add(item.InsuranceAndHF)
+		w.RecordedTax = w.RecordedTax.Add(item.Tax)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].year != keys[j].year {
+			return keys[i].year < keys[j].year
+		}
+		if keys[i].employer != keys[j].employer {
+			return keys[i].employer < keys[j].employer
+		}
+		return keys[i].month < keys[j].month
+	})
+
+	ret := &TaxReport{}
+	settlements := map[int]*TaxSettlement{}
+	bonuses := map[int]decimal.Decimal{}
+	var years []int
+	// 当前扣缴义务人当年的累计数据
+	var (
+		current                  taxMonthKey
+		firstMonth               time.Month
+		cumulativeIncome         decimal.Decimal
+		cumulativeInsuranceAndHF decimal.Decimal
+		cumulativeTax            decimal.Decimal
+	)
+	for i, k := range keys {
+		if i == 0 || k.year != current.year || k.employer != current.employer {
+			firstMonth = k.month
+			cumulativeIncome, cumulativeInsuranceAndHF, cumulativeTax = decimal.Zero, decimal.Zero, decimal.Zero
+		}
+		current = k
+		w := withholding[k]
+
+		months := decimal.NewFromInt(int64(k.month - firstMonth + 1))
+		cumulativeIncome = cumulativeIncome.Add(w.Salary)
+		cumulativeInsuranceAndHF = cumulativeInsuranceAndHF.Add(w.InsuranceAndHF)
+		w.CumulativeTaxable = decimal.Max(cumulativeIncome.
+			Sub(incometax.BasicDeductionPerMonth.Mul(months)).
+			Sub(cumulativeInsuranceAndHF).
+			Sub(opts.SpecialDeductions.Mul(months)), decimal.Zero)
+		salaryTax := decimal.Max(incometax.ComprehensiveTax(w.CumulativeTaxable).Sub(cumulativeTax), decimal.Zero)
+		cumulativeTax = cumulativeTax.Add(salaryTax)
+
+		w.ExpectedTax = salaryTax.Add(incometax.BonusTax(w.Bonus))
+		w.Difference = w.RecordedTax.Sub(w.ExpectedTax)
+		w.Mismatch = w.Difference.Abs().GreaterThan(taxTolerance)
+		ret.Withholding = append(ret.Withholding, *w)
+
+		s, ok := settlements[k.year]
+		if !ok {
+			s = &TaxSettlement{Year: fmt.Sprintf("%d", k.year)}
+			settlements[k.year] = s
+			years = append(years, k.year)
+		}
+		s.Income = s.Income.Add(w.Salary)
+		s.Deductions = s.Deductions.Add(w.InsuranceAndHF)
+		s.Withheld = s.Withheld.Add(w.RecordedTax)
+		bonuses[k.year] = bonuses[k.year].Add(w.Bonus)
+	}
+
+	sort.Ints(years)
+	months := decimal.New(12, 0)
+	for _, year := range years {
+		s := settlements[year]
+		s.Deductions = s.Deductions.
+			Add(incometax.BasicDeductionPerMonth.Mul(months)).
+			Add(opts.SpecialDeductions.Mul(months))
+		s.Taxable = decimal.Max(s.Income.Sub(s.Deductions), decimal.Zero)
+		s.ComprehensiveTax = incometax.ComprehensiveTax(s.Taxable)
+		s.BonusTax = incometax.BonusTax(bonuses[year])
+		s.AnnualTax = s.ComprehensiveTax.Add(s.BonusTax)
+		s.Payable = s.AnnualTax.Sub(s.Withheld)
+		ret.Settlements = append(ret.Settlements, *s)
+	}
+	return ret
+}
@@ -0,0 +1,81 @@
+package income
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestComputeTax 测试 computeTax 方法
+func TestComputeTax(t *testing.T) {
+	item := func(date string, gross, insuranceAndHF, tax int64, tags map[string]string) IncomeItem {
+		d, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			t.Fatalf("parse date %q error: %v", date, err)
+		}
+		return IncomeItem{IncomeItem: v1.IncomeItem{
+			Date:           v1.Date{Time: d},
+			Gross:          decimal.New(gross, 0),
+			InsuranceAndHF: decimal.New(insuranceAndHF, 0),
+			Tax:            decimal.New(tax, 0),
+			Tags:           tags,
+		}}
+	}
+	a := map[string]string{"company": "A"}
+	details := []IncomeItem{
+		item("2024-01-10", 30000, 4500, 570, a),
+		item("2024-02-10", 30000, 4500, 700, a),
+		item("2024-03-10", 30000, 4500, 1900, a),
+		item("2024-03-20", 50000, 0, 4790, map[string]string{"company": "A", "type": "bonus"}),
+		// 换工作后重新累计
+		item("2024-04-10", 20000, 3000, 315, map[string]string{"company": "B"}),
+	}
+	opts := TaxOptions{
+		SpecialDeductions: decimal.New(1500, 0),
+		BonusTagKey:       "type",
+		BonusTagValue:     "bonus",
+		BonusMethod:       BonusSeparate,
+		EmployerTagKey:    "company",
+	}
+
+	ret := computeTax(details, opts)
+	if len(ret.Withholding) != 4 {
+		t.Fatalf("expected 4 withholding records, got %d", len(ret.Withholding))
+	}
+	for i, expected := range []struct {
+		month    string
+		tax      string
+		mismatch bool
+	}{
+		{month: "2024-01", tax: "570"},
+		{month: "2024-02", tax: "710", mismatch: true},
+		{month: "2024-03", tax: "6690"},
+		{month: "2024-04", tax: "315"},
+	} {
+		w := ret.Withholding[i]
+		if w.Month != expected.month || !w.ExpectedTax.Equal(decimal.RequireFromString(expected.tax)) ||
+			w.Mismatch != expected.mismatch {
+			t.Errorf("withholding %d: expected %+v, got %+v", i, expected, w)
+		}
+	}
+
+	if len(ret.Settlements) != 1 {
+		t.Fatalf("expected 1 settlement, got %d", len(ret.Settlements))
+	}
+	// 110000 - 60000 - 18000 - 16500 = 15500
+	s := ret.Settlements[0]
+	if !s.Taxable.Equal(decimal.New(15500, 0)) || !s.AnnualTax.Equal(decimal.New(465+4790, 0)) ||
+		!s.Payable.Equal(decimal.New(465+4790-8275, 0)) {
+		t.Errorf("unexpected settlement: %+v", s)
+	}
+
+	// 奖金并入当月工资
+	opts.BonusMethod = BonusCombined
+	ret = computeTax(details, opts)
+	if w := ret.Withholding[2]; !w.Bonus.IsZero() || !w.ExpectedTax.Equal(decimal.New(6900, 0)) {
+		t.Errorf("unexpected combined withholding: %+v", w)
+	}
+}
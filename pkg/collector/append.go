@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// defaultFileNames 各类型账本文件不存在时新建的文件名
+var defaultFileNames = map[FileKind]string{
+	FileKindAssetsGoods:        "assets_goods.csv",
+	FileKindAssetsTransactions: "assets_transactions.csv",
+	FileKindAssetsCheckpoints:  "assets_checkpoints.yaml",
+	FileKindAssetsPrices:       "assets_prices.csv",
+	FileKindIncomeDetails:      "income_details.csv",
+}
+
+// AppendToLedger 将数据追加到账本目录中对应类型的文件
+//
+// data 可以是 []v1.GoodsInfo 、 []v1.Transaction 、 []v1.Checkpoint 、 []v1.PriceRecord 或 []v1.IncomeItem 。
+// 追加到该类型文件名排序最靠后的文件，不存在时新建。
+// 追加时只在文件末尾写入新记录，不改变已有内容（包括注释和格式），无法追加时才以规范形式重写文件，
+// 但不会重写含注释的 YAML 文件。返回写入的文件路径
+func AppendToLedger(dir string, data interface{}) (string, error) {
+	var kind FileKind
+	switch data.(type) {
+	case []v1.GoodsInfo:
+		kind = FileKindAssetsGoods
+	case []v1.Transaction:
+		kind = FileKindAssetsTransactions
+	case []v1.Checkpoint:
+		kind = FileKindAssetsCheckpoints
+	case []v1.PriceRecord:
+		kind = FileKindAssetsPrices
+	case []v1.IncomeItem:
+		kind = FileKindIncomeDetails
+	default:
+		return "", fmt.Errorf("can not append %T to ledger", data)
+	}
+
+	// 确定目标文件
+	files, err := FindFiles(dir, kind)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		path := filepath.Join(dir, defaultFileNames[kind])
+		if _, err := os.Stat(path); err == nil {
+			return "", fmt.Errorf("file %q exists but can not be loaded", path)
+		}
+		created := appendData(NewFileData(kind), data)
+		Normalize(created)
+		return path, WriteFile(path, created)
+	}
+	path := files[len(files)-1]
+	existing, err := LoadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("load file %q error: %w", path, err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read file %q error: %w", path, err)
+	}
+	switch FormatOf(path) {
+	case FormatCSV:
+		err = appendCSV(path, raw, data)
+	case FormatYAML:
+		err = appendYAML(path, raw, existing, data)
+	default:
+		err = WriteFile(path, appendData(existing, data))
+	}
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// appendData 将 data 追加到 existing 并返回 existing ， existing 类型见 NewFileData
+func appendData(existing, data interface{}) interface{} {
+	switch obj := existing.(type) {
+	case *[]v1.GoodsInfo:
+		*obj = append(*obj, data.([]v1.GoodsInfo)...)
+	case *[]v1.Transaction:
+		*obj = append(*obj, data.([]v1.Transaction)...)
+	case *[]v1.Checkpoint:
+		*obj = append(*obj, data.([]v1.Checkpoint)...)
+	case *[]v1.PriceRecord:
+		*obj = append(*obj, data.([]v1.PriceRecord)...)
+	case *[]v1.IncomeItem:
+		*obj = append(*obj, data.([]v1.IncomeItem)...)
+	}
+	return existing
+}
+
+// appendCSV 将 data 编码为 CSV 行（不含表头）追加到内容为 raw 的文件末尾
+func appendCSV(path string, raw []byte, data interface{}) error {
+	buf := &bytes.Buffer{}
+	if err := EncodeCSV(buf, data); err != nil {
+		return err
+	}
+	content := buf.Bytes()
+	if len(bytes.TrimSpace(raw)) > 0 {
+		// 去掉表头
+		if i := bytes.IndexByte(content, '\n'); i >= 0 {
+			content = content[i+1:]
+		}
+		if raw[len(raw)-1] != '\n' {
+			content = append([]byte("\n"), content...)
+		}
+	}
+	return appendFile(path, content)
+}
+
+// appendYAML 将 data 追加到内容为 raw 、数据为 existing 的 YAML 文件
+//
+// 文件根节点为非空的块序列时将 data 编码后追加到文件末尾，否则以规范形式重写，文件含注释时报错
+func appendYAML(path string, raw []byte, existing, data interface{}) error {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(raw, root); err != nil {
+		return fmt.Errorf("unmarshal file %q as yaml error: %w", path, err)
+	}
+
+	if len(root.Content) == 1 && root.Content[0].Kind == yaml.SequenceNode &&
+		root.Content[0].Style&yaml.FlowStyle == 0 && len(root.Content[0].Content) > 0 {
+		buf := &bytes.Buffer{}
+		if len(raw) > 0 && raw[len(raw)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		if err := EncodeYAML(buf, data); err != nil {
+			return err
+		}
+
+		// 确认追加后的文件能加载出所有数据
+		expected := reflect.ValueOf(existing).Elem().Len() + reflect.ValueOf(data).Len()
+		loaded := NewFileData(FileKindOf(path))
+		if err := yaml.Unmarshal(append(append([]byte(nil), raw...), buf.Bytes()...), loaded); err == nil &&
+			reflect.ValueOf(loaded).Elem().Len() == expected {
+			return appendFile(path, buf.Bytes())
+		}
+	}
+
+	if hasYAMLComments(root) {
+		return fmt.Errorf("can not append to %q without rewriting it and losing its comments, please add the data manually", path)
+	}
+	return WriteFile(path, appendData(existing, data))
+}
+
+// hasYAMLComments 返回 YAML 节点及其子节点是否含注释
+func hasYAMLComments(node *yaml.Node) bool {
+	if node.HeadComment != "" || node.LineComment != "" || node.FootComment != "" {
+		return true
+	}
+	for _, child := range node.Content {
+		if hasYAMLComments(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendFile 将 content 追加到文件末尾
+func appendFile(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open file %q error: %w", path, err)
+	}
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write file %q error: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close file %q error: %w", path, err)
+	}
+	return nil
+}
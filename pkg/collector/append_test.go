@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestAppendToLedger 测试 AppendToLedger 方法
+func TestAppendToLedger(t *testing.T) {
+	dir := t.TempDir()
+	d, _ := time.Parse(time.DateOnly, "2024-12-31")
+	date := v1.Date{Time: d}
+
+	// CSV 追加到末尾，不改变已有内容
+	goodsCSV := "Name,Code,Risk,Price,Flags\nCNY ,,R0,1,Base"
+	if err := os.WriteFile(filepath.Join(dir, "assets_goods.csv"), []byte(goodsCSV), 0o644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	path, err := AppendToLedger(dir, []v1.GoodsInfo{{Name: "AAPL", Price: decimal.New(250, 0)}})
+	if err != nil {
+		t.Fatalf("append goods error: %v", err)
+	}
+	raw, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(raw), goodsCSV+"\n") || !strings.Contains(string(raw), "\nAAPL,") {
+		t.Errorf("unexpected goods file content:\n%s", raw)
+	}
+	if goods, err := LoadFile(path); err != nil || len(*goods.(*[]v1.GoodsInfo)) != 2 {
+		t.Errorf("load appended goods error: %v", err)
+	}
+
+	// YAML 追加保留注释
+	checkpoints := "# 年度检查点\n- date: 2023-12-31 # 去年\n  goods:\n    - name: CNY\n      price: 1\n"
+	cpPath := filepath.Join(dir, "assets_checkpoints.yaml")
+	if err := os.WriteFile(cpPath, []byte(checkpoints), 0o644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	cp := v1.Checkpoint{Date: date, Goods: []v1.CheckpointGoodsInfo{{Name: "CNY", Price: decimal.New(1, 0)}}}
+	if _, err := AppendToLedger(dir, []v1.Checkpoint{cp}); err != nil {
+		t.Fatalf("append checkpoint error: %v", err)
+	}
+	raw, _ = os.ReadFile(cpPath)
+	if !strings.HasPrefix(string(raw), checkpoints) {
+		t.Errorf("unexpected checkpoints file content:\n%s", raw)
+	}
+	if loaded, err := LoadFile(cpPath); err != nil || len(*loaded.(*[]v1.Checkpoint)) != 2 {
+		t.Errorf("load appended checkpoints error: %v", err)
+	}
+
+	// 无法追加又含注释的 YAML 文件不重写
+	if err := os.WriteFile(cpPath, []byte("# 暂无\n[]\n"), 0o644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	if _, err := AppendToLedger(dir, []v1.Checkpoint{cp}); err == nil {
+		t.Errorf("expected error when appending to yaml file with comments")
+	}
+
+	// 文件不存在时新建
+	path, err = AppendToLedger(dir, []v1.PriceRecord{{Date: date, Name: "AAPL", Price: decimal.New(250, 0)}})
+	if err != nil {
+		t.Fatalf("append prices error: %v", err)
+	}
+	if filepath.Base(path) != "assets_prices.csv" {
+		t.Errorf("unexpected prices file: %q", path)
+	}
+}
@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"os"
@@ -14,17 +15,74 @@ import (
 	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
 )
 
+// FileKind 账本文件类型
+type FileKind string
+
+// FileKind 的可选值
+//
+// 账本文件类型由文件名前缀决定，如 assets_transactions_2024.csv 的类型为 FileKindAssetsTransactions
 const (
-	assetsName         = "assets"
-	assetsGoods        = "assets_goods"
-	assetsTransactions = "assets_transactions"
-	assetsCheckpoints  = "assets_checkpoints"
-	incomeName         = "income"
-	incomeDetailsName  = "income_details"
+	FileKindAssets             FileKind = "assets"
+	FileKindAssetsGoods        FileKind = "assets_goods"
+	FileKindAssetsTransactions FileKind = "assets_transactions"
+	FileKindAssetsCheckpoints  FileKind = "assets_checkpoints"
+	FileKindAssetsPrices       FileKind = "assets_prices"
+	FileKindIncome             FileKind = "income"
+	FileKindIncomeDetails      FileKind = "income_details"
 )
 
+// FileKindOf 根据文件名返回账本文件类型，不是账本文件时返回空字符串
+func FileKindOf(name string) FileKind {
+	name = filepath.Base(name)
+	// 注意需要先匹配较长的前缀
+	for _, kind := range []FileKind{
+		FileKindIncomeDetails,
+		FileKindIncome,
+		FileKindAssetsCheckpoints,
+		FileKindAssetsPrices,
+		FileKindAssetsTransactions,
+		FileKindAssetsGoods,
+		FileKindAssets,
+	} {
+		if strings.HasPrefix(name, string(kind)) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// NewFileData 创建用于承载指定类型账本文件内容的对象
+//
+// 返回 *v1.Assets 、 *[]v1.GoodsInfo 、 *[]v1.Transaction 、 *[]v1.Checkpoint 、 *[]v1.PriceRecord 、
+// *v1.Income 或 *[]v1.IncomeItem
+func NewFileData(kind FileKind) interface{} {
+	switch kind {
+	case FileKindAssets:
+		return &v1.Assets{}
+	case FileKindAssetsGoods:
+		return &[]v1.GoodsInfo{}
+	case FileKindAssetsTransactions:
+		return &[]v1.Transaction{}
+	case FileKindAssetsCheckpoints:
+		return &[]v1.Checkpoint{}
+	case FileKindAssetsPrices:
+		return &[]v1.PriceRecord{}
+	case FileKindIncome:
+		return &v1.Income{}
+	case FileKindIncomeDetails:
+		return &[]v1.IncomeItem{}
+	}
+	return nil
+}
+
+// Options 收集选项
+type Options struct {
+	// 重复交易处理策略
+	Duplicates DuplicatesPolicy
+}
+
 // Collect 收集数据
-func Collect(path string) (*v1.Root, error) {
+func Collect(ctx context.Context, path string, opts Options) (*v1.Root, error) {
 	dir, err := os.ReadDir(path)
 	if err != nil {
 		return nil, fmt.Errorf("list %q error: %w", path, err)
@@ -35,57 +93,64 @@ func Collect(path string) (*v1.Root, error) {
 		if f.IsDir() {
 			continue
 		}
-		ext := filepath.Ext(f.Name())
 		filePath := filepath.Join(path, f.Name())
-		var err error
-		switch {
-		case strings.HasPrefix(f.Name(), incomeDetailsName):
-			switch ext {
-			case ".yaml", ".yml":
-				err = loadYAML(ret, filePath, &[]v1.IncomeItem{})
-			case ".csv":
-				err = loadCSV(ret, filePath, &[]v1.IncomeItem{})
-			}
-		case strings.HasPrefix(f.Name(), incomeName):
-			switch ext {
-			case ".yaml", ".yml":
-				err = loadYAML(ret, filePath, &v1.Income{})
-			}
-		case strings.HasPrefix(f.Name(), assetsCheckpoints):
-			switch ext {
-			case ".yaml", ".yml":
-				err = loadYAML(ret, filePath, &[]v1.Checkpoint{})
-			}
-		case strings.HasPrefix(f.Name(), assetsTransactions):
-			switch ext {
-			case ".yaml", ".yml":
-				err = loadYAML(ret, filePath, &[]v1.Transaction{})
-			case ".csv":
-				err = loadCSV(ret, filePath, &[]v1.Transaction{})
-			}
-		case strings.HasPrefix(f.Name(), assetsGoods):
-			switch ext {
-			case ".yaml", ".yml":
-				err = loadYAML(ret, filePath, &[]v1.GoodsInfo{})
-			case ".csv":
-				err = loadCSV(ret, filePath, &[]v1.GoodsInfo{})
-			}
-		case strings.HasPrefix(f.Name(), assetsName):
-			switch ext {
-			case ".yaml", ".yml":
-				err = loadYAML(ret, filePath, &v1.Assets{})
-			}
-		}
+		data, err := LoadFile(filePath)
 		if err != nil {
 			return nil, fmt.Errorf("load file %q error: %w", filePath, err)
 		}
+		if data == nil {
+			continue
+		}
+		// 合并数据
+		if err := Merge(ret, data); err != nil {
+			return nil, fmt.Errorf("merge file %q error: %w", filePath, err)
+		}
+	}
+
+	// 处理重复交易
+	if err := handleDuplicateTransactions(ctx, ret, opts.Duplicates); err != nil {
+		return nil, err
 	}
 
 	return ret, nil
 }
 
+// IsSupportedFile 判断是否支持加载该文件
+func IsSupportedFile(path string) bool {
+	kind := FileKindOf(path)
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return kind != ""
+	case ".csv":
+		return kind == FileKindAssetsGoods || kind == FileKindAssetsTransactions || kind == FileKindAssetsPrices ||
+			kind == FileKindIncomeDetails
+	}
+	return false
+}
+
+// LoadFile 加载单个账本文件
+//
+// 返回值类型见 NewFileData ，不支持的文件返回 nil
+func LoadFile(path string) (interface{}, error) {
+	if !IsSupportedFile(path) {
+		return nil, nil
+	}
+	into := NewFileData(FileKindOf(path))
+	var err error
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = loadYAML(path, into)
+	case ".csv":
+		err = loadCSV(path, into)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
 // loadYAML 加载 YAML 文件
-func loadYAML(root *v1.Root, path string, into interface{}) error {
+func loadYAML(path string, into interface{}) error {
 	// 读
 	raw, err := os.ReadFile(path)
 	if err != nil {
@@ -95,15 +160,11 @@ func loadYAML(root *v1.Root, path string, into interface{}) error {
 	if err := yaml.Unmarshal(raw, into); err != nil {
 		return fmt.Errorf("unmarshal file %q as yaml to %T error: %w", path, into, err)
 	}
-	// 合并数据
-	if err := Merge(root, into); err != nil {
-		return fmt.Errorf("merge file %q error: %w", path, err)
-	}
 	return nil
 }
 
 // loadCSV 加载 CSV 文件
-func loadCSV(root *v1.Root, path string, into interface{}) error {
+func loadCSV(path string, into interface{}) error {
 	f, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("open file %q error: %w", path, err)
@@ -121,17 +182,14 @@ func loadCSV(root *v1.Root, path string, into interface{}) error {
 		err = loadCSVToAssetsGoods(r, obj)
 	case *[]v1.Transaction:
 		err = loadCSVToAssetsTransactions(r, obj)
+	case *[]v1.PriceRecord:
+		err = loadCSVToAssetsPrices(r, obj)
 	default:
 		return fmt.Errorf("can not load csv to %T", into)
 	}
 	if err != nil {
 		return fmt.Errorf("load csv to %T error: %w", into, err)
 	}
-
-	// 合并数据
-	if err := Merge(root, into); err != nil {
-		return fmt.Errorf("merge file %q error: %w", path, err)
-	}
 	return nil
 }
 
@@ -180,7 +238,7 @@ func loadCSVToIncomeDetails(r *csv.Reader, into *[]v1.IncomeItem) error {
 		if row[5] != "" {
 			tags := map[string]string{}
 			for _, item := range strings.Split(row[5], " ") {
-				divided := strings.Split(item, ":")
+				divided := strings.SplitN(item, ":", 2)
 				if len(divided) != 2 {
 					continue
 				}
@@ -232,6 +290,37 @@ func loadCSVToAssetsGoods(r *csv.Reader, into *[]v1.GoodsInfo) error {
 	return nil
 }
 
+// loadCSVToAssetsPrices 加载 CSV 到 []v1.PriceRecord
+func loadCSVToAssetsPrices(r *csv.Reader, into *[]v1.PriceRecord) error {
+	rows, err := r.ReadAll()
+	if err != nil {
+		return fmt.Errorf("read csv error: %w", err)
+	}
+	if len(rows) < 2 {
+		return nil
+	}
+
+	ret := make([]v1.PriceRecord, len(rows)-1)
+	for i, row := range rows[1:] {
+		if len(row) != 3 {
+			return fmt.Errorf("the number of columns is not as expected: %d (expected: 3)", len(row))
+		}
+
+		d, err := time.Parse(time.DateOnly, row[0])
+		if err != nil {
+			return fmt.Errorf("parse Date %q at line %d error: %w", row[0], i+2, err)
+		}
+		ret[i].Date = v1.Date{Time: d}
+		ret[i].Name = row[1]
+		ret[i].Price, err = decimal.NewFromString(row[2])
+		if err != nil {
+			return fmt.Errorf("parse Price %q at line %d error: %w", row[2], i+2, err)
+		}
+	}
+	*into = ret
+	return nil
+}
+
 // loadCSVToAssetsTransactions 加载 CSV 到 []v1.Transaction
 func loadCSVToAssetsTransactions(r *csv.Reader, into *[]v1.Transaction) error {
 
@@ -245,8 +334,8 @@ func loadCSVToAssetsTransactions(r *csv.Reader, into *[]v1.Transaction) error {
 
 	ret := make([]v1.Transaction, len(rows)-1)
 	for i, row := range rows[1:] {
-		if len(row) != 9 {
-			return fmt.Errorf("the number of columns is not as expected: %d (expected: 9)", len(row))
+		if len(row) != 9 && len(row) != 10 {
+			return fmt.Errorf("the number of columns is not as expected: %d (expected: 9 or 10)", len(row))
 		}
 
 		d, err := time.Parse(time.DateOnly, row[0])
@@ -280,6 +369,9 @@ func loadCSVToAssetsTransactions(r *csv.Reader, into *[]v1.Transaction) error {
 		}
 		ret[i].Reason = row[7]
 		ret[i].Comment = row[8]
+		if len(row) > 9 {
+			ret[i].ID = row[9]
+		}
 	}
 	*into = ret
 	return nil
@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"fmt"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// Split 将合并的 assets 或 income 文件内容拆分为各类型文件内容
+//
+// data 为 *v1.Assets 或 *v1.Income ，返回各类型文件内容（类型见 NewFileData），空的部分不返回
+func Split(data interface{}) (map[FileKind]interface{}, error) {
+	ret := map[FileKind]interface{}{}
+	switch obj := data.(type) {
+	case *v1.Assets:
+		if len(obj.Goods) > 0 {
+			ret[FileKindAssetsGoods] = &obj.Goods
+		}
+		if len(obj.Transactions) > 0 {
+			ret[FileKindAssetsTransactions] = &obj.Transactions
+		}
+		if len(obj.Checkpoints) > 0 {
+			ret[FileKindAssetsCheckpoints] = &obj.Checkpoints
+		}
+		if len(obj.Prices) > 0 {
+			ret[FileKindAssetsPrices] = &obj.Prices
+		}
+	case *v1.Income:
+		if len(obj.Details) > 0 {
+			ret[FileKindIncomeDetails] = &obj.Details
+		}
+	default:
+		return nil, fmt.Errorf("can not split %T", data)
+	}
+	return ret, nil
+}
+
+// Combine 将多个账本文件内容合并为 kind 类型的文件内容
+//
+// kind 为 FileKindAssets 或 FileKindIncome ， data 中各元素类型见 NewFileData ，且须属于 kind
+func Combine(kind FileKind, data ...interface{}) (interface{}, error) {
+	root := &v1.Root{}
+	for _, d := range data {
+		switch d.(type) {
+		case *v1.Assets, *[]v1.GoodsInfo, *[]v1.Transaction, *[]v1.Checkpoint, *[]v1.PriceRecord:
+			if kind != FileKindAssets {
+				return nil, fmt.Errorf("can not combine %T into %q", d, kind)
+			}
+		case *v1.Income, *[]v1.IncomeItem:
+			if kind != FileKindIncome {
+				return nil, fmt.Errorf("can not combine %T into %q", d, kind)
+			}
+		}
+		if err := Merge(root, d); err != nil {
+			return nil, err
+		}
+	}
+
+	switch kind {
+	case FileKindAssets:
+		return &root.Assets, nil
+	case FileKindIncome:
+		return &root.Income, nil
+	}
+	return nil, fmt.Errorf("can not combine into %q", kind)
+}
+
+// DefaultFileName 返回指定类型账本文件以指定格式保存时的默认文件名
+func DefaultFileName(kind FileKind, format Format) string {
+	return string(kind) + "." + string(format)
+}
@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestSplitAndCombine 测试 Split 和 Combine 方法
+func TestSplitAndCombine(t *testing.T) {
+	d, _ := time.Parse(time.DateOnly, "2024-12-24")
+	assets := &v1.Assets{
+		Goods: []v1.GoodsInfo{{Name: "CNY", Price: decimal.New(1, 0), Base: true}},
+		Transactions: []v1.Transaction{{
+			Date:    v1.Date{Time: d},
+			To:      &v1.Goods{Quantity: decimal.New(100, 0), Name: "CNY", Custodian: "Bank"},
+			Reason:  "工资",
+			Comment: "十二月",
+			ID:      "T1",
+		}},
+	}
+
+	parts, err := Split(assets)
+	if err != nil {
+		t.Fatalf("split error: %v", err)
+	}
+	if len(parts) != 2 {
+		t.Errorf("unexpected parts: %v (expected: goods and transactions)", parts)
+	}
+	if _, ok := parts[FileKindAssetsCheckpoints]; ok {
+		t.Errorf("unexpected empty checkpoints part")
+	}
+
+	combined, err := Combine(FileKindAssets, parts[FileKindAssetsGoods], parts[FileKindAssetsTransactions])
+	if err != nil {
+		t.Fatalf("combine error: %v", err)
+	}
+	if !reflect.DeepEqual(combined, assets) {
+		t.Errorf("unexpected combined: %v (expected: %v)", combined, assets)
+	}
+
+	if _, err := Combine(FileKindIncome, parts[FileKindAssetsGoods]); err == nil {
+		t.Errorf("expected error when combining goods into income")
+	}
+}
+
+// TestEncodeCSVIncomeTags 测试以 CSV 格式输出收入标签
+func TestEncodeCSVIncomeTags(t *testing.T) {
+	d, _ := time.Parse(time.DateOnly, "2024-12-24")
+	items := []v1.IncomeItem{{Date: v1.Date{Time: d}, Tags: map[string]string{"url": "a:b"}}}
+	buf := &bytes.Buffer{}
+	if err := EncodeCSV(buf, items); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	var loaded []v1.IncomeItem
+	if err := loadCSVToIncomeDetails(csv.NewReader(buf), &loaded); err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded[0].Tags, items[0].Tags) {
+		t.Errorf("unexpected tags: %v (expected: %v)", loaded[0].Tags, items[0].Tags)
+	}
+
+	items[0].Tags = map[string]string{"company": "A B"}
+	if err := EncodeCSV(&bytes.Buffer{}, items); err == nil {
+		t.Errorf("expected error when tag value contains space")
+	}
+}
@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// DuplicatesPolicy 重复交易处理策略
+type DuplicatesPolicy string
+
+// DuplicatesPolicy 的可选值
+const (
+	// DuplicatesWarn 输出警告并保留重复交易
+	DuplicatesWarn DuplicatesPolicy = "warn"
+	// DuplicatesDrop 输出警告并丢弃重复交易
+	DuplicatesDrop DuplicatesPolicy = "drop"
+	// DuplicatesAllow 不检查重复交易
+	DuplicatesAllow DuplicatesPolicy = "allow"
+)
+
+// FindDuplicateTransactions 查找重复交易
+//
+// 返回 transactions 中指纹与之前某条交易（或 existing 中某条交易）相同的交易的下标
+func FindDuplicateTransactions(existing, transactions []v1.Transaction) []int {
+	seen := make(map[string]struct{}, len(existing)+len(transactions))
+	for _, t := range existing {
+		seen[t.Fingerprint()] = struct{}{}
+	}
+	var ret []int
+	for i, t := range transactions {
+		fp := t.Fingerprint()
+		if _, ok := seen[fp]; ok {
+			ret = append(ret, i)
+			continue
+		}
+		seen[fp] = struct{}{}
+	}
+	return ret
+}
+
+// RemoveDuplicateTransactions 移除 transactions 中与之前某条交易（或 existing 中某条交易）重复的交易
+//
+// 返回去重后的交易和被移除的交易
+func RemoveDuplicateTransactions(existing, transactions []v1.Transaction) (kept, removed []v1.Transaction) {
+	dups := FindDuplicateTransactions(existing, transactions)
+	if len(dups) == 0 {
+		return transactions, nil
+	}
+	kept = make([]v1.Transaction, 0, len(transactions)-len(dups))
+	removed = make([]v1.Transaction, 0, len(dups))
+	j := 0
+	for i, t := range transactions {
+		if j < len(dups) && dups[j] == i {
+			removed = append(removed, t)
+			j++
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept, removed
+}
+
+// handleDuplicateTransactions 按策略处理 root 中的重复交易
+func handleDuplicateTransactions(ctx context.Context, root *v1.Root, policy DuplicatesPolicy) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	switch policy {
+	case DuplicatesAllow:
+		return nil
+	case "", DuplicatesWarn:
+		for _, i := range FindDuplicateTransactions(nil, root.Assets.Transactions) {
+			logger.Info(fmt.Sprintf(
+				"WARN duplicate transaction: %s",
+				transactionSummary(root.Assets.Transactions[i]),
+			))
+		}
+	case DuplicatesDrop:
+		var removed []v1.Transaction
+		root.Assets.Transactions, removed = RemoveDuplicateTransactions(nil, root.Assets.Transactions)
+		for _, t := range removed {
+			logger.Info(fmt.Sprintf("WARN drop duplicate transaction: %s", transactionSummary(t)))
+		}
+	default:
+		return fmt.Errorf("unsupported duplicates policy: %q", policy)
+	}
+	return nil
+}
+
+// transactionSummary 返回交易的简短描述
+func transactionSummary(t v1.Transaction) string {
+	goods := func(g *v1.Goods) string {
+		if g == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%s %s(%s)", g.Quantity, g.Name, g.Custodian)
+	}
+	ret := fmt.Sprintf("%s %s -> %s", t.Date, goods(t.From), goods(t.To))
+	if t.ID != "" {
+		ret += fmt.Sprintf(" [%s]", t.ID)
+	}
+	return ret
+}
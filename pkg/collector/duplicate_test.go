@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestRemoveDuplicateTransactions 测试 RemoveDuplicateTransactions 方法
+func TestRemoveDuplicateTransactions(t *testing.T) {
+	d, _ := time.Parse(time.DateOnly, "2024-12-24")
+	buy := v1.Transaction{
+		Date: v1.Date{Time: d},
+		From: &v1.Goods{Quantity: decimal.New(1500, 0), Name: "USD", Custodian: "IBKR"},
+		To:   &v1.Goods{Quantity: decimal.New(10, 0), Name: "AAPL", Custodian: "IBKR"},
+	}
+	// 数量写法不同但数值相同
+	sameBuy := buy
+	sameBuy.From = &v1.Goods{Quantity: decimal.RequireFromString("1500.00"), Name: "USD", Custodian: "IBKR"}
+	// 外部编号不同
+	otherBuy := buy
+	otherBuy.ID = "T2"
+
+	kept, removed := RemoveDuplicateTransactions(nil, []v1.Transaction{buy, sameBuy, otherBuy})
+	if !reflect.DeepEqual(kept, []v1.Transaction{buy, otherBuy}) {
+		t.Errorf("unexpected kept: %v", kept)
+	}
+	if !reflect.DeepEqual(removed, []v1.Transaction{sameBuy}) {
+		t.Errorf("unexpected removed: %v", removed)
+	}
+
+	kept, removed = RemoveDuplicateTransactions([]v1.Transaction{otherBuy}, []v1.Transaction{otherBuy})
+	if len(kept) != 0 {
+		t.Errorf("unexpected kept: %v (expected: empty)", kept)
+	}
+	if len(removed) != 1 {
+		t.Errorf("unexpected removed: %v (expected: 1 transaction)", removed)
+	}
+}
@@ -0,0 +1,369 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// Format 账本文件格式
+type Format string
+
+// Format 的可选值
+const (
+	FormatCSV  Format = "csv"
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// FormatOf 根据文件扩展名返回文件格式，不支持的扩展名返回空字符串
+func FormatOf(path string) Format {
+	switch filepath.Ext(path) {
+	case ".csv":
+		return FormatCSV
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	}
+	return ""
+}
+
+// Encode 将 data 以指定格式输出到 w
+func Encode(w io.Writer, format Format, data interface{}) error {
+	switch format {
+	case FormatCSV:
+		return EncodeCSV(w, data)
+	case FormatYAML:
+		return EncodeYAML(w, data)
+	case FormatJSON:
+		return EncodeJSON(w, data)
+	}
+	return fmt.Errorf("unsupported format: %q", format)
+}
+
+// WriteFile 将 data 按文件扩展名对应的格式写入文件
+func WriteFile(path string, data interface{}) error {
+	format := FormatOf(path)
+	if format == "" {
+		return fmt.Errorf("unsupported file extension: %q", filepath.Ext(path))
+	}
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, format, data); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write file %q error: %w", path, err)
+	}
+	return nil
+}
+
+// FindFiles 返回目录中指定类型的账本文件路径（按文件名排序）
+func FindFiles(dir string, kind FileKind) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("list %q error: %w", dir, err)
+	}
+	var ret []string
+	for _, e := range entries {
+		if e.IsDir() || FileKindOf(e.Name()) != kind {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if !IsSupportedFile(path) {
+			continue
+		}
+		ret = append(ret, path)
+	}
+	return ret, nil
+}
+
+// EncodeJSON 将 data 以 JSON 格式输出到 w
+func EncodeJSON(w io.Writer, data interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("encode %T to json error: %w", data, err)
+	}
+	return nil
+}
+
+// yamlPlainTypes 值需要以非引号形式输出的类型
+//
+// 数值和日期序列化后为字符串，默认会被加上引号
+var yamlPlainTypes = map[reflect.Type]bool{
+	reflect.TypeOf(decimal.Decimal{}): true,
+	reflect.TypeOf(v1.Date{}):         true,
+}
+
+// EncodeYAML 将 data 以 YAML 格式输出到 w
+func EncodeYAML(w io.Writer, data interface{}) error {
+	node := &yaml.Node{}
+	if err := node.Encode(data); err != nil {
+		return fmt.Errorf("encode %T to yaml error: %w", data, err)
+	}
+	unquoteYAMLValues(node, reflect.ValueOf(data))
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return fmt.Errorf("encode %T to yaml error: %w", data, err)
+	}
+	return enc.Close()
+}
+
+// unquoteYAMLValues 去掉 node 中类型在 yamlPlainTypes 中的值的引号， v 为 node 对应的值
+func unquoteYAMLValues(node *yaml.Node, v reflect.Value) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			unquoteYAMLValues(child, v)
+		}
+		return
+	}
+	if yamlPlainTypes[v.Type()] {
+		if node.Kind == yaml.ScalarNode {
+			node.Style = 0
+			node.Tag = ""
+		}
+		return
+	}
+	if !v.CanInterface() {
+		return
+	}
+	if _, ok := v.Interface().(yaml.Marshaler); ok {
+		// 自定义序列化的值结构可能与类型不同
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := map[string]reflect.Value{}
+		yamlStructFields(v, fields)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if field, ok := fields[node.Content[i].Value]; ok {
+				unquoteYAMLValues(node.Content[i+1], field)
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode || v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := reflect.ValueOf(node.Content[i].Value).Convert(v.Type().Key())
+			unquoteYAMLValues(node.Content[i+1], v.MapIndex(key))
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, child := range node.Content {
+			if i < v.Len() {
+				unquoteYAMLValues(child, v.Index(i))
+			}
+		}
+	}
+}
+
+// yamlStructFields 将结构体 v 的字段按 YAML 中的键名添加到 fields ，展开 inline 字段
+func yamlStructFields(v reflect.Value, fields map[string]reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(","+opts+",", ",inline,") {
+			inline := v.Field(i)
+			for inline.Kind() == reflect.Pointer && !inline.IsNil() {
+				inline = inline.Elem()
+			}
+			if inline.Kind() == reflect.Struct {
+				yamlStructFields(inline, fields)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = v.Field(i)
+	}
+}
+
+// EncodeCSV 将 data 以 CSV 格式输出到 w
+func EncodeCSV(w io.Writer, data interface{}) error {
+	csvW := csv.NewWriter(w)
+
+	var err error
+	switch obj := data.(type) {
+	case []v1.Transaction:
+		err = encodeCSVAssetsTransactions(csvW, obj)
+	case *[]v1.Transaction:
+		err = encodeCSVAssetsTransactions(csvW, *obj)
+	case []v1.GoodsInfo:
+		err = encodeCSVAssetsGoods(csvW, obj)
+	case *[]v1.GoodsInfo:
+		err = encodeCSVAssetsGoods(csvW, *obj)
+	case []v1.PriceRecord:
+		err = encodeCSVAssetsPrices(csvW, obj)
+	case *[]v1.PriceRecord:
+		err = encodeCSVAssetsPrices(csvW, *obj)
+	case []v1.IncomeItem:
+		err = encodeCSVIncomeDetails(csvW, obj)
+	case *[]v1.IncomeItem:
+		err = encodeCSVIncomeDetails(csvW, *obj)
+	default:
+		return fmt.Errorf("can not encode %T to csv", data)
+	}
+	if err != nil {
+		return fmt.Errorf("encode %T to csv error: %w", data, err)
+	}
+
+	csvW.Flush()
+	return csvW.Error()
+}
+
+// encodeCSVIncomeDetails 将 []v1.IncomeItem 以 CSV 格式输出
+func encodeCSVIncomeDetails(w *csv.Writer, data []v1.IncomeItem) error {
+	if err := w.Write([]string{
+		"Date", "Gross", "InsuranceAndHF", "Tax", "ConsumptionProportion", "Tags", "Comment",
+	}); err != nil {
+		return err
+	}
+	for _, item := range data {
+		tags := make([]string, 0, len(item.Tags))
+		for k, v := range item.Tags {
+			// CSV 中标签以空格分隔、以第一个冒号分隔键值，无法表示的标签报错而不是静默丢弃
+			if k == "" || strings.ContainsAny(k, ": ") || strings.Contains(v, " ") {
+				return fmt.Errorf("tag %q: %q at %s can not be represented in csv", k, v, item.Date)
+			}
+			tags = append(tags, k+":"+v)
+		}
+		sort.Strings(tags)
+
+		if err := w.Write([]string{
+			item.Date.String(),
+			item.Gross.String(),
+			item.InsuranceAndHF.String(),
+			item.Tax.String(),
+			item.ConsumptionProportion.String(),
+			strings.Join(tags, " "),
+			item.Comment,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeCSVAssetsGoods 将 []v1.GoodsInfo 以 CSV 格式输出
+func encodeCSVAssetsGoods(w *csv.Writer, data []v1.GoodsInfo) error {
+	if err := w.Write([]string{"Name", "Code", "Risk", "Price", "Flags"}); err != nil {
+		return err
+	}
+	for _, info := range data {
+		var flags []string
+		if info.Base {
+			flags = append(flags, "Base")
+		}
+		if info.IgnoreReturn {
+			flags = append(flags, "IgnoreReturn")
+		}
+		if err := w.Write([]string{
+			info.Name,
+			info.Code,
+			string(info.Risk),
+			info.Price.String(),
+			strings.Join(flags, " "),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeCSVAssetsPrices 将 []v1.PriceRecord 以 CSV 格式输出
+func encodeCSVAssetsPrices(w *csv.Writer, data []v1.PriceRecord) error {
+	if err := w.Write([]string{"Date", "Name", "Price"}); err != nil {
+		return err
+	}
+	for _, p := range data {
+		if err := w.Write([]string{p.Date.String(), p.Name, p.Price.String()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeCSVAssetsTransactions 将 []v1.Transaction 以 CSV 格式输出
+func encodeCSVAssetsTransactions(w *csv.Writer, data []v1.Transaction) error {
+	// 仅当存在外部编号时输出 ID 列
+	withID := false
+	for _, t := range data {
+		if t.ID != "" {
+			withID = true
+			break
+		}
+	}
+
+	header := []string{
+		"Date",
+		"From.Quantity", "From.Name", "From.Custodian",
+		"To.Quantity", "To.Name", "To.Custodian",
+		"Reason", "Comment",
+	}
+	if withID {
+		header = append(header, "ID")
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, t := range data {
+		row := make([]string, 0, len(header))
+		row = append(row, t.Date.String())
+		row = append(row, csvGoodsColumns(t.From)...)
+		row = append(row, csvGoodsColumns(t.To)...)
+		row = append(row, t.Reason, t.Comment)
+		if withID {
+			row = append(row, t.ID)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvGoodsColumns 返回商品在 CSV 中的列
+func csvGoodsColumns(g *v1.Goods) []string {
+	if g == nil {
+		return []string{"", "", ""}
+	}
+	return []string{g.Quantity.String(), g.Name, g.Custodian}
+}
@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestEncodeYAML 测试 EncodeYAML 方法
+func TestEncodeYAML(t *testing.T) {
+	d, _ := time.Parse(time.DateOnly, "2024-01-10")
+	data := map[string]interface{}{
+		"goods": []v1.GoodsInfo{{Name: "FUND", Code: "000001", Price: decimal.RequireFromString("1.5")}},
+		"details": []v1.IncomeItem{{
+			Date:  v1.Date{Time: d},
+			Gross: decimal.New(30000, 0),
+			// 标签值为字符串，即使键名与数值字段相同也不去掉引号
+			Tags: map[string]string{"income": "100", "salary": "2024"},
+		}},
+		"sums": map[string]decimal.Decimal{"CNY": decimal.New(-7200, 0)},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := EncodeYAML(buf, data); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	expected := `details:
+  - date: 2024-01-10
+    gross: 30000
+    tags:
+      income: "100"
+      salary: "2024"
+goods:
+  - name: FUND
+    code: "000001"
+    price: 1.5
+sums:
+  CNY: -7200
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
@@ -30,6 +30,10 @@ func Merge(root *v1.Root, data interface{}) error {
 		return mergeAssetsTransactions(root, d)
 	case []v1.Checkpoint:
 		return mergeAssetsCheckpoints(root, d)
+	case *[]v1.PriceRecord:
+		return mergeAssetsPrices(root, *d)
+	case []v1.PriceRecord:
+		return mergeAssetsPrices(root, d)
 	default:
 		return fmt.Errorf("can not merge %T to *v1.Root", data)
 	}
@@ -65,6 +69,9 @@ func mergeAssets(root *v1.Root, data *v1.Assets) error {
 	if err := mergeAssetsCheckpoints(root, data.Checkpoints); err != nil {
 		return err
 	}
+	if err := mergeAssetsPrices(root, data.Prices); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -90,8 +97,8 @@ func mergeAssetsGoods(root *v1.Root, data []v1.GoodsInfo) error {
 func mergeAssetsTransactions(root *v1.Root, data []v1.Transaction) error {
 	// 追加
 	root.Assets.Transactions = append(root.Assets.Transactions, data...)
-	// 排序
-	sort.Slice(root.Assets.Transactions, func(i, j int) bool {
+	// 排序（保持同一天交易的原有顺序）
+	sort.SliceStable(root.Assets.Transactions, func(i, j int) bool {
 		return root.Assets.Transactions[i].Date.Before(root.Assets.Transactions[j].Date.Time)
 	})
 	return nil
@@ -107,3 +114,14 @@ func mergeAssetsCheckpoints(root *v1.Root, data []v1.Checkpoint) error {
 	})
 	return nil
 }
+
+// mergeAssetsPrices 将 data 合并到 root.Assets.Prices
+func mergeAssetsPrices(root *v1.Root, data []v1.PriceRecord) error {
+	// 追加
+	root.Assets.Prices = append(root.Assets.Prices, data...)
+	// 排序（保持同一天价格的原有顺序）
+	sort.SliceStable(root.Assets.Prices, func(i, j int) bool {
+		return root.Assets.Prices[i].Date.Before(root.Assets.Prices[j].Date.Time)
+	})
+	return nil
+}
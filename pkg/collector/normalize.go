@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// ErrYAMLComments 重新编码会丢失 YAML 文件中的注释
+var ErrYAMLComments = errors.New("yaml file has comments that would be lost")
+
+// Normalize 规范化账本数据
+//
+// data 类型见 NewFileData 。交易、检查点和收入明细按日期稳定排序，去掉文本首尾的空白。
+// 数值和日期在编码时以规范形式输出
+func Normalize(data interface{}) {
+	switch obj := data.(type) {
+	case *v1.Root:
+		Normalize(&obj.Assets)
+		Normalize(&obj.Income)
+	case *v1.Assets:
+		Normalize(&obj.Goods)
+		Normalize(&obj.Transactions)
+		Normalize(&obj.Checkpoints)
+		Normalize(&obj.Prices)
+	case *v1.Income:
+		Normalize(&obj.Details)
+	case *[]v1.GoodsInfo:
+		for i := range *obj {
+			g := &(*obj)[i]
+			g.Name = strings.TrimSpace(g.Name)
+			g.Code = strings.TrimSpace(g.Code)
+		}
+	case *[]v1.Transaction:
+		for i := range *obj {
+			t := &(*obj)[i]
+			normalizeGoods(t.From)
+			normalizeGoods(t.To)
+			t.Reason = strings.TrimSpace(t.Reason)
+			t.Comment = strings.TrimSpace(t.Comment)
+			t.ID = strings.TrimSpace(t.ID)
+		}
+		sort.SliceStable(*obj, func(i, j int) bool {
+			return (*obj)[i].Date.Before((*obj)[j].Date.Time)
+		})
+	case *[]v1.Checkpoint:
+		for i := range *obj {
+			for j := range (*obj)[i].Goods {
+				(*obj)[i].Goods[j].Name = strings.TrimSpace((*obj)[i].Goods[j].Name)
+			}
+		}
+		sort.SliceStable(*obj, func(i, j int) bool {
+			return (*obj)[i].Date.Before((*obj)[j].Date.Time)
+		})
+	case *[]v1.PriceRecord:
+		for i := range *obj {
+			(*obj)[i].Name = strings.TrimSpace((*obj)[i].Name)
+		}
+		sort.SliceStable(*obj, func(i, j int) bool {
+			return (*obj)[i].Date.Before((*obj)[j].Date.Time)
+		})
+	case *[]v1.IncomeItem:
+		for i := range *obj {
+			(*obj)[i].Comment = strings.TrimSpace((*obj)[i].Comment)
+		}
+		sort.SliceStable(*obj, func(i, j int) bool {
+			return (*obj)[i].Date.Before((*obj)[j].Date.Time)
+		})
+	}
+}
+
+// normalizeGoods 规范化交易中的商品
+func normalizeGoods(g *v1.Goods) {
+	if g == nil {
+		return
+	}
+	g.Name = strings.TrimSpace(g.Name)
+	g.Custodian = strings.TrimSpace(g.Custodian)
+}
+
+// FormatFile 以规范形式重新编码账本文件
+//
+// 返回文件内容是否需要变化。 write 为 true 时将规范形式写回文件。不支持的文件返回 false 。
+// 重新编码会丢失 YAML 文件中的注释，因此 force 不为 true 时不写回含注释的 YAML 文件，返回 ErrYAMLComments
+func FormatFile(path string, write, force bool) (bool, error) {
+	data, err := LoadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("load file %q error: %w", path, err)
+	}
+	if data == nil {
+		return false, nil
+	}
+	Normalize(data)
+
+	origin, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("read file %q error: %w", path, err)
+	}
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, FormatOf(path), data); err != nil {
+		return false, fmt.Errorf("encode file %q error: %w", path, err)
+	}
+	if bytes.Equal(origin, buf.Bytes()) {
+		return false, nil
+	}
+	if write && !force && FormatOf(path) == FormatYAML {
+		root := &yaml.Node{}
+		if err := yaml.Unmarshal(origin, root); err != nil {
+			return true, fmt.Errorf("unmarshal file %q as yaml error: %w", path, err)
+		}
+		if hasYAMLComments(root) {
+			return true, fmt.Errorf("can not format %q: %w", path, ErrYAMLComments)
+		}
+	}
+	if write {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return true, fmt.Errorf("write file %q error: %w", path, err)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFormatFile 测试 FormatFile 方法
+func TestFormatFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "income_details.csv")
+	origin := "Date,Gross,InsHF,Tax,CP,Tags,Comment\n" +
+		"2024-02-10,10000.00,1000,500,0.3,, 二月 \n" +
+		"2024-01-10,10000,1000,500,0.3,,\n"
+	if err := os.WriteFile(path, []byte(origin), 0o644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	changed, err := FormatFile(path, false, false)
+	if err != nil {
+		t.Fatalf("format file error: %v", err)
+	}
+	if !changed {
+		t.Errorf("expected changed")
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != origin {
+		t.Errorf("file written in check mode")
+	}
+
+	if _, err := FormatFile(path, true, false); err != nil {
+		t.Fatalf("format file error: %v", err)
+	}
+	content, _ = os.ReadFile(path)
+	expected := "Date,Gross,InsuranceAndHF,Tax,ConsumptionProportion,Tags,Comment\n" +
+		"2024-01-10,10000,1000,500,0.3,,\n" +
+		"2024-02-10,10000,1000,500,0.3,,二月\n"
+	if string(content) != expected {
+		t.Errorf("unexpected content: %q (expected: %q)", content, expected)
+	}
+
+	changed, err = FormatFile(path, false, false)
+	if err != nil {
+		t.Fatalf("format file error: %v", err)
+	}
+	if changed {
+		t.Errorf("expected unchanged after formatting")
+	}
+}
+
+// TestFormatFileWithComments 测试 FormatFile 方法处理含注释的 YAML 文件
+func TestFormatFileWithComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "assets_transactions.yaml")
+	origin := "# 工资卡\n" +
+		"- date: 2024-02-01\n  to: {quantity: 100, name: CNY}\n" +
+		"- date: 2024-01-01\n  to: {quantity: 200, name: CNY}\n"
+	if err := os.WriteFile(path, []byte(origin), 0o644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	// 不强制时不写回
+	changed, err := FormatFile(path, true, false)
+	if !errors.Is(err, ErrYAMLComments) {
+		t.Errorf("unexpected error: %v (expected: %v)", err, ErrYAMLComments)
+	}
+	if !changed {
+		t.Errorf("expected changed")
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != origin {
+		t.Errorf("file with comments written without force")
+	}
+
+	// 仅检查时不报错
+	if _, err := FormatFile(path, false, false); err != nil {
+		t.Errorf("unexpected error in check mode: %v", err)
+	}
+
+	// 强制时写回
+	if _, err := FormatFile(path, true, true); err != nil {
+		t.Fatalf("format file error: %v", err)
+	}
+	changed, err = FormatFile(path, false, false)
+	if err != nil {
+		t.Fatalf("format file error: %v", err)
+	}
+	if changed {
+		t.Errorf("expected unchanged after formatting")
+	}
+}
@@ -0,0 +1,465 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/mattn/go-isatty"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	"github.com/yhlooo/dragon-acct/pkg/commands/options"
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+	"github.com/yhlooo/dragon-acct/pkg/utils/prompt"
+)
+
+// NewAddCommandWithOptions 基于选项创建 add 命令
+func NewAddCommandWithOptions(opts *options.AddOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a transaction or an income record to the ledger",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	// 添加子命令
+	cmd.AddCommand(
+		NewAddTransactionCommandWithOptions(&opts.Transaction),
+		NewAddIncomeCommandWithOptions(&opts.Income),
+	)
+
+	return cmd
+}
+
+// NewAddTransactionCommandWithOptions 基于选项创建 add tx 命令
+func NewAddTransactionCommandWithOptions(opts *options.AddTransactionOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tx",
+		Short: "Add a transaction to the ledger",
+		Long: `Add a transaction to the ledger.
+
+Fields not specified by flags are prompted for when stdin is a terminal. Goods must already
+exist in the ledger. The transaction is appended to the last assets transactions file in the
+current directory, which is then written back in canonical form.`,
+		Example: `  dragon add tx
+  dragon add tx --from-quantity 1500 --from-name USD --from-custodian IBKR \
+    --to-quantity 10 --to-name AAPL --to-custodian IBKR --reason 买入`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			logger := logr.FromContextOrDiscard(ctx)
+
+			pwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get current workdir error: %w", err)
+			}
+			data, err := collector.Collect(ctx, pwd, collector.Options{Duplicates: collector.DuplicatesAllow})
+			if err != nil {
+				return fmt.Errorf("collect error: %w", err)
+			}
+			names := newLedgerNames(data)
+
+			// 询问未指定的字段
+			p := addPrompter(opts.NoPrompt)
+			if p != nil {
+				if err := promptTransaction(cmd, p, opts, names); err != nil {
+					return err
+				}
+			}
+
+			t, err := newTransaction(opts, names)
+			if err != nil {
+				return err
+			}
+			if dups := collector.FindDuplicateTransactions(data.Assets.Transactions, []v1.Transaction{t}); len(dups) > 0 {
+				logger.Info("WARN the same transaction already exists in the ledger")
+			}
+
+			return appendToLedger(cmd, p, []v1.Transaction{t})
+		},
+	}
+
+	// 绑定选项到命令行参数
+	opts.AddPFlags(cmd.Flags())
+	// 补全
+	for flag, list := range map[string]func(n *ledgerNames) []string{
+		"from-name":      func(n *ledgerNames) []string { return n.Goods },
+		"to-name":        func(n *ledgerNames) []string { return n.Goods },
+		"from-custodian": func(n *ledgerNames) []string { return n.Custodians },
+		"to-custodian":   func(n *ledgerNames) []string { return n.Custodians },
+		"reason":         func(n *ledgerNames) []string { return n.Reasons },
+	} {
+		_ = cmd.RegisterFlagCompletionFunc(flag, completeLedgerNames(list))
+	}
+
+	return cmd
+}
+
+// NewAddIncomeCommandWithOptions 基于选项创建 add income 命令
+func NewAddIncomeCommandWithOptions(opts *options.AddIncomeOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "income",
+		Short: "Add an income record to the ledger",
+		Long: `Add an income record to the ledger.
+
+Fields not specified by flags are prompted for when stdin is a terminal. The record is appended
+to the last income details file in the current directory, which is then written back in
+canonical form.`,
+		Example: `  dragon add income
+  dragon add income --gross 30000 --insurance-and-hf 4500 --tax 1200 --tag company=A`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			pwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get current workdir error: %w", err)
+			}
+			data, err := collector.Collect(ctx, pwd, collector.Options{Duplicates: collector.DuplicatesAllow})
+			if err != nil {
+				return fmt.Errorf("collect error: %w", err)
+			}
+			names := newLedgerNames(data)
+
+			// 询问未指定的字段
+			p := addPrompter(opts.NoPrompt)
+			if p != nil {
+				if err := promptIncome(cmd, p, opts, names); err != nil {
+					return err
+				}
+			}
+
+			item, err := newIncomeItem(opts)
+			if err != nil {
+				return err
+			}
+			return appendToLedger(cmd, p, []v1.IncomeItem{item})
+		},
+	}
+
+	// 绑定选项到命令行参数
+	opts.AddPFlags(cmd.Flags())
+	// 补全
+	_ = cmd.RegisterFlagCompletionFunc("tag", completeLedgerNames(func(n *ledgerNames) []string {
+		ret := make([]string, 0, len(n.Tags))
+		for _, tag := range n.Tags {
+			ret = append(ret, strings.Replace(tag, ":", "=", 1))
+		}
+		return ret
+	}))
+
+	return cmd
+}
+
+// ledgerNames 账本中已有的名称，用于补全和校验
+type ledgerNames struct {
+	// 商品名
+	Goods []string
+	// 托管机构
+	Custodians []string
+	// 交易原因
+	Reasons []string
+	// 收入标签（ key:value 形式）
+	Tags []string
+}
+
+// newLedgerNames 从账本数据中收集已有的名称
+func newLedgerNames(data *v1.Root) *ledgerNames {
+	goods := map[string]struct{}{}
+	custodians := map[string]struct{}{}
+	reasons := map[string]struct{}{}
+	tags := map[string]struct{}{}
+	for _, g := range data.Assets.Goods {
+		goods[g.Name] = struct{}{}
+	}
+	for _, t := range data.Assets.Transactions {
+		for _, g := range []*v1.Goods{t.From, t.To} {
+			if g != nil && g.Custodian != "" {
+				custodians[g.Custodian] = struct{}{}
+			}
+		}
+		if t.Reason != "" {
+			reasons[t.Reason] = struct{}{}
+		}
+	}
+	for _, item := range data.Income.Details {
+		for k, v := range item.Tags {
+			tags[k+":"+v] = struct{}{}
+		}
+	}
+	return &ledgerNames{
+		Goods:      sortedNames(goods),
+		Custodians: sortedNames(custodians),
+		Reasons:    sortedNames(reasons),
+		Tags:       sortedNames(tags),
+	}
+}
+
+// sortedNames 返回排序后的名称
+func sortedNames(names map[string]struct{}) []string {
+	ret := make([]string, 0, len(names))
+	for name := range names {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// completeLedgerNames 返回根据账本中已有名称补全命令行参数的方法
+func completeLedgerNames(
+	list func(n *ledgerNames) []string,
+) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// 补全时不会执行 PersistentPreRunE ，需要自行处理工作目录
+		dir := "."
+		if f := cmd.Flag("chdir"); f != nil && f.Value.String() != "" {
+			dir = f.Value.String()
+		}
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		data, err := collector.Collect(cmd.Context(), absDir, collector.Options{Duplicates: collector.DuplicatesAllow})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return prompt.Complete(toComplete, list(newLedgerNames(data))), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// addPrompter 标准输入为终端且未禁止询问时返回 Prompter ，否则返回 nil
+func addPrompter(noPrompt bool) *prompt.Prompter {
+	if noPrompt || !isatty.IsTerminal(os.Stdin.Fd()) {
+		return nil
+	}
+	return prompt.NewPrompter(os.Stdin, os.Stderr)
+}
+
+// promptTransaction 询问交易中未通过命令行参数指定的字段
+func promptTransaction(
+	cmd *cobra.Command,
+	p *prompt.Prompter,
+	opts *options.AddTransactionOptions,
+	names *ledgerNames,
+) error {
+	flags := cmd.Flags()
+	var err error
+	ask := func(flag string, into *string, question, defaultValue string, candidates []string) {
+		if err != nil || flags.Changed(flag) {
+			return
+		}
+		if candidates != nil {
+			*into, err = p.Choose(question, defaultValue, candidates)
+		} else {
+			*into, err = p.Ask(question, defaultValue)
+		}
+	}
+
+	ask("date", &opts.Date, "Date", time.Now().Format(time.DateOnly), nil)
+	ask("from-name", &opts.FromName, "From goods (empty for none)", "", names.Goods)
+	if opts.FromName != "" {
+		ask("from-quantity", &opts.FromQuantity, "From quantity", "", nil)
+		ask("from-custodian", &opts.FromCustodian, "From custodian", "", names.Custodians)
+	}
+	ask("to-name", &opts.ToName, "To goods (empty for none)", "", names.Goods)
+	if opts.ToName != "" {
+		ask("to-quantity", &opts.ToQuantity, "To quantity", "", nil)
+		ask("to-custodian", &opts.ToCustodian, "To custodian", opts.FromCustodian, names.Custodians)
+	}
+	ask("reason", &opts.Reason, "Reason", "", names.Reasons)
+	ask("comment", &opts.Comment, "Comment", "", nil)
+	return err
+}
+
+// promptIncome 询问收入中未通过命令行参数指定的字段
+func promptIncome(
+	cmd *cobra.Command,
+	p *prompt.Prompter,
+	opts *options.AddIncomeOptions,
+	names *ledgerNames,
+) error {
+	flags := cmd.Flags()
+	var err error
+	ask := func(flag string, into *string, question, defaultValue string) {
+		if err != nil || flags.Changed(flag) {
+			return
+		}
+		*into, err = p.Ask(question, defaultValue)
+	}
+
+	ask("date", &opts.Date, "Date", time.Now().Format(time.DateOnly))
+	ask("gross", &opts.Gross, "Gross", "")
+	ask("insurance-and-hf", &opts.InsuranceAndHF, "Insurance and housing fund", "0")
+	ask("tax", &opts.Tax, "Tax", "0")
+	ask("consumption-proportion", &opts.ConsumptionProportion, "Consumption proportion", "0")
+	if err == nil && !flags.Changed("tag") {
+		opts.Tags = map[string]string{}
+		for {
+			var tag string
+			tag, err = p.Choose("Tag KEY:VALUE (empty to finish)", "", names.Tags)
+			if err != nil || tag == "" {
+				break
+			}
+			k, v, ok := strings.Cut(tag, ":")
+			if !ok {
+				_, _ = fmt.Fprintln(os.Stderr, "tag must be in KEY:VALUE format")
+				continue
+			}
+			opts.Tags[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	ask("comment", &opts.Comment, "Comment", "")
+	return err
+}
+
+// newTransaction 根据选项创建交易并校验
+func newTransaction(opts *options.AddTransactionOptions, names *ledgerNames) (v1.Transaction, error) {
+	date, err := parseDateOrToday(opts.Date)
+	if err != nil {
+		return v1.Transaction{}, err
+	}
+	t := v1.Transaction{
+		Date:    date,
+		Reason:  opts.Reason,
+		Comment: opts.Comment,
+		ID:      opts.ID,
+	}
+	t.From, err = newTransactionGoods("from", opts.FromQuantity, opts.FromName, opts.FromCustodian, names)
+	if err != nil {
+		return v1.Transaction{}, err
+	}
+	t.To, err = newTransactionGoods("to", opts.ToQuantity, opts.ToName, opts.ToCustodian, names)
+	if err != nil {
+		return v1.Transaction{}, err
+	}
+	if t.From == nil && t.To == nil {
+		return v1.Transaction{}, fmt.Errorf("at least one of from goods and to goods must be specified")
+	}
+	return t, nil
+}
+
+// newTransactionGoods 创建交易中的商品并校验，商品名为空时返回 nil
+func newTransactionGoods(side, quantity, name, custodian string, names *ledgerNames) (*v1.Goods, error) {
+	if name == "" {
+		return nil, nil
+	}
+	found := false
+	for _, n := range names.Goods {
+		if n == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s goods %q not found in the ledger, please add it to assets goods first", side, name)
+	}
+	if quantity == "" {
+		return nil, fmt.Errorf("%s quantity must be specified", side)
+	}
+	q, err := decimal.NewFromString(quantity)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s quantity %q error: %w", side, quantity, err)
+	}
+	if q.IsNegative() {
+		return nil, fmt.Errorf("%s quantity must not be negative: %s", side, quantity)
+	}
+	return &v1.Goods{Quantity: q, Name: name, Custodian: custodian}, nil
+}
+
+// newIncomeItem 根据选项创建收入项并校验
+func newIncomeItem(opts *options.AddIncomeOptions) (v1.IncomeItem, error) {
+	date, err := parseDateOrToday(opts.Date)
+	if err != nil {
+		return v1.IncomeItem{}, err
+	}
+	item := v1.IncomeItem{
+		Date:    date,
+		Comment: opts.Comment,
+	}
+	if len(opts.Tags) > 0 {
+		item.Tags = opts.Tags
+	}
+
+	if opts.Gross == "" {
+		return v1.IncomeItem{}, fmt.Errorf("gross must be specified")
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		into  *decimal.Decimal
+	}{
+		{name: "gross", value: opts.Gross, into: &item.Gross},
+		{name: "insurance and housing fund", value: opts.InsuranceAndHF, into: &item.InsuranceAndHF},
+		{name: "tax", value: opts.Tax, into: &item.Tax},
+		{name: "consumption proportion", value: opts.ConsumptionProportion, into: &item.ConsumptionProportion},
+	} {
+		if field.value == "" {
+			continue
+		}
+		*field.into, err = decimal.NewFromString(field.value)
+		if err != nil {
+			return v1.IncomeItem{}, fmt.Errorf("parse %s %q error: %w", field.name, field.value, err)
+		}
+		if field.into.IsNegative() {
+			return v1.IncomeItem{}, fmt.Errorf("%s must not be negative: %s", field.name, field.value)
+		}
+	}
+	if item.ConsumptionProportion.GreaterThan(decimal.New(1, 0)) {
+		return v1.IncomeItem{}, fmt.Errorf("consumption proportion must be between 0 and 1: %s", item.ConsumptionProportion)
+	}
+	return item, nil
+}
+
+// parseDateOrToday 解析日期，为空时返回当天
+func parseDateOrToday(s string) (v1.Date, error) {
+	if s == "" {
+		s = time.Now().Format(time.DateOnly)
+	}
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return v1.Date{}, fmt.Errorf("parse date %q error: %w", s, err)
+	}
+	return v1.Date{Time: d}, nil
+}
+
+// appendToLedger 将数据追加到当前目录的账本，交互模式下先确认
+func appendToLedger(cmd *cobra.Command, p *prompt.Prompter, data interface{}) error {
+	logger := logr.FromContextOrDiscard(cmd.Context())
+
+	if p != nil {
+		_, _ = fmt.Fprintln(os.Stderr)
+		if err := collector.EncodeYAML(os.Stderr, data); err != nil {
+			return err
+		}
+		ok, err := p.Confirm("Append to the ledger?", true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("canceled")
+		}
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get current workdir error: %w", err)
+	}
+	path, err := collector.AppendToLedger(pwd, data)
+	if err != nil {
+		return fmt.Errorf("append to ledger error: %w", err)
+	}
+	logger.Info(fmt.Sprintf("appended to %q", path))
+	return nil
+}
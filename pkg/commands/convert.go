@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	"github.com/yhlooo/dragon-acct/pkg/commands/options"
+)
+
+// NewConvertCommandWithOptions 基于选项创建 convert 命令
+func NewConvertCommandWithOptions(opts *options.ConvertOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert FILE...",
+		Short: "Convert ledger files between csv, yaml and json",
+		Long: `Convert ledger files between csv, yaml and json.
+
+Without --split or --combine exactly one FILE is converted and written to --output (or stdout).
+With --split a combined assets or income FILE is split into per-kind files in the --output
+directory. With --combine per-kind FILEs are combined into one assets or income file. Existing
+output files are never overwritten.`,
+		Example: `  dragon convert assets_transactions.yaml -o assets_transactions.csv
+  dragon convert assets.yaml --split -o ledger/ -f csv
+  dragon convert assets_goods.csv assets_transactions.csv --combine -o assets.yaml`,
+		Args: cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			if !opts.Combine && len(args) != 1 {
+				return fmt.Errorf("only one file can be converted without --combine")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := logr.FromContextOrDiscard(cmd.Context())
+
+			// 加载输入
+			inputs := make([]interface{}, len(args))
+			for i, path := range args {
+				if !collector.IsSupportedFile(path) {
+					return fmt.Errorf("%q is not a supported ledger file", path)
+				}
+				data, err := collector.LoadFile(path)
+				if err != nil {
+					return fmt.Errorf("load file %q error: %w", path, err)
+				}
+				inputs[i] = data
+			}
+
+			switch {
+			case opts.Split:
+				return convertSplit(logger, opts, args[0], inputs[0])
+			case opts.Combine:
+				kind := collector.FileKindOf(opts.Output)
+				if kind != collector.FileKindAssets && kind != collector.FileKindIncome {
+					// 未指定输出文件名时根据输入决定
+					kind = collector.FileKindAssets
+					if k := collector.FileKindOf(args[0]); k == collector.FileKindIncome ||
+						k == collector.FileKindIncomeDetails {
+						kind = collector.FileKindIncome
+					}
+					if opts.Output != "" {
+						logger.Info(fmt.Sprintf(
+							"WARN %q will not be loaded as %q file because of its name", opts.Output, kind,
+						))
+					}
+				}
+				data, err := collector.Combine(kind, inputs...)
+				if err != nil {
+					return err
+				}
+				return convertWrite(opts.Output, convertFormat(opts, collector.FormatYAML), data)
+			default:
+				if opts.Output != "" && collector.FileKindOf(opts.Output) != collector.FileKindOf(args[0]) {
+					logger.Info(fmt.Sprintf(
+						"WARN %q will not be loaded as %q file because of its name",
+						opts.Output, collector.FileKindOf(args[0]),
+					))
+				}
+				return convertWrite(opts.Output, convertFormat(opts, collector.FormatYAML), inputs[0])
+			}
+		},
+	}
+
+	// 绑定选项到命令行参数
+	opts.AddPFlags(cmd.Flags())
+
+	return cmd
+}
+
+// convertFormat 返回输出格式
+func convertFormat(opts *options.ConvertOptions, defaultFormat collector.Format) collector.Format {
+	if opts.Format != "" {
+		return collector.Format(opts.Format)
+	}
+	if format := collector.FormatOf(opts.Output); format != "" {
+		return format
+	}
+	return defaultFormat
+}
+
+// convertSplit 将合并的文件拆分为各类型文件输出到目录
+func convertSplit(logger logr.Logger, opts *options.ConvertOptions, path string, data interface{}) error {
+	parts, err := collector.Split(data)
+	if err != nil {
+		return fmt.Errorf("split %q error: %w", path, err)
+	}
+	dir := opts.Output
+	if dir == "" {
+		dir = "."
+	}
+	// 写入前检查所有输出文件，避免部分写入
+	type target struct {
+		path   string
+		format collector.Format
+		data   interface{}
+	}
+	var targets []target
+	for _, kind := range []collector.FileKind{
+		collector.FileKindAssetsGoods,
+		collector.FileKindAssetsTransactions,
+		collector.FileKindAssetsCheckpoints,
+		collector.FileKindAssetsPrices,
+		collector.FileKindIncomeDetails,
+	} {
+		part, ok := parts[kind]
+		if !ok {
+			continue
+		}
+		// 默认 csv ，检查点只能以 yaml 或 json 格式保存
+		format := collector.FormatCSV
+		if opts.Format != "" {
+			format = collector.Format(opts.Format)
+		}
+		if kind == collector.FileKindAssetsCheckpoints && format == collector.FormatCSV {
+			format = collector.FormatYAML
+		}
+
+		outPath := filepath.Join(dir, collector.DefaultFileName(kind, format))
+		if _, err := os.Stat(outPath); err == nil {
+			return fmt.Errorf("file %q already exists", outPath)
+		}
+		targets = append(targets, target{path: outPath, format: format, data: part})
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("make directory %q error: %w", dir, err)
+	}
+	for _, t := range targets {
+		if err := convertWrite(t.path, t.format, t.data); err != nil {
+			return err
+		}
+		logger.Info(fmt.Sprintf("written %q", t.path))
+	}
+	return nil
+}
+
+// convertWrite 将 data 以指定格式写入文件，文件路径为空时输出到标准输出
+//
+// 不覆盖已存在的文件
+func convertWrite(path string, format collector.Format, data interface{}) error {
+	if path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("file %q already exists", path)
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := collector.Encode(buf, format, data); err != nil {
+		return err
+	}
+	if path == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write file %q error: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	"github.com/yhlooo/dragon-acct/pkg/commands/options"
+	"github.com/yhlooo/dragon-acct/pkg/exports"
+)
+
+// NewExportCommandWithOptions 基于选项创建 export 命令
+func NewExportCommandWithOptions(opts *options.ExportOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the ledger to other accounting formats",
+		Args:  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// 获取输入
+			pwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get current workdir error: %w", err)
+			}
+			data, err := collector.Collect(ctx, pwd, collector.Options{})
+			if err != nil {
+				return fmt.Errorf("collect error: %w", err)
+			}
+
+			// 输出
+			w := os.Stdout
+			if opts.Output == "" && opts.Format == string(exports.FormatXLSX) && isatty.IsTerminal(w.Fd()) {
+				return fmt.Errorf("refusing to write xlsx to a terminal, please specify --output")
+			}
+			if opts.Output != "" {
+				w, err = os.OpenFile(opts.Output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+				if err != nil {
+					return fmt.Errorf("open %q error: %w", opts.Output, err)
+				}
+				defer func() { _ = w.Close() }()
+			}
+			incomeOpts, err := options.IncomeAnalyseOptions(&opts.IncomeTax, &opts.Insurance)
+			if err != nil {
+				return err
+			}
+			return exports.Export(ctx, w, exports.Format(opts.Format), data, exports.Options{
+				Currency:    opts.Currency,
+				ShowHistory: opts.ShowHistory,
+				Raw:         opts.Raw,
+				Income:      incomeOpts,
+			})
+		},
+	}
+
+	// 绑定选项到命令行参数
+	opts.AddPFlags(cmd.Flags())
+
+	return cmd
+}
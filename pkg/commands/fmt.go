@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	"github.com/yhlooo/dragon-acct/pkg/commands/options"
+)
+
+// NewFmtCommandWithOptions 基于选项创建 fmt 命令
+func NewFmtCommandWithOptions(opts *options.FmtOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fmt [PATH...]",
+		Short: "Normalize ledger files into canonical form",
+		Long: `Normalize ledger files into canonical form.
+
+Transactions, checkpoints and income details are sorted by date, decimals and dates are
+written in canonical form and files are re-encoded in their original format. Comments in
+YAML files can not be preserved, so YAML files with comments are skipped unless --force is
+given. PATH can be a ledger file or a directory (not recursive), default to the current
+working directory.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := logr.FromContextOrDiscard(cmd.Context())
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+
+			// 确定文件
+			var files []string
+			for _, arg := range args {
+				info, err := os.Stat(arg)
+				if err != nil {
+					return fmt.Errorf("stat %q error: %w", arg, err)
+				}
+				if !info.IsDir() {
+					if !collector.IsSupportedFile(arg) {
+						return fmt.Errorf("%q is not a supported ledger file", arg)
+					}
+					files = append(files, arg)
+					continue
+				}
+				entries, err := os.ReadDir(arg)
+				if err != nil {
+					return fmt.Errorf("list %q error: %w", arg, err)
+				}
+				for _, e := range entries {
+					path := filepath.Join(arg, e.Name())
+					if e.IsDir() || !collector.IsSupportedFile(path) {
+						continue
+					}
+					files = append(files, path)
+				}
+			}
+
+			// 格式化
+			changed := 0
+			skipped := 0
+			for _, path := range files {
+				ok, err := collector.FormatFile(path, !opts.Check, opts.Force)
+				if errors.Is(err, collector.ErrYAMLComments) {
+					logger.Info(fmt.Sprintf("WARN skip %q: formatting it would lose its comments, use --force to format it anyway", path))
+					skipped++
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if ok {
+					changed++
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), path)
+				}
+			}
+
+			if opts.Check && changed > 0 {
+				return fmt.Errorf("%d file(s) need formatting", changed)
+			}
+			if skipped > 0 {
+				return fmt.Errorf("%d file(s) with comments not formatted", skipped)
+			}
+			return nil
+		},
+	}
+
+	// 绑定选项到命令行参数
+	opts.AddPFlags(cmd.Flags())
+
+	return cmd
+}
@@ -1,13 +1,20 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 
+	"github.com/go-logr/logr"
+	"github.com/olekukonko/tablewriter"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 
+	"github.com/yhlooo/dragon-acct/pkg/collector"
 	"github.com/yhlooo/dragon-acct/pkg/commands/options"
 	"github.com/yhlooo/dragon-acct/pkg/imports"
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
 )
 
 // NewImportCommandWithOptions 基于选项创建 import 命令
@@ -20,7 +27,15 @@ func NewImportCommandWithOptions(opts *options.ImportOptions) *cobra.Command {
 			return opts.Validate()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// 确定输入输出
+			ctx := cmd.Context()
+			logger := logr.FromContextOrDiscard(ctx)
+
+			if opts.List {
+				listImporters(cmd.OutOrStdout())
+				return nil
+			}
+
+			// 读取输入
 			r := os.Stdin
 			if len(args) > 0 {
 				var err error
@@ -30,20 +45,73 @@ func NewImportCommandWithOptions(opts *options.ImportOptions) *cobra.Command {
 				}
 				defer func() { _ = r.Close() }()
 			}
+			raw, err := io.ReadAll(r)
+			if err != nil {
+				return fmt.Errorf("read input error: %w", err)
+			}
+
+			// 确定导入器
+			var importer imports.Importer
+			if opts.DataType != "" {
+				importer, _ = imports.Get(opts.DataType)
+			} else {
+				importer, err = imports.Detect(raw)
+				if err != nil {
+					return err
+				}
+				logger.V(1).Info(fmt.Sprintf("detected data type: %q", importer.Name()))
+			}
+
+			// 导入
+			result, err := importer.Import(ctx, bytes.NewReader(raw), imports.Options{
+				Custodian: opts.Custodian,
+				Currency:  opts.Currency,
+				Mapping:   opts.Mapping,
+			})
+			if err != nil {
+				return fmt.Errorf("import %s data error: %w", importer.Name(), err)
+			}
+
+			pwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get current workdir error: %w", err)
+			}
+
+			// 跳过账本中已存在的交易和商品
+			var existingGoods []v1.GoodsInfo
+			if opts.SkipExisting || opts.Merge {
+				data, err := collector.Collect(ctx, pwd, collector.Options{Duplicates: collector.DuplicatesAllow})
+				if err != nil {
+					return fmt.Errorf("collect error: %w", err)
+				}
+				var skipped []v1.Transaction
+				result.Transactions, skipped = collector.RemoveDuplicateTransactions(
+					data.Assets.Transactions,
+					result.Transactions,
+				)
+				logger.Info(fmt.Sprintf("skipped %d transactions already present in the ledger", len(skipped)))
+				result.Goods = newGoods(data.Assets.Goods, result.Goods)
+				existingGoods = data.Assets.Goods
+			}
+
+			// 合并到账本
+			if opts.Merge {
+				return mergeIntoLedger(logger, pwd, existingGoods, result)
+			}
+
+			// 输出
 			w := os.Stdout
 			if opts.Output != "" {
-				var err error
 				w, err = os.OpenFile(opts.Output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
 				if err != nil {
 					return fmt.Errorf("open %q error: %w", opts.Output, err)
 				}
 				defer func() { _ = w.Close() }()
 			}
-			switch opts.DataType {
-			case "futu":
-				return imports.ImportFutu(cmd.Context(), r, w)
+			if opts.Format == string(collector.FormatCSV) {
+				return collector.EncodeCSV(w, result.Transactions)
 			}
-			return nil
+			return collector.Encode(w, collector.Format(opts.Format), result)
 		},
 	}
 
@@ -53,3 +121,114 @@ func NewImportCommandWithOptions(opts *options.ImportOptions) *cobra.Command {
 	return cmd
 
 }
+
+// listImporters 输出可用的导入器列表
+func listImporters(w io.Writer) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Type", "Description"})
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT})
+	for _, importer := range imports.List() {
+		table.Append([]string{importer.Name(), importer.Description()})
+	}
+	table.Render()
+}
+
+// newGoods 返回 goods 中不存在于 existing 的商品信息
+func newGoods(existing, goods []v1.GoodsInfo) []v1.GoodsInfo {
+	names := make(map[string]struct{}, len(existing))
+	for _, g := range existing {
+		names[g.Name] = struct{}{}
+	}
+	var ret []v1.GoodsInfo
+	for _, g := range goods {
+		if _, ok := names[g.Name]; ok {
+			continue
+		}
+		ret = append(ret, g)
+	}
+	return ret
+}
+
+// mergeIntoLedger 将导入结果合并到账本， existingGoods 为账本中已有的商品信息
+func mergeIntoLedger(logger logr.Logger, dir string, existingGoods []v1.GoodsInfo, data *v1.Assets) error {
+	if len(data.Goods) > 0 {
+		fillGoodsPrices(existingGoods, data.Goods, data.Transactions)
+		path, err := collector.AppendToLedger(dir, data.Goods)
+		if err != nil {
+			return fmt.Errorf("append goods to ledger error: %w", err)
+		}
+		for _, g := range data.Goods {
+			if g.Price.IsZero() {
+				logger.Info(fmt.Sprintf(
+					"WARN added goods %q without price to %q, please set its price and risk", g.Name, path,
+				))
+				continue
+			}
+			logger.Info(fmt.Sprintf(
+				"added goods %q with price %s to %q, please complete its price and risk",
+				g.Name, g.Price, path,
+			))
+		}
+	}
+	if len(data.Transactions) > 0 {
+		path, err := collector.AppendToLedger(dir, data.Transactions)
+		if err != nil {
+			return fmt.Errorf("append transactions to ledger error: %w", err)
+		}
+		logger.Info(fmt.Sprintf("added %d transactions to %q", len(data.Transactions), path))
+	}
+	return nil
+}
+
+// defaultOperatingCurrency 账本中没有计价货币时使用的计价货币
+const defaultOperatingCurrency = "CNY"
+
+// operatingCurrency 返回账本的计价货币，即已有商品中单价为 1 的基础商品
+func operatingCurrency(existingGoods []v1.GoodsInfo) string {
+	for _, g := range existingGoods {
+		if g.Base && g.Price.Equal(decimal.New(1, 0)) {
+			return g.Name
+		}
+	}
+	return defaultOperatingCurrency
+}
+
+// fillGoodsPrices 补充新商品 goods 中缺失的价格，以免分析时成本为零
+//
+// 账本的计价货币按 1 计价；其它商品（包括其它货币）按 transactions 中最后一笔与已知价格的商品交换的交易计价，
+// 无法计价的保持为零，由调用方提示用户补充
+func fillGoodsPrices(existingGoods, goods []v1.GoodsInfo, transactions []v1.Transaction) {
+	currency := operatingCurrency(existingGoods)
+	prices := map[string]decimal.Decimal{}
+	for _, g := range existingGoods {
+		prices[g.Name] = g.Price
+	}
+	for i, g := range goods {
+		if g.Price.IsZero() && g.Base && g.Name == currency {
+			goods[i].Price = decimal.New(1, 0)
+		}
+		prices[g.Name] = goods[i].Price
+	}
+
+	for i, g := range goods {
+		if !g.Price.IsZero() {
+			continue
+		}
+		for j := len(transactions) - 1; j >= 0; j-- {
+			t := transactions[j]
+			if t.From == nil || t.To == nil {
+				continue
+			}
+			own, other := t.To, t.From
+			if t.From.Name == g.Name {
+				own, other = t.From, t.To
+			}
+			if own.Name != g.Name || own.Quantity.IsZero() || prices[other.Name].IsZero() {
+				continue
+			}
+			goods[i].Price = other.Quantity.Mul(prices[other.Name]).DivRound(own.Quantity, 6)
+			break
+		}
+	}
+}
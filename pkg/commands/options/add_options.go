@@ -0,0 +1,120 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// NewDefaultAddOptions 创建默认 add 命令选项
+func NewDefaultAddOptions() AddOptions {
+	return AddOptions{
+		Transaction: NewDefaultAddTransactionOptions(),
+		Income:      NewDefaultAddIncomeOptions(),
+	}
+}
+
+// AddOptions add 命令选项
+type AddOptions struct {
+	// add tx 命令选项
+	Transaction AddTransactionOptions `json:"tx,omitempty" yaml:"tx,omitempty"`
+	// add income 命令选项
+	Income AddIncomeOptions `json:"income,omitempty" yaml:"income,omitempty"`
+}
+
+// NewDefaultAddTransactionOptions 创建默认 add tx 命令选项
+func NewDefaultAddTransactionOptions() AddTransactionOptions {
+	return AddTransactionOptions{}
+}
+
+// AddTransactionOptions add tx 命令选项
+type AddTransactionOptions struct {
+	// 交易日期，默认为当天
+	Date string `json:"date,omitempty" yaml:"date,omitempty"`
+	// 源商品数量
+	FromQuantity string `json:"fromQuantity,omitempty" yaml:"fromQuantity,omitempty"`
+	// 源商品名
+	FromName string `json:"fromName,omitempty" yaml:"fromName,omitempty"`
+	// 源商品托管机构
+	FromCustodian string `json:"fromCustodian,omitempty" yaml:"fromCustodian,omitempty"`
+	// 目标商品数量
+	ToQuantity string `json:"toQuantity,omitempty" yaml:"toQuantity,omitempty"`
+	// 目标商品名
+	ToName string `json:"toName,omitempty" yaml:"toName,omitempty"`
+	// 目标商品托管机构
+	ToCustodian string `json:"toCustodian,omitempty" yaml:"toCustodian,omitempty"`
+	// 交易原因
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// 备注
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	// 外部编号
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+	// 不交互式询问未指定的字段
+	NoPrompt bool `json:"noPrompt,omitempty" yaml:"noPrompt,omitempty"`
+}
+
+// Validate 校验选项是否合法
+func (o *AddTransactionOptions) Validate() error {
+	if o.FromName == "" && o.FromQuantity != "" {
+		return fmt.Errorf("--from-quantity requires --from-name")
+	}
+	if o.ToName == "" && o.ToQuantity != "" {
+		return fmt.Errorf("--to-quantity requires --to-name")
+	}
+	return nil
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *AddTransactionOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Date, "date", o.Date, "Transaction date in YYYY-MM-DD format (default today)")
+	flags.StringVar(&o.FromQuantity, "from-quantity", o.FromQuantity, "Quantity of the goods given")
+	flags.StringVar(&o.FromName, "from-name", o.FromName, "Name of the goods given")
+	flags.StringVar(&o.FromCustodian, "from-custodian", o.FromCustodian, "Custodian of the goods given")
+	flags.StringVar(&o.ToQuantity, "to-quantity", o.ToQuantity, "Quantity of the goods received")
+	flags.StringVar(&o.ToName, "to-name", o.ToName, "Name of the goods received")
+	flags.StringVar(&o.ToCustodian, "to-custodian", o.ToCustodian, "Custodian of the goods received")
+	flags.StringVar(&o.Reason, "reason", o.Reason, "Reason of the transaction")
+	flags.StringVar(&o.Comment, "comment", o.Comment, "Comment of the transaction")
+	flags.StringVar(&o.ID, "id", o.ID, "External ID of the transaction (e.g. trade ID)")
+	flags.BoolVar(&o.NoPrompt, "no-prompt", o.NoPrompt, "Do not prompt for fields not specified by flags")
+}
+
+// NewDefaultAddIncomeOptions 创建默认 add income 命令选项
+func NewDefaultAddIncomeOptions() AddIncomeOptions {
+	return AddIncomeOptions{}
+}
+
+// AddIncomeOptions add income 命令选项
+type AddIncomeOptions struct {
+	// 日期，默认为当天
+	Date string `json:"date,omitempty" yaml:"date,omitempty"`
+	// 税前总额
+	Gross string `json:"gross,omitempty" yaml:"gross,omitempty"`
+	// 保险和住房公积金
+	InsuranceAndHF string `json:"insuranceAndHF,omitempty" yaml:"insuranceAndHF,omitempty"`
+	// 税
+	Tax string `json:"tax,omitempty" yaml:"tax,omitempty"`
+	// 消费比例
+	ConsumptionProportion string `json:"consumptionProportion,omitempty" yaml:"consumptionProportion,omitempty"`
+	// 标签
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// 备注
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	// 不交互式询问未指定的字段
+	NoPrompt bool `json:"noPrompt,omitempty" yaml:"noPrompt,omitempty"`
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *AddIncomeOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Date, "date", o.Date, "Income date in YYYY-MM-DD format (default today)")
+	flags.StringVar(&o.Gross, "gross", o.Gross, "Gross income before tax")
+	flags.StringVar(&o.InsuranceAndHF, "insurance-and-hf", o.InsuranceAndHF, "Social insurance and housing fund")
+	flags.StringVar(&o.Tax, "tax", o.Tax, "Tax")
+	flags.StringVar(
+		&o.ConsumptionProportion, "consumption-proportion", o.ConsumptionProportion,
+		"Proportion of the net income for consumption (0 to 1)",
+	)
+	flags.StringToStringVar(&o.Tags, "tag", o.Tags, "Tags of the income in KEY=VALUE format")
+	flags.StringVar(&o.Comment, "comment", o.Comment, "Comment of the income")
+	flags.BoolVar(&o.NoPrompt, "no-prompt", o.NoPrompt, "Do not prompt for fields not specified by flags")
+}
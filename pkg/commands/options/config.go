@@ -0,0 +1,46 @@
+package options
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LedgerConfigFileName 账本目录中的配置文件名
+const LedgerConfigFileName = "dragon.yaml"
+
+// UserConfigPath 返回用户级配置文件路径
+//
+// 即 $XDG_CONFIG_HOME/dragon/config.yaml ，未设置 XDG_CONFIG_HOME 时使用系统默认的用户配置目录
+func UserConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("get user config dir error: %w", err)
+	}
+	return filepath.Join(dir, "dragon", "config.yaml"), nil
+}
+
+// LoadConfigFile 将配置文件中的选项合并到 o 中，文件不存在时返回 false
+//
+// 配置文件格式与 Options 的 yaml 序列化格式相同，仅覆盖文件中出现的字段，不认识的字段视为错误
+func (o *Options) LoadConfigFile(path string) (bool, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("read config file %q error: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(o); err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("unmarshal config file %q error: %w", path, err)
+	}
+	return true, nil
+}
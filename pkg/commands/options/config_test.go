@@ -0,0 +1,65 @@
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	cmdutil "github.com/yhlooo/dragon-acct/pkg/utils/cmd"
+)
+
+// TestOptions_LoadConfigFile 测试 Options.LoadConfigFile 方法
+func TestOptions_LoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dragon.yaml")
+	opts := NewDefaultOptions()
+
+	// 文件不存在
+	if ok, err := opts.LoadConfigFile(path); ok || err != nil {
+		t.Fatalf("unexpected result for not existing file: %t, %v", ok, err)
+	}
+
+	content := "run:\n  format: json\n  showHistory: true\nadd:\n  income:\n    tags:\n      a: config\n      b: config\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	// 命令行参数优先于配置文件
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts.Run.AddPFlags(flags)
+	opts.Add.Income.AddPFlags(flags)
+	if err := flags.Parse([]string{"--format", "yaml", "--tag", "b=flag,c=flag"}); err != nil {
+		t.Fatalf("parse flags error: %v", err)
+	}
+	restore := cmdutil.SnapshotChangedFlags(flags)
+	if ok, err := opts.LoadConfigFile(path); !ok || err != nil {
+		t.Fatalf("load config file error: %t, %v", ok, err)
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("restore flags error: %v", err)
+	}
+
+	if opts.Run.Format != "yaml" {
+		t.Errorf("expected format %q, got %q", "yaml", opts.Run.Format)
+	}
+	if !opts.Run.ShowHistory {
+		t.Errorf("expected showHistory loaded from config file")
+	}
+	if opts.Run.Duplicates != NewDefaultRunOptions().Duplicates {
+		t.Errorf("unexpected duplicates: %q", opts.Run.Duplicates)
+	}
+	expectedTags := map[string]string{"a": "config", "b": "flag", "c": "flag"}
+	if !reflect.DeepEqual(opts.Add.Income.Tags, expectedTags) {
+		t.Errorf("expected tags %v, got %v", expectedTags, opts.Add.Income.Tags)
+	}
+
+	// 未知字段
+	if err := os.WriteFile(path, []byte("run:\n  formt: json\n"), 0o644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	if _, err := opts.LoadConfigFile(path); err == nil {
+		t.Errorf("expected error for unknown field")
+	}
+}
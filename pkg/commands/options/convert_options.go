@@ -0,0 +1,54 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// NewDefaultConvertOptions 创建默认 convert 命令选项
+func NewDefaultConvertOptions() ConvertOptions {
+	return ConvertOptions{}
+}
+
+// ConvertOptions convert 命令选项
+type ConvertOptions struct {
+	// 输出路径，拆分时为输出目录
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// 输出格式，未指定时根据输出文件扩展名决定
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// 将合并的 assets 或 income 文件拆分为各类型文件
+	Split bool `json:"split,omitempty" yaml:"split,omitempty"`
+	// 将各类型文件合并为一个 assets 或 income 文件
+	Combine bool `json:"combine,omitempty" yaml:"combine,omitempty"`
+}
+
+// Validate 校验选项是否合法
+func (o *ConvertOptions) Validate() error {
+	switch o.Format {
+	case "", "csv", "json", "yaml":
+	default:
+		return fmt.Errorf("unsupported output format: %q", o.Format)
+	}
+	if o.Split && o.Combine {
+		return fmt.Errorf("--split and --combine can not be used together")
+	}
+	if o.Combine && o.Format == "csv" {
+		return fmt.Errorf("combined file can not be written in \"csv\" format")
+	}
+	return nil
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *ConvertOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(
+		&o.Output, "output", "o", o.Output,
+		"Output path. Output directory when --split is specified (default to the current directory)",
+	)
+	flags.StringVarP(
+		&o.Format, "format", "f", o.Format,
+		`Output format ("csv", "yaml", "json"). Detected from the output file extension if not specified`,
+	)
+	flags.BoolVar(&o.Split, "split", o.Split, "Split a combined assets or income file into per-kind files")
+	flags.BoolVar(&o.Combine, "combine", o.Combine, "Combine per-kind files into one assets or income file")
+}
@@ -0,0 +1,86 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/yhlooo/dragon-acct/pkg/exports"
+)
+
+// NewDefaultExportOptions 创建默认 export 命令选项
+func NewDefaultExportOptions() ExportOptions {
+	return ExportOptions{
+		Output:    "",
+		Format:    string(exports.FormatBeancount),
+		Currency:  "CNY",
+		IncomeTax: NewDefaultIncomeTaxOptions(),
+		Insurance: NewDefaultInsuranceOptions(),
+	}
+}
+
+// ExportOptions export 命令选项
+type ExportOptions struct {
+	// 输出文件路径
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// 输出格式
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// 报告货币，商品单价和收入均以该货币计
+	Currency string `json:"currency,omitempty" yaml:"currency,omitempty"`
+	// 显示历史持仓（数量为 0 的商品），用于 xlsx 格式
+	ShowHistory bool `json:"showHistory,omitempty" yaml:"showHistory,omitempty"`
+	// 额外输出合并后的原始账本数据，用于 xlsx 格式
+	Raw bool `json:"raw,omitempty" yaml:"raw,omitempty"`
+	// 个人所得税计算选项，用于 xlsx 格式
+	IncomeTax IncomeTaxOptions `json:"incomeTax,omitempty" yaml:"incomeTax,omitempty"`
+	// 社会保险和住房公积金计算选项，用于 xlsx 格式
+	Insurance InsuranceOptions `json:"insurance,omitempty" yaml:"insurance,omitempty"`
+}
+
+// Validate 校验选项是否合法
+func (o *ExportOptions) Validate() error {
+	supported := false
+	for _, f := range exports.Formats() {
+		if o.Format == string(f) {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("unsupported export format: %q", o.Format)
+	}
+	if o.Raw && o.Format != string(exports.FormatXLSX) {
+		return fmt.Errorf("--raw is only supported by %q format", exports.FormatXLSX)
+	}
+	if o.Currency == "" {
+		return fmt.Errorf("currency must not be empty")
+	}
+	if err := o.IncomeTax.Validate(); err != nil {
+		return err
+	}
+	if err := o.Insurance.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *ExportOptions) AddPFlags(flags *pflag.FlagSet) {
+	formats := make([]string, 0, len(exports.Formats()))
+	for _, f := range exports.Formats() {
+		formats = append(formats, string(f))
+	}
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "Output path")
+	flags.StringVarP(
+		&o.Format, "format", "f", o.Format,
+		fmt.Sprintf("Export format (%s)", quoteJoin(formats)),
+	)
+	flags.StringVar(
+		&o.Currency, "currency", o.Currency,
+		"Reporting currency in which goods prices and income are denominated",
+	)
+	flags.BoolVar(&o.ShowHistory, "show-history", o.ShowHistory, `Show history goods (only for "xlsx" format)`)
+	flags.BoolVar(&o.Raw, "raw", o.Raw, `Also write the merged ledger as sheets (only for "xlsx" format)`)
+	o.IncomeTax.AddPFlags(flags)
+	o.Insurance.AddPFlags(flags)
+}
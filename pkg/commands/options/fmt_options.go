@@ -0,0 +1,30 @@
+package options
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// NewDefaultFmtOptions 创建默认 fmt 命令选项
+func NewDefaultFmtOptions() FmtOptions {
+	return FmtOptions{}
+}
+
+// FmtOptions fmt 命令选项
+type FmtOptions struct {
+	// 仅检查文件是否为规范形式，不写回
+	Check bool `json:"check,omitempty" yaml:"check,omitempty"`
+	// 重写含注释的 YAML 文件，注释会丢失
+	Force bool `json:"force,omitempty" yaml:"force,omitempty"`
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *FmtOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&o.Check, "check", o.Check,
+		"Do not write files, only list files that need formatting and exit with non-zero status if any",
+	)
+	flags.BoolVar(
+		&o.Force, "force", o.Force,
+		"Also rewrite YAML files with comments, the comments will be lost",
+	)
+}
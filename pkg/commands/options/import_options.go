@@ -2,15 +2,21 @@ package options
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/pflag"
+
+	"github.com/yhlooo/dragon-acct/pkg/imports"
 )
 
 // NewDefaultImportOptions 创建默认 import 命令选项
 func NewDefaultImportOptions() ImportOptions {
 	return ImportOptions{
-		Output: "",
-		Format: "csv",
+		Output:       "",
+		Format:       "csv",
+		SkipExisting: false,
+		Merge:        false,
+		List:         false,
 	}
 }
 
@@ -20,8 +26,20 @@ type ImportOptions struct {
 	Output string `json:"output,omitempty" yaml:"output,omitempty"`
 	// 输出格式
 	Format string `json:"format,omitempty" yaml:"format,omitempty"`
-	// 导入数据类型
+	// 导入数据类型，为空时根据数据内容自动识别
 	DataType string `json:"dataType,omitempty" yaml:"dataType,omitempty"`
+	// 托管机构，为空时使用导入器默认值
+	Custodian string `json:"custodian,omitempty" yaml:"custodian,omitempty"`
+	// 货币，用于数据中未注明货币的情况，为空时使用导入器默认值
+	Currency string `json:"currency,omitempty" yaml:"currency,omitempty"`
+	// 映射配置文件路径，用于 generic-csv 导入器
+	Mapping string `json:"mapping,omitempty" yaml:"mapping,omitempty"`
+	// 跳过账本中已存在的交易
+	SkipExisting bool `json:"skipExisting,omitempty" yaml:"skipExisting,omitempty"`
+	// 合并到工作目录的账本中
+	Merge bool `json:"merge,omitempty" yaml:"merge,omitempty"`
+	// 列出可用的导入器
+	List bool `json:"-" yaml:"-"`
 }
 
 // Validate 校验选项是否合法
@@ -31,17 +49,59 @@ func (o *ImportOptions) Validate() error {
 	default:
 		return fmt.Errorf("unsupported output format: %q", o.Format)
 	}
-	switch o.DataType {
-	case "futu":
-	default:
-		return fmt.Errorf("unsupported data type: %q", o.DataType)
+	if o.DataType != "" {
+		if _, ok := imports.Get(o.DataType); !ok {
+			return fmt.Errorf("unsupported data type: %q", o.DataType)
+		}
+	}
+	if o.DataType == "generic-csv" && o.Mapping == "" {
+		return fmt.Errorf("--mapping is required for data type \"generic-csv\"")
+	}
+	if o.Merge && o.Output != "" {
+		return fmt.Errorf("--merge and --output can not be used together")
 	}
 	return nil
 }
 
 // AddPFlags 将选项绑定到命令行参数
 func (o *ImportOptions) AddPFlags(flags *pflag.FlagSet) {
-	flags.StringVarP(&o.DataType, "type", "t", o.DataType, `Import data type ("futu")`)
+	flags.StringVarP(
+		&o.DataType, "type", "t", o.DataType,
+		fmt.Sprintf(
+			"Import data type (%s). Detected from the content if not specified",
+			quoteJoin(imports.Names()),
+		),
+	)
+	flags.StringVar(&o.Custodian, "custodian", o.Custodian, "Custodian of imported goods (default by importer)")
+	flags.StringVar(
+		&o.Currency, "currency", o.Currency,
+		"Currency used when the data does not specify one (default by importer)",
+	)
+	flags.StringVar(
+		&o.Mapping, "mapping", o.Mapping,
+		`Path of the YAML mapping file describing the CSV layout (required for "generic-csv")`,
+	)
 	flags.StringVarP(&o.Output, "output", "o", o.Output, "Output path")
-	flags.StringVarP(&o.Format, "format", "f", o.Format, `Output format ("csv", "yaml", "json")`)
+	flags.StringVarP(
+		&o.Format, "format", "f", o.Format,
+		`Output format ("csv", "yaml", "json"). Only transactions are output in "csv" format`,
+	)
+	flags.BoolVar(
+		&o.SkipExisting, "skip-existing", o.SkipExisting,
+		"Skip transactions already present in the ledger of the working directory",
+	)
+	flags.BoolVar(
+		&o.Merge, "merge", o.Merge,
+		"Merge imported transactions and new goods into the ledger of the working directory",
+	)
+	flags.BoolVar(&o.List, "list", o.List, "List available importers")
+}
+
+// quoteJoin 将字符串加引号后以逗号连接
+func quoteJoin(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+	return strings.Join(quoted, ", ")
 }
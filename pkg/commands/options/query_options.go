@@ -0,0 +1,42 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// NewDefaultQueryOptions 创建默认 query 命令选项
+func NewDefaultQueryOptions() QueryOptions {
+	return QueryOptions{
+		Output: "",
+		Format: "table",
+	}
+}
+
+// QueryOptions query 命令选项
+type QueryOptions struct {
+	// 输出文件路径
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// 输出格式
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+}
+
+// Validate 校验选项是否合法
+func (o *QueryOptions) Validate() error {
+	switch o.Format {
+	case "table", "csv", "json":
+	default:
+		return fmt.Errorf("unsupported output format: %q", o.Format)
+	}
+	return nil
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *QueryOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "Output path")
+	flags.StringVarP(
+		&o.Format, "format", "f", o.Format,
+		`Output format ("table", "csv" or "json"). Sums and counts are not included in "csv" format`,
+	)
+}
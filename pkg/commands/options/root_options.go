@@ -8,6 +8,14 @@ func NewDefaultOptions() Options {
 		Run:      NewDefaultRunOptions(),
 		Validate: NewDefaultValidateOptions(),
 		Import:   NewDefaultImportOptions(),
+		Export:   NewDefaultExportOptions(),
+		Fmt:      NewDefaultFmtOptions(),
+		Convert:  NewDefaultConvertOptions(),
+		Add:      NewDefaultAddOptions(),
+		Query:    NewDefaultQueryOptions(),
+		Serve:    NewDefaultServeOptions(),
+		TUI:      NewDefaultTUIOptions(),
+		Snapshot: NewDefaultSnapshotOptions(),
 	}
 }
 
@@ -23,4 +31,20 @@ type Options struct {
 	Validate ValidateOptions `json:"validate,omitempty" yaml:"validate"`
 	// import 命令选项
 	Import ImportOptions `json:"import,omitempty" yaml:"import,omitempty"`
+	// export 命令选项
+	Export ExportOptions `json:"export,omitempty" yaml:"export,omitempty"`
+	// fmt 命令选项
+	Fmt FmtOptions `json:"fmt,omitempty" yaml:"fmt,omitempty"`
+	// convert 命令选项
+	Convert ConvertOptions `json:"convert,omitempty" yaml:"convert,omitempty"`
+	// add 命令选项
+	Add AddOptions `json:"add,omitempty" yaml:"add,omitempty"`
+	// query 命令选项
+	Query QueryOptions `json:"query,omitempty" yaml:"query,omitempty"`
+	// serve 命令选项
+	Serve ServeOptions `json:"serve,omitempty" yaml:"serve,omitempty"`
+	// tui 命令选项
+	TUI TUIOptions `json:"tui,omitempty" yaml:"tui,omitempty"`
+	// snapshot 命令选项
+	Snapshot SnapshotOptions `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
 }
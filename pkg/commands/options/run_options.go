@@ -2,16 +2,29 @@ package options
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/spf13/pflag"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	analyzerincome "github.com/yhlooo/dragon-acct/pkg/analyzers/income"
+	"github.com/yhlooo/dragon-acct/pkg/collector"
 )
 
 // NewDefaultRunOptions 创建一个默认的 RunOptions
 func NewDefaultRunOptions() RunOptions {
 	return RunOptions{
-		ShowHistory: false,
-		Output:      "",
-		Format:      "text",
+		ShowHistory:   false,
+		Output:        "",
+		Format:        "text",
+		Duplicates:    string(collector.DuplicatesWarn),
+		Performance:   string(analyzersassets.PeriodYear),
+		Watch:         false,
+		WatchInterval: time.Second,
+		IncomeTax:     NewDefaultIncomeTaxOptions(),
+		Insurance:     NewDefaultInsuranceOptions(),
 	}
 }
 
@@ -25,15 +38,54 @@ type RunOptions struct {
 	Format string `json:"format,omitempty" yaml:"format,omitempty"`
 	// 输出不含颜色相关的 ANSI 控制字符
 	NoColor bool `json:"noColor,omitempty" yaml:"noColor,omitempty"`
+	// 重复交易处理策略
+	Duplicates string `json:"duplicates,omitempty" yaml:"duplicates,omitempty"`
+	// 定期生成检查点的周期（ month 、 quarter 或 year ），为空时仅使用账本中的检查点
+	PeriodicCheckpoints string `json:"periodicCheckpoints,omitempty" yaml:"periodicCheckpoints,omitempty"`
+	// 计算各自然周期表现的周期（ month 、 quarter 或 year ），为空时不计算
+	Performance string `json:"performance,omitempty" yaml:"performance,omitempty"`
+	// 账本文件变化时重新分析并输出报告
+	Watch bool `json:"watch,omitempty" yaml:"watch,omitempty"`
+	// 检查账本文件变化的间隔
+	WatchInterval time.Duration `json:"watchInterval,omitempty" yaml:"watchInterval,omitempty"`
+	// 个人所得税计算选项
+	IncomeTax IncomeTaxOptions `json:"incomeTax,omitempty" yaml:"incomeTax,omitempty"`
+	// 社会保险和住房公积金计算选项
+	Insurance InsuranceOptions `json:"insurance,omitempty" yaml:"insurance,omitempty"`
 }
 
 // Validate 校验选项是否合法
 func (o *RunOptions) Validate() error {
 	switch o.Format {
-	case "text":
+	case "text", "json", "yaml":
 	default:
 		return fmt.Errorf("unsupported output format: %q", o.Format)
 	}
+	switch collector.DuplicatesPolicy(o.Duplicates) {
+	case collector.DuplicatesWarn, collector.DuplicatesDrop, collector.DuplicatesAllow:
+	default:
+		return fmt.Errorf("unsupported duplicates policy: %q", o.Duplicates)
+	}
+	if err := validatePeriod("checkpoint", o.PeriodicCheckpoints); err != nil {
+		return err
+	}
+	if err := validatePeriod("performance", o.Performance); err != nil {
+		return err
+	}
+	if err := o.IncomeTax.Validate(); err != nil {
+		return err
+	}
+	if err := o.Insurance.Validate(); err != nil {
+		return err
+	}
+	if o.Watch {
+		if o.Format != "text" {
+			return fmt.Errorf("--watch only supports text format")
+		}
+		if o.WatchInterval <= 0 {
+			return fmt.Errorf("watch interval must be positive: %s", o.WatchInterval)
+		}
+	}
 	return nil
 }
 
@@ -43,7 +95,305 @@ func (o *RunOptions) AddPFlags(flags *pflag.FlagSet) {
 	flags.StringVarP(&o.Output, "output", "o", o.Output, "Output path of the report")
 	flags.StringVarP(
 		&o.Format, "format", "f", o.Format,
-		`Output format of the report ("text", "yaml", "json", "markdown" or "html")`,
+		`Output format of the report ("text", "yaml" or "json")`,
 	)
 	flags.BoolVar(&o.NoColor, "no-color", o.NoColor, "Disable color output")
+	flags.StringVar(
+		&o.Duplicates, "duplicates", o.Duplicates,
+		`How to handle duplicate transactions ("warn", "drop" or "allow")`,
+	)
+	flags.StringVar(
+		&o.PeriodicCheckpoints, "periodic-checkpoints", o.PeriodicCheckpoints,
+		`Generate checkpoints at the end of each period ("month", "quarter" or "year") in addition to the ledger's`,
+	)
+	flags.StringVar(
+		&o.Performance, "performance", o.Performance,
+		`Show performance of each calendar "month", "quarter" or "year". Empty to disable`,
+	)
+	flags.BoolVarP(&o.Watch, "watch", "w", o.Watch, "Re-render reports when ledger files change")
+	flags.DurationVar(&o.WatchInterval, "watch-interval", o.WatchInterval, "Interval to check ledger files for changes")
+	o.IncomeTax.AddPFlags(flags)
+	o.Insurance.AddPFlags(flags)
+}
+
+// NewDefaultIncomeTaxOptions 创建默认个人所得税计算选项
+func NewDefaultIncomeTaxOptions() IncomeTaxOptions {
+	return IncomeTaxOptions{
+		Enabled:           false,
+		SpecialDeductions: "0",
+		BonusTag:          "type=bonus",
+		BonusMethod:       string(analyzerincome.BonusSeparate),
+		EmployerTag:       "company",
+	}
+}
+
+// IncomeTaxOptions 个人所得税计算选项
+type IncomeTaxOptions struct {
+	// 按累计预扣法计算个人所得税并与记录的税额比对
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// 每月专项附加扣除
+	SpecialDeductions string `json:"specialDeductions,omitempty" yaml:"specialDeductions,omitempty"`
+	// 标识全年一次性奖金的标签，格式为 <key>=<value>
+	BonusTag string `json:"bonusTag,omitempty" yaml:"bonusTag,omitempty"`
+	// 全年一次性奖金计税方式（ separate 或 combined ）
+	BonusMethod string `json:"bonusMethod,omitempty" yaml:"bonusMethod,omitempty"`
+	// 标识扣缴义务人的标签名
+	EmployerTag string `json:"employerTag,omitempty" yaml:"employerTag,omitempty"`
+}
+
+// Validate 校验选项是否合法
+func (o *IncomeTaxOptions) Validate() error {
+	_, err := o.TaxOptions()
+	return err
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *IncomeTaxOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&o.Enabled, "income-tax", o.Enabled,
+		"Compute China individual income tax by the cumulative withholding method and check recorded tax",
+	)
+	flags.StringVar(
+		&o.SpecialDeductions, "income-tax-special-deductions", o.SpecialDeductions,
+		"Monthly special additional deductions for income tax",
+	)
+	flags.StringVar(
+		&o.BonusTag, "income-tax-bonus-tag", o.BonusTag,
+		"Tag marking annual bonus income items, in <key>=<value> format. Empty to treat all income as salary",
+	)
+	flags.StringVar(
+		&o.BonusMethod, "income-tax-bonus-method", o.BonusMethod,
+		`How annual bonus is taxed ("separate" or "combined" with salary)`,
+	)
+	flags.StringVar(
+		&o.EmployerTag, "income-tax-employer-tag", o.EmployerTag,
+		"Tag key identifying the employer withholding the tax. Empty to treat all income as from one employer",
+	)
+}
+
+// TaxOptions 返回收入分析的个人所得税计算选项，未启用时返回 nil
+func (o *IncomeTaxOptions) TaxOptions() (*analyzerincome.TaxOptions, error) {
+	ret := &analyzerincome.TaxOptions{
+		BonusMethod:    analyzerincome.BonusMethod(o.BonusMethod),
+		EmployerTagKey: o.EmployerTag,
+	}
+	switch ret.BonusMethod {
+	case analyzerincome.BonusSeparate, analyzerincome.BonusCombined:
+	default:
+		return nil, fmt.Errorf("unsupported income tax bonus method: %q", o.BonusMethod)
+	}
+	if o.BonusTag != "" {
+		var ok bool
+		ret.BonusTagKey, ret.BonusTagValue, ok = strings.Cut(o.BonusTag, "=")
+		if !ok || ret.BonusTagKey == "" {
+			return nil, fmt.Errorf("invalid income tax bonus tag %q, expected <key>=<value>", o.BonusTag)
+		}
+	}
+	var err error
+	if ret.SpecialDeductions, err = parseDecimalOption("income tax special deductions", o.SpecialDeductions); err != nil {
+		return nil, err
+	}
+	if !o.Enabled {
+		return nil, nil
+	}
+	return ret, nil
+}
+
+// IncomeAnalyseOptions 返回收入分析选项
+func IncomeAnalyseOptions(tax *IncomeTaxOptions, insurance *InsuranceOptions) (analyzerincome.Options, error) {
+	ret := analyzerincome.Options{}
+	var err error
+	if ret.Tax, err = tax.TaxOptions(); err != nil {
+		return ret, err
+	}
+	if ret.Insurance, err = insurance.InsuranceOptions(); err != nil {
+		return ret, err
+	}
+	return ret, nil
+}
+
+// validatePeriod 校验周期是否合法， name 为周期用途
+func validatePeriod(name, period string) error {
+	switch analyzersassets.Period(period) {
+	case analyzersassets.PeriodNone, analyzersassets.PeriodMonth, analyzersassets.PeriodQuarter, analyzersassets.PeriodYear:
+		return nil
+	}
+	return fmt.Errorf("unsupported %s period: %q (expected: \"month\", \"quarter\" or \"year\")", name, period)
+}
+
+// 缴费比例的键
+const (
+	rateKeyPension      = "pension"
+	rateKeyMedical      = "medical"
+	rateKeyUnemployment = "unemployment"
+	rateKeyHousingFund  = "housingFund"
+)
+
+// NewDefaultInsuranceOptions 创建默认社会保险和住房公积金计算选项
+//
+// 缴费基数上下限和比例默认为北京 2024 年度的标准
+func NewDefaultInsuranceOptions() InsuranceOptions {
+	return InsuranceOptions{
+		Enabled:            false,
+		SocialBaseMin:      "6821",
+		SocialBaseMax:      "35283",
+		HousingFundBaseMin: "2540",
+		HousingFundBaseMax: "35283",
+		EmployeeRates: map[string]string{
+			rateKeyPension:      "0.08",
+			rateKeyMedical:      "0.02",
+			rateKeyUnemployment: "0.005",
+			rateKeyHousingFund:  "0.12",
+		},
+		EmployerRates: map[string]string{
+			rateKeyPension:      "0.16",
+			rateKeyMedical:      "0.098",
+			rateKeyUnemployment: "0.005",
+			rateKeyHousingFund:  "0.12",
+		},
+		HousingFundOpeningBalance: "0",
+		HousingFundCustodian:      "住房公积金",
+	}
+}
+
+// InsuranceOptions 社会保险和住房公积金计算选项
+type InsuranceOptions struct {
+	// 按缴费基数和比例拆分保险和住房公积金
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// 固定的缴费基数，为空时按当月工资计算
+	Base string `json:"base,omitempty" yaml:"base,omitempty"`
+	// 社会保险缴费基数下限
+	SocialBaseMin string `json:"socialBaseMin,omitempty" yaml:"socialBaseMin,omitempty"`
+	// 社会保险缴费基数上限
+	SocialBaseMax string `json:"socialBaseMax,omitempty" yaml:"socialBaseMax,omitempty"`
+	// 住房公积金缴费基数下限
+	HousingFundBaseMin string `json:"housingFundBaseMin,omitempty" yaml:"housingFundBaseMin,omitempty"`
+	// 住房公积金缴费基数上限
+	HousingFundBaseMax string `json:"housingFundBaseMax,omitempty" yaml:"housingFundBaseMax,omitempty"`
+	// 个人缴费比例，键为 pension 、 medical 、 unemployment 或 housingFund
+	EmployeeRates map[string]string `json:"employeeRates,omitempty" yaml:"employeeRates,omitempty"`
+	// 单位缴费比例，键同 EmployeeRates
+	EmployerRates map[string]string `json:"employerRates,omitempty" yaml:"employerRates,omitempty"`
+	// 住房公积金期初余额
+	HousingFundOpeningBalance string `json:"housingFundOpeningBalance,omitempty" yaml:"housingFundOpeningBalance,omitempty"`
+	// 住房公积金账户的托管机构，不为空时住房公积金作为该托管机构持有的资产计入资产报告
+	HousingFundCustodian string `json:"housingFundCustodian,omitempty" yaml:"housingFundCustodian,omitempty"`
+}
+
+// Validate 校验选项是否合法
+func (o *InsuranceOptions) Validate() error {
+	_, err := o.InsuranceOptions()
+	return err
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *InsuranceOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&o.Enabled, "insurance", o.Enabled,
+		"Break down social insurance and housing fund by contribution base and rates",
+	)
+	flags.StringVar(
+		&o.Base, "insurance-base", o.Base,
+		"Fixed contribution base. Empty to use the monthly gross of income items with insurance and housing fund",
+	)
+	flags.StringVar(&o.SocialBaseMin, "insurance-social-base-min", o.SocialBaseMin, "Lower limit of social insurance contribution base")
+	flags.StringVar(&o.SocialBaseMax, "insurance-social-base-max", o.SocialBaseMax, "Upper limit of social insurance contribution base")
+	flags.StringVar(&o.HousingFundBaseMin, "insurance-hf-base-min", o.HousingFundBaseMin, "Lower limit of housing fund contribution base")
+	flags.StringVar(&o.HousingFundBaseMax, "insurance-hf-base-max", o.HousingFundBaseMax, "Upper limit of housing fund contribution base")
+	flags.StringToStringVar(
+		&o.EmployeeRates, "insurance-employee-rates", o.EmployeeRates,
+		"Employee contribution rates in KEY=VALUE format (keys: pension, medical, unemployment, housingFund)",
+	)
+	flags.StringToStringVar(
+		&o.EmployerRates, "insurance-employer-rates", o.EmployerRates,
+		"Employer contribution rates in KEY=VALUE format (keys: pension, medical, unemployment, housingFund)",
+	)
+	flags.StringVar(
+		&o.HousingFundOpeningBalance, "insurance-hf-opening-balance", o.HousingFundOpeningBalance,
+		"Housing fund balance before the first contribution",
+	)
+	flags.StringVar(
+		&o.HousingFundCustodian, "insurance-hf-custodian", o.HousingFundCustodian,
+		"Custodian holding the housing fund in the assets report. Empty to leave the housing fund out of assets",
+	)
+}
+
+// InsuranceOptions 返回收入分析的社会保险和住房公积金计算选项，未启用时返回 nil
+//
+// 未指定的缴费比例使用默认值
+func (o *InsuranceOptions) InsuranceOptions() (*analyzerincome.InsuranceOptions, error) {
+	ret := &analyzerincome.InsuranceOptions{HousingFundCustodian: o.HousingFundCustodian}
+	var err error
+	for _, item := range []struct {
+		name   string
+		value  string
+		target *decimal.Decimal
+	}{
+		{name: "insurance base", value: o.Base, target: &ret.Base},
+		{name: "social insurance base min", value: o.SocialBaseMin, target: &ret.SocialBaseMin},
+		{name: "social insurance base max", value: o.SocialBaseMax, target: &ret.SocialBaseMax},
+		{name: "housing fund base min", value: o.HousingFundBaseMin, target: &ret.HousingFundBaseMin},
+		{name: "housing fund base max", value: o.HousingFundBaseMax, target: &ret.HousingFundBaseMax},
+		{name: "housing fund opening balance", value: o.HousingFundOpeningBalance, target: &ret.HousingFundOpeningBalance},
+	} {
+		if *item.target, err = parseDecimalOption(item.name, item.value); err != nil {
+			return nil, err
+		}
+	}
+	defaults := NewDefaultInsuranceOptions()
+	if ret.EmployeeRates, err = parseContributionRates("employee", defaults.EmployeeRates, o.EmployeeRates); err != nil {
+		return nil, err
+	}
+	if ret.EmployerRates, err = parseContributionRates("employer", defaults.EmployerRates, o.EmployerRates); err != nil {
+		return nil, err
+	}
+	if !o.Enabled {
+		return nil, nil
+	}
+	return ret, nil
+}
+
+// parseContributionRates 解析缴费比例， rates 中未指定的使用 defaults 中的值
+func parseContributionRates(name string, defaults, rates map[string]string) (analyzerincome.ContributionRates, error) {
+	merged := map[string]string{}
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range rates {
+		merged[k] = v
+	}
+
+	var ret analyzerincome.ContributionRates
+	targets := map[string]*decimal.Decimal{
+		rateKeyPension:      &ret.Pension,
+		rateKeyMedical:      &ret.Medical,
+		rateKeyUnemployment: &ret.Unemployment,
+		rateKeyHousingFund:  &ret.HousingFund,
+	}
+	for k, v := range merged {
+		target, ok := targets[k]
+		if !ok {
+			return ret, fmt.Errorf(
+				"unknown %s contribution rate %q (expected: %q, %q, %q or %q)",
+				name, k, rateKeyPension, rateKeyMedical, rateKeyUnemployment, rateKeyHousingFund,
+			)
+		}
+		var err error
+		if *target, err = parseDecimalOption(fmt.Sprintf("%s %s rate", name, k), v); err != nil {
+			return ret, err
+		}
+	}
+	return ret, nil
+}
+
+// parseDecimalOption 解析数值选项，为空时返回零
+func parseDecimalOption(name, value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	ret, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	return ret, nil
 }
@@ -0,0 +1,95 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+)
+
+// NewDefaultServeOptions 创建默认 serve 命令选项
+func NewDefaultServeOptions() ServeOptions {
+	return ServeOptions{
+		Listen:        "127.0.0.1:8080",
+		WatchInterval: 2 * time.Second,
+		Duplicates:    string(collector.DuplicatesWarn),
+		Performance:   string(analyzersassets.PeriodYear),
+		IncomeTax:     NewDefaultIncomeTaxOptions(),
+		Insurance:     NewDefaultInsuranceOptions(),
+	}
+}
+
+// ServeOptions serve 命令选项
+type ServeOptions struct {
+	// 监听地址
+	Listen string `json:"listen,omitempty" yaml:"listen,omitempty"`
+	// 检查账本文件变化的间隔
+	WatchInterval time.Duration `json:"watchInterval,omitempty" yaml:"watchInterval,omitempty"`
+	// 资产报告中显示历史持仓
+	ShowHistory bool `json:"showHistory,omitempty" yaml:"showHistory,omitempty"`
+	// 重复交易处理策略
+	Duplicates string `json:"duplicates,omitempty" yaml:"duplicates,omitempty"`
+	// 定期生成检查点的周期（ month 、 quarter 或 year ），为空时仅使用账本中的检查点
+	PeriodicCheckpoints string `json:"periodicCheckpoints,omitempty" yaml:"periodicCheckpoints,omitempty"`
+	// 计算各自然周期表现的周期（ month 、 quarter 或 year ），为空时不计算
+	Performance string `json:"performance,omitempty" yaml:"performance,omitempty"`
+	// 个人所得税计算选项
+	IncomeTax IncomeTaxOptions `json:"incomeTax,omitempty" yaml:"incomeTax,omitempty"`
+	// 社会保险和住房公积金计算选项
+	Insurance InsuranceOptions `json:"insurance,omitempty" yaml:"insurance,omitempty"`
+}
+
+// Validate 校验选项是否合法
+func (o *ServeOptions) Validate() error {
+	if o.Listen == "" {
+		return fmt.Errorf("listen address must not be empty")
+	}
+	if o.WatchInterval < 0 {
+		return fmt.Errorf("watch interval must not be negative: %s", o.WatchInterval)
+	}
+	switch collector.DuplicatesPolicy(o.Duplicates) {
+	case collector.DuplicatesWarn, collector.DuplicatesDrop, collector.DuplicatesAllow:
+	default:
+		return fmt.Errorf("unsupported duplicates policy: %q", o.Duplicates)
+	}
+	if err := validatePeriod("checkpoint", o.PeriodicCheckpoints); err != nil {
+		return err
+	}
+	if err := validatePeriod("performance", o.Performance); err != nil {
+		return err
+	}
+	if err := o.IncomeTax.Validate(); err != nil {
+		return err
+	}
+	if err := o.Insurance.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *ServeOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.Listen, "listen", "l", o.Listen, "Address to listen on")
+	flags.DurationVar(
+		&o.WatchInterval, "watch-interval", o.WatchInterval,
+		"Interval to check ledger files for changes. 0 to disable reloading",
+	)
+	flags.BoolVar(&o.ShowHistory, "show-history", o.ShowHistory, "Show history goods in the assets report")
+	flags.StringVar(
+		&o.Duplicates, "duplicates", o.Duplicates,
+		`How to handle duplicate transactions ("warn", "drop" or "allow")`,
+	)
+	flags.StringVar(
+		&o.PeriodicCheckpoints, "periodic-checkpoints", o.PeriodicCheckpoints,
+		`Generate checkpoints at the end of each period ("month", "quarter" or "year") in addition to the ledger's`,
+	)
+	flags.StringVar(
+		&o.Performance, "performance", o.Performance,
+		`Include performance of each calendar "month", "quarter" or "year" in the assets report. Empty to disable`,
+	)
+	o.IncomeTax.AddPFlags(flags)
+	o.Insurance.AddPFlags(flags)
+}
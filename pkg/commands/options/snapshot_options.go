@@ -0,0 +1,32 @@
+package options
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// NewDefaultSnapshotOptions 创建默认 snapshot 命令选项
+func NewDefaultSnapshotOptions() SnapshotOptions {
+	return SnapshotOptions{
+		Date:   "",
+		DryRun: false,
+	}
+}
+
+// SnapshotOptions snapshot 命令选项
+type SnapshotOptions struct {
+	// 检查点日期，为空时为当天
+	Date string `json:"date,omitempty" yaml:"date,omitempty"`
+	// 仅输出检查点，不写入账本
+	DryRun bool `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+}
+
+// Validate 校验选项是否合法
+func (o *SnapshotOptions) Validate() error {
+	return nil
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *SnapshotOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Date, "date", o.Date, "Checkpoint date in YYYY-MM-DD format (default today)")
+	flags.BoolVar(&o.DryRun, "dry-run", o.DryRun, "Print the checkpoint instead of writing it to the ledger")
+}
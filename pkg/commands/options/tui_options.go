@@ -0,0 +1,67 @@
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+)
+
+// NewDefaultTUIOptions 创建默认 tui 命令选项
+func NewDefaultTUIOptions() TUIOptions {
+	return TUIOptions{
+		ShowHistory: false,
+		Duplicates:  string(collector.DuplicatesWarn),
+		IncomeTax:   NewDefaultIncomeTaxOptions(),
+		Insurance:   NewDefaultInsuranceOptions(),
+	}
+}
+
+// TUIOptions tui 命令选项
+type TUIOptions struct {
+	// 显示历史持仓
+	ShowHistory bool `json:"showHistory,omitempty" yaml:"showHistory,omitempty"`
+	// 重复交易处理策略
+	Duplicates string `json:"duplicates,omitempty" yaml:"duplicates,omitempty"`
+	// 定期生成检查点的周期（ month 、 quarter 或 year ），为空时仅使用账本中的检查点
+	PeriodicCheckpoints string `json:"periodicCheckpoints,omitempty" yaml:"periodicCheckpoints,omitempty"`
+	// 个人所得税计算选项
+	IncomeTax IncomeTaxOptions `json:"incomeTax,omitempty" yaml:"incomeTax,omitempty"`
+	// 社会保险和住房公积金计算选项
+	Insurance InsuranceOptions `json:"insurance,omitempty" yaml:"insurance,omitempty"`
+}
+
+// Validate 校验选项是否合法
+func (o *TUIOptions) Validate() error {
+	switch collector.DuplicatesPolicy(o.Duplicates) {
+	case collector.DuplicatesWarn, collector.DuplicatesDrop, collector.DuplicatesAllow:
+	default:
+		return fmt.Errorf("unsupported duplicates policy: %q", o.Duplicates)
+	}
+	if err := validatePeriod("checkpoint", o.PeriodicCheckpoints); err != nil {
+		return err
+	}
+	if err := o.IncomeTax.Validate(); err != nil {
+		return err
+	}
+	if err := o.Insurance.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddPFlags 将选项绑定到命令行参数
+func (o *TUIOptions) AddPFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.ShowHistory, "show-history", o.ShowHistory, "Show history goods initially")
+	flags.StringVar(
+		&o.Duplicates, "duplicates", o.Duplicates,
+		`How to handle duplicate transactions ("warn", "drop" or "allow")`,
+	)
+	flags.StringVar(
+		&o.PeriodicCheckpoints, "periodic-checkpoints", o.PeriodicCheckpoints,
+		`Generate checkpoints at the end of each period ("month", "quarter" or "year") in addition to the ledger's`,
+	)
+	o.IncomeTax.AddPFlags(flags)
+	o.Insurance.AddPFlags(flags)
+}
@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	"github.com/yhlooo/dragon-acct/pkg/commands/options"
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+	"github.com/yhlooo/dragon-acct/pkg/query"
+)
+
+// NewQueryCommandWithOptions 基于选项创建 query 命令
+func NewQueryCommandWithOptions(opts *options.QueryOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query tx|income [EXPR...]",
+		Short: "Query transactions or income details with a filter expression",
+		Long: fmt.Sprintf(`Query transactions or income details with a filter expression.
+
+The expression consists of comparisons in the form of FIELD OP VALUE combined with "and", "or",
+"not" and parentheses. Adjacent comparisons are combined with "and". OP can be "=", "!=", ">",
+">=", "<", "<=", "~" (contains) or "!~" (not contains). Quote values containing spaces. Dates
+are compared at the precision of the value, e.g. date=2024 matches any day in 2024.
+
+The "table" and "json" formats include counts and sums of the matched records. The "csv" format
+only contains the matched records in the ledger file layout, so it can be loaded as a ledger file.
+
+Fields of tx: %s
+Fields of income: %s`,
+			strings.Join(query.TransactionFields.Names(), ", "),
+			strings.Join(query.IncomeFields.Names(), ", "),
+		),
+		Example: `  dragon query tx 'custodian=汇丰银行 date=2024 reason=利息分红'
+  dragon query tx 'name=AAPL and (reason=买入 or reason=卖出)' -f csv
+  dragon query income 'date>=2024-01 date<2024-07 tag.company=A'`,
+		Args:      cobra.MinimumNArgs(1),
+		ValidArgs: []string{"tx", "income"},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			target := args[0]
+			fields := query.TransactionFields
+			switch target {
+			case "tx":
+			case "income":
+				fields = query.IncomeFields
+			default:
+				return fmt.Errorf("unsupported target: %q (expected: \"tx\" or \"income\")", target)
+			}
+			expr, err := query.Parse(strings.Join(args[1:], " "), fields)
+			if err != nil {
+				return fmt.Errorf("parse expression error: %w", err)
+			}
+
+			// 获取输入
+			pwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get current workdir error: %w", err)
+			}
+			data, err := collector.Collect(ctx, pwd, collector.Options{})
+			if err != nil {
+				return fmt.Errorf("collect error: %w", err)
+			}
+
+			// 输出
+			w := io.Writer(os.Stdout)
+			if opts.Output != "" {
+				f, err := os.OpenFile(opts.Output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+				if err != nil {
+					return fmt.Errorf("open %q error: %w", opts.Output, err)
+				}
+				defer func() { _ = f.Close() }()
+				w = f
+			}
+
+			if target == "tx" {
+				transactions := query.Filter(expr, data.Assets.Transactions, query.NewTransactionRecord)
+				return outputTransactions(w, opts.Format, transactions)
+			}
+			items := query.Filter(expr, data.Income.Details, query.NewIncomeRecord)
+			return outputIncomeItems(w, opts.Format, items)
+		},
+	}
+
+	// 绑定选项到命令行参数
+	opts.AddPFlags(cmd.Flags())
+
+	return cmd
+}
+
+// outputTransactions 输出查询到的交易
+//
+// csv 格式只包含交易记录，与账本文件格式相同，不包含数量和汇总
+func outputTransactions(w io.Writer, format string, transactions []v1.Transaction) error {
+	summaries := query.SummarizeTransactions(transactions)
+	switch format {
+	case "csv":
+		return collector.EncodeCSV(w, transactions)
+	case "json":
+		return collector.EncodeJSON(w, map[string]interface{}{
+			"count":        len(transactions),
+			"transactions": transactions,
+			"sums":         summaries,
+		})
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Date", "From", "From Custodian", "To", "To Custodian", "Reason", "Comment"})
+	for _, t := range transactions {
+		from, fromCustodian := queryGoodsColumns(t.From)
+		to, toCustodian := queryGoodsColumns(t.To)
+		table.Append([]string{t.Date.String(), from, fromCustodian, to, toCustodian, t.Reason, t.Comment})
+	}
+	_, _ = fmt.Fprintf(w, "Transactions (%d):\n", len(transactions))
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+
+	table = tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Custodian", "Name", "Count", "In", "Out", "Net"})
+	table.SetColumnAlignment([]int{
+		tablewriter.ALIGN_LEFT,
+		tablewriter.ALIGN_LEFT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+	})
+	for _, s := range summaries {
+		table.Append([]string{
+			s.Custodian,
+			s.Name,
+			strconv.Itoa(s.Count),
+			s.In.String(),
+			s.Out.String(),
+			s.Net.String(),
+		})
+	}
+	_, _ = fmt.Fprintln(w, "Sums:")
+	table.Render()
+	return nil
+}
+
+// queryGoodsColumns 返回交易中商品在表格中的列
+func queryGoodsColumns(g *v1.Goods) (string, string) {
+	if g == nil {
+		return "", ""
+	}
+	return g.Quantity.String() + " " + g.Name, g.Custodian
+}
+
+// outputIncomeItems 输出查询到的收入项
+//
+// csv 格式只包含收入项，与账本文件格式相同，不包含数量和汇总
+func outputIncomeItems(w io.Writer, format string, items []v1.IncomeItem) error {
+	summary := query.SummarizeIncome(items)
+	switch format {
+	case "csv":
+		return collector.EncodeCSV(w, items)
+	case "json":
+		return collector.EncodeJSON(w, map[string]interface{}{
+			"count":   summary.Count,
+			"details": items,
+			"sums":    summary,
+		})
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Date", "Gross", "Ins & HF", "Tax", "Net", "Tags", "Comment"})
+	table.SetColumnAlignment([]int{
+		tablewriter.ALIGN_LEFT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_LEFT,
+		tablewriter.ALIGN_LEFT,
+	})
+	for _, item := range items {
+		tags := query.NewIncomeRecord(item).Values("tag")
+		sort.Strings(tags)
+		table.Append([]string{
+			item.Date.String(),
+			item.Gross.StringFixedBank(2),
+			item.InsuranceAndHF.StringFixedBank(2),
+			item.Tax.StringFixedBank(2),
+			item.Gross.Sub(item.InsuranceAndHF).Sub(item.Tax).StringFixedBank(2),
+			strings.Join(tags, " "),
+			item.Comment,
+		})
+	}
+	table.SetFooter([]string{
+		fmt.Sprintf("Total (%d)", summary.Count),
+		summary.Gross.StringFixedBank(2),
+		summary.InsuranceAndHF.StringFixedBank(2),
+		summary.Tax.StringFixedBank(2),
+		summary.Net.StringFixedBank(2),
+		"",
+		"",
+	})
+	_, _ = fmt.Fprintln(w, "Income:")
+	table.Render()
+	return nil
+}
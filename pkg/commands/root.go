@@ -12,21 +12,49 @@ import (
 // NewDragonCommandWithOptions 创建一个基于选项的 dragon 命令
 func NewDragonCommandWithOptions(opts options.Options) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:          "dragon",
-		Short:        "A dragon interested in counting gold.",
+		Use:   "dragon",
+		Short: "A dragon interested in counting gold.",
+		Long: `A dragon interested in counting gold.
+
+Default options are loaded from the user config file ($XDG_CONFIG_HOME/dragon/config.yaml)
+and then from dragon.yaml in the ledger directory, both in the form of:
+
+  run:
+    format: json
+    showHistory: true
+
+Command-line flags take precedence over config files.`,
 		SilenceUsage: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			// 校验全局选项
-			if err := opts.Global.Validate(); err != nil {
-				return err
-			}
 			// 设置日志
 			logger := cmdutil.SetLogger(cmd, opts.Global.Verbosity)
+
+			// 加载用户配置文件
+			restoreFlags := cmdutil.SnapshotChangedFlags(cmd.Flags())
+			var configFiles []string
+			userConfig, err := options.UserConfigPath()
+			if err != nil {
+				logger.V(1).Info(fmt.Sprintf("skip user config file: %v", err))
+			} else if err := loadConfigFile(&opts, userConfig, restoreFlags, &configFiles); err != nil {
+				return err
+			}
 			// 设置工作目录
 			if err := cmdutil.ChangeWorkingDirectory(cmd, opts.Global.Chdir); err != nil {
 				return err
 			}
+			// 加载账本目录中的配置文件
+			if err := loadConfigFile(&opts, options.LedgerConfigFileName, restoreFlags, &configFiles); err != nil {
+				return err
+			}
+
+			// 校验全局选项
+			if err := opts.Global.Validate(); err != nil {
+				return err
+			}
+			// 配置文件可能修改了日志级别
+			logger = cmdutil.SetLogger(cmd, opts.Global.Verbosity)
 
+			logger.V(1).Info(fmt.Sprintf("config files: %q", configFiles))
 			logger.V(1).Info(fmt.Sprintf("command: %q, args: %#v, options: %#v", cmd.Name(), args, opts))
 			return nil
 		},
@@ -44,11 +72,31 @@ func NewDragonCommandWithOptions(opts options.Options) *cobra.Command {
 		NewValidateCommandWithOptions(&opts.Validate),
 		NewRunCommandWithOptions(&opts.Run),
 		NewImportCommandWithOptions(&opts.Import),
+		NewExportCommandWithOptions(&opts.Export),
+		NewFmtCommandWithOptions(&opts.Fmt),
+		NewConvertCommandWithOptions(&opts.Convert),
+		NewAddCommandWithOptions(&opts.Add),
+		NewQueryCommandWithOptions(&opts.Query),
+		NewServeCommandWithOptions(&opts.Serve),
+		NewTUICommandWithOptions(&opts.TUI),
+		NewSnapshotCommandWithOptions(&opts.Snapshot),
 	)
 
 	return cmd
 }
 
+// loadConfigFile 加载配置文件到 opts ，并重新应用命令行参数使其优先于配置文件
+//
+// 文件存在时将路径追加到 loaded
+func loadConfigFile(opts *options.Options, path string, restoreFlags func() error, loaded *[]string) error {
+	ok, err := opts.LoadConfigFile(path)
+	if err != nil || !ok {
+		return err
+	}
+	*loaded = append(*loaded, path)
+	return restoreFlags()
+}
+
 // NewDragonCommand 使用默认选项创建一个 dragon 命令
 func NewDragonCommand() *cobra.Command {
 	return NewDragonCommandWithOptions(options.NewDefaultOptions())
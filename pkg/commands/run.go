@@ -1,8 +1,12 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"time"
 
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
@@ -12,6 +16,7 @@ import (
 	"github.com/yhlooo/dragon-acct/pkg/collector"
 	"github.com/yhlooo/dragon-acct/pkg/commands/options"
 	"github.com/yhlooo/dragon-acct/pkg/report"
+	"github.com/yhlooo/dragon-acct/pkg/utils/watch"
 )
 
 // NewRunCommandWithOptions 创建一个基于选项的 run 命令
@@ -19,6 +24,10 @@ func NewRunCommandWithOptions(opts *options.RunOptions) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "run [income|assets]...",
 		Short: "Run analysis and output reports",
+		Long: `Run analysis and output reports.
+
+With --watch, reports are re-rendered whenever ledger files in the working directory change.
+Errors are printed without exiting until interrupted.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// 校验选项
 			if err := opts.Validate(); err != nil {
@@ -31,58 +40,20 @@ func NewRunCommandWithOptions(opts *options.RunOptions) *cobra.Command {
 			if len(targets) == 0 {
 				targets = []string{"income", "assets"}
 			}
+			for _, target := range targets {
+				if target != "income" && target != "assets" {
+					return fmt.Errorf("unsupported target: %q", target)
+				}
+			}
 
-			// 获取输入
 			pwd, err := os.Getwd()
 			if err != nil {
 				return fmt.Errorf("get current workdir error: %w", err)
 			}
-			data, err := collector.Collect(pwd)
-			if err != nil {
-				return fmt.Errorf("collect error: %w", err)
+			if !opts.Watch {
+				return runReports(ctx, pwd, targets, opts)
 			}
-
-			for _, target := range targets {
-				// 分析
-				var r report.Report
-				switch target {
-				case "income":
-					r, err = analyzerincome.Analyse(ctx, &data.Income)
-				case "assets":
-					r, err = analyzersassets.Analyse(ctx, &data.Assets, analyzersassets.Options{
-						ShowHistory: opts.ShowHistory,
-					})
-				default:
-					return fmt.Errorf("unsupported target: %q", target)
-				}
-				if err != nil {
-					return err
-				}
-
-				// 输出
-				switch opts.Format {
-				case "text":
-					w := os.Stdout
-					withColor := !opts.NoColor
-					if withColor {
-						withColor = isatty.IsTerminal(os.Stdout.Fd())
-					}
-					if opts.Output != "" {
-						w, err = os.OpenFile(opts.Output, os.O_WRONLY|os.O_CREATE, 0o644)
-						if err != nil {
-							return fmt.Errorf("open output file %q error: %w", opts.Output, err)
-						}
-						withColor = false
-					}
-					if err := r.Text(w, report.TextOptions{WithColor: withColor}); err != nil {
-						return err
-					}
-				default:
-					return fmt.Errorf("unsupported output format: %q", opts.Format)
-				}
-			}
-
-			return nil
+			return watchReports(ctx, pwd, targets, opts)
 		},
 	}
 
@@ -91,3 +62,100 @@ func NewRunCommandWithOptions(opts *options.RunOptions) *cobra.Command {
 
 	return cmd
 }
+
+// watchReports 输出报告，并在账本文件变化时重新分析和输出，阻塞直到 ctx 结束或收到中断信号
+//
+// 输出到终端时每次清屏后重新绘制，出错时输出错误并继续监视
+func watchReports(ctx context.Context, dir string, targets []string, opts *options.RunOptions) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	redraw := opts.Output == "" && isatty.IsTerminal(os.Stdout.Fd())
+	render := func() {
+		if redraw {
+			// 光标移到左上角并清屏
+			_, _ = fmt.Fprint(os.Stdout, "\033[H\033[2J")
+		}
+		if err := runReports(ctx, dir, targets, opts); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		_, _ = fmt.Fprintf(
+			os.Stderr, "\nUpdated at %s. Watching %s for changes, press Ctrl+C to exit.\n",
+			time.Now().Format(time.TimeOnly), dir,
+		)
+	}
+
+	render()
+	watch.Poll(ctx, dir, opts.WatchInterval, collector.IsSupportedFile, render)
+	return nil
+}
+
+// runReports 收集并分析账本，输出报告
+func runReports(ctx context.Context, dir string, targets []string, opts *options.RunOptions) error {
+	// 获取输入
+	data, err := collector.Collect(ctx, dir, collector.Options{
+		Duplicates: collector.DuplicatesPolicy(opts.Duplicates),
+	})
+	if err != nil {
+		return fmt.Errorf("collect error: %w", err)
+	}
+
+	// 输出
+	w := io.Writer(os.Stdout)
+	withColor := !opts.NoColor && isatty.IsTerminal(os.Stdout.Fd())
+	if opts.Output != "" {
+		f, err := os.OpenFile(opts.Output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			return fmt.Errorf("open output file %q error: %w", opts.Output, err)
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+		withColor = false
+	}
+
+	incomeOpts, err := options.IncomeAnalyseOptions(&opts.IncomeTax, &opts.Insurance)
+	if err != nil {
+		return err
+	}
+	structured := map[string]interface{}{}
+	for _, target := range targets {
+		// 分析
+		var r report.Report
+		switch target {
+		case "income":
+			r, err = analyzerincome.Analyse(ctx, &data.Income, incomeOpts)
+		case "assets":
+			assets := data.Assets
+			analyzerincome.AddHousingFund(&assets, &data.Income, incomeOpts.Insurance)
+			r, err = analyzersassets.Analyse(ctx, &assets, analyzersassets.Options{
+				ShowHistory: opts.ShowHistory,
+				Periodic:    analyzersassets.Period(opts.PeriodicCheckpoints),
+				Performance: analyzersassets.Period(opts.Performance),
+			})
+		default:
+			return fmt.Errorf("unsupported target: %q", target)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch opts.Format {
+		case "text":
+			if err := r.Text(w, report.TextOptions{WithColor: withColor}); err != nil {
+				return err
+			}
+		case "json", "yaml":
+			structured[target] = r.Data()
+		default:
+			return fmt.Errorf("unsupported output format: %q", opts.Format)
+		}
+	}
+
+	switch opts.Format {
+	case "json":
+		return collector.EncodeJSON(w, structured)
+	case "yaml":
+		return collector.EncodeYAML(w, structured)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	"github.com/yhlooo/dragon-acct/pkg/commands/options"
+	"github.com/yhlooo/dragon-acct/pkg/server"
+)
+
+// NewServeCommandWithOptions 基于选项创建 serve 命令
+func NewServeCommandWithOptions(opts *options.ServeOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the ledger and reports over a local HTTP API",
+		Long: `Serve the ledger and reports over a local HTTP API.
+
+A web dashboard is served at /ui/ . API endpoints (all GET, responding JSON):
+  /                      endpoints and ledger loading status
+  /assets/report         assets report (showHistory=true to include history goods)
+  /income/report         income report
+  /transactions?filter=  transactions matching the filter expression of "dragon query tx"
+  /income?filter=        income details matching the filter expression of "dragon query income"
+  /checkpoints           checkpoints and the summary at each checkpoint
+  /goods                 goods information
+
+The ledger is re-collected when files in the working directory change.`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get current workdir error: %w", err)
+			}
+			incomeOpts, err := options.IncomeAnalyseOptions(&opts.IncomeTax, &opts.Insurance)
+			if err != nil {
+				return err
+			}
+			return server.New(server.Options{
+				Dir:                 pwd,
+				Listen:              opts.Listen,
+				WatchInterval:       opts.WatchInterval,
+				ShowHistory:         opts.ShowHistory,
+				Duplicates:          collector.DuplicatesPolicy(opts.Duplicates),
+				PeriodicCheckpoints: analyzersassets.Period(opts.PeriodicCheckpoints),
+				Performance:         analyzersassets.Period(opts.Performance),
+				Income:              incomeOpts,
+			}).Run(cmd.Context())
+		},
+	}
+
+	// 绑定选项到命令行参数
+	opts.AddPFlags(cmd.Flags())
+
+	return cmd
+}
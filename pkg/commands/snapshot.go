@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	"github.com/yhlooo/dragon-acct/pkg/commands/options"
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// NewSnapshotCommandWithOptions 基于选项创建 snapshot 命令
+func NewSnapshotCommandWithOptions(opts *options.SnapshotOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Write a checkpoint for a date using current or recorded prices",
+		Long: `Write a checkpoint for a date using current or recorded prices.
+
+The checkpoint includes goods held on the date, and goods traded since the previous checkpoint
+whose prices are needed for the profit and loss of the period. The price of each goods is the
+latest one on or before the date in assets_prices files, or the price in goods information.`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := logr.FromContextOrDiscard(cmd.Context())
+
+			date, err := parseDateOrToday(opts.Date)
+			if err != nil {
+				return err
+			}
+			pwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get current workdir error: %w", err)
+			}
+			data, err := collector.Collect(cmd.Context(), pwd, collector.Options{Duplicates: collector.DuplicatesWarn})
+			if err != nil {
+				return fmt.Errorf("collect error: %w", err)
+			}
+			for _, cp := range data.Assets.Checkpoints {
+				if cp.Date.String() == date.String() {
+					return fmt.Errorf("checkpoint at %s already exists", date)
+				}
+			}
+
+			cp, err := analyzersassets.NewCheckpoint(&data.Assets, date)
+			if err != nil {
+				return fmt.Errorf("generate checkpoint error: %w", err)
+			}
+			if len(cp.Goods) == 0 {
+				return fmt.Errorf("no goods held at %s", date)
+			}
+
+			if opts.DryRun {
+				return collector.EncodeYAML(cmd.OutOrStdout(), []v1.Checkpoint{cp})
+			}
+			path, err := collector.AppendToLedger(pwd, []v1.Checkpoint{cp})
+			if err != nil {
+				return err
+			}
+			logger.Info(fmt.Sprintf("checkpoint at %s with %d goods written to %q", date, len(cp.Goods), path))
+			return nil
+		},
+	}
+
+	// 绑定选项到命令行参数
+	opts.AddPFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	"github.com/yhlooo/dragon-acct/pkg/commands/options"
+	"github.com/yhlooo/dragon-acct/pkg/tui"
+)
+
+// NewTUICommandWithOptions 基于选项创建 tui 命令
+func NewTUICommandWithOptions(opts *options.TUIOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Explore the portfolio in a terminal UI",
+		Long: `Explore the portfolio in a full-screen terminal UI.
+
+Keys:
+  Tab, Shift+Tab, 1-6  switch between goods, holdings, risks, custodians, checkpoints and income
+  s                    sort by the selected column (press again to reverse)
+  /                    filter rows containing the text (Enter to apply, Esc to clear)
+  h                    toggle showing history goods
+  Enter                show transactions of the selected goods
+  Esc                  back from transactions, or clear the filter
+  r                    reload the ledger
+  q                    quit`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get current workdir error: %w", err)
+			}
+			incomeOpts, err := options.IncomeAnalyseOptions(&opts.IncomeTax, &opts.Insurance)
+			if err != nil {
+				return err
+			}
+			return tui.New(tui.Options{
+				Dir:                 pwd,
+				ShowHistory:         opts.ShowHistory,
+				Duplicates:          collector.DuplicatesPolicy(opts.Duplicates),
+				PeriodicCheckpoints: analyzersassets.Period(opts.PeriodicCheckpoints),
+				Income:              incomeOpts,
+			}).Run(cmd.Context())
+		},
+	}
+
+	// 绑定选项到命令行参数
+	opts.AddPFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,95 @@
+package exports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WriteBeancount 以 Beancount 格式输出日记账
+//
+// 商品名、代号等无法直接表示的信息记录在元数据中
+func WriteBeancount(w io.Writer, j *Journal) error {
+	bw := bufio.NewWriter(w)
+
+	_, _ = fmt.Fprintf(bw, "option \"operating_currency\" %s\n\n", beancountString(j.Currency))
+
+	// 商品
+	for _, c := range j.Commodities {
+		_, _ = fmt.Fprintf(bw, "%s commodity %s\n", beancountDate(j.StartDate), c.Symbol)
+		_, _ = fmt.Fprintf(bw, "  name: %s\n", beancountString(c.Name))
+		if c.Code != "" {
+			_, _ = fmt.Fprintf(bw, "  code: %s\n", beancountString(c.Code))
+		}
+		if c.Base {
+			_, _ = fmt.Fprintln(bw, "  base: TRUE")
+		}
+	}
+	_, _ = fmt.Fprintln(bw)
+
+	// 账户
+	for _, a := range j.Accounts {
+		_, _ = fmt.Fprintf(bw, "%s open %s\n", beancountDate(a.OpenDate), a.Name)
+		if a.Custodian != "" {
+			_, _ = fmt.Fprintf(bw, "  custodian: %s\n", beancountString(a.Custodian))
+		}
+	}
+	_, _ = fmt.Fprintln(bw)
+
+	// 交易
+	for _, t := range j.Transactions {
+		_, _ = fmt.Fprintf(bw, "%s * %s", beancountDate(t.Date), beancountString(t.Narration))
+		for _, tag := range t.Tags {
+			_, _ = fmt.Fprintf(bw, " #%s", tag)
+		}
+		_, _ = fmt.Fprintln(bw)
+		for _, k := range sortedKeys(t.Meta) {
+			_, _ = fmt.Fprintf(bw, "  %s: %s\n", k, beancountString(t.Meta[k]))
+		}
+		for _, p := range t.Postings {
+			_, _ = fmt.Fprintf(bw, "  %s  %s %s", p.Account, p.Amount.Quantity, p.Amount.Commodity)
+			if p.TotalPrice != nil {
+				_, _ = fmt.Fprintf(bw, " @@ %s %s", p.TotalPrice.Quantity, p.TotalPrice.Commodity)
+			}
+			_, _ = fmt.Fprintln(bw)
+		}
+		_, _ = fmt.Fprintln(bw)
+	}
+
+	// 价格
+	for _, p := range j.Prices {
+		_, _ = fmt.Fprintf(
+			bw, "%s price %s %s %s\n",
+			beancountDate(p.Date), p.Commodity, p.Amount.Quantity, p.Amount.Commodity,
+		)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write beancount error: %w", err)
+	}
+	return nil
+}
+
+// beancountDate 返回 Beancount 日期
+func beancountDate(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// beancountString 返回 Beancount 字符串
+func beancountString(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", " ").Replace(s)
+	return `"` + s + `"`
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+package exports
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestWriteBeancount 测试 WriteBeancount 方法
+func TestWriteBeancount(t *testing.T) {
+	date := func(s string) v1.Date {
+		d, _ := time.Parse(time.DateOnly, s)
+		return v1.Date{Time: d}
+	}
+	data := &v1.Root{
+		Assets: v1.Assets{
+			Goods: []v1.GoodsInfo{
+				{Name: "CNY", Price: decimal.NewFromInt(1), Base: true},
+				{Name: "博时现金宝货币B", Code: "000000", Price: decimal.NewFromInt(1)},
+			},
+			Transactions: []v1.Transaction{
+				{
+					Date:   date("2024-01-02"),
+					To:     &v1.Goods{Quantity: decimal.NewFromInt(10000), Name: "CNY", Custodian: "汇丰银行"},
+					Reason: "工资",
+				},
+				{
+					Date:   date("2024-01-03"),
+					From:   &v1.Goods{Quantity: decimal.NewFromInt(5000), Name: "CNY", Custodian: "汇丰银行"},
+					To:     &v1.Goods{Quantity: decimal.NewFromInt(5000), Name: "博时现金宝货币B", Custodian: "汇丰银行"},
+					Reason: "买入",
+				},
+				{
+					Date:   date("2024-03-29"),
+					From:   &v1.Goods{Name: "博时现金宝货币B", Custodian: "汇丰银行"},
+					To:     &v1.Goods{Quantity: decimal.RequireFromString("44.73"), Name: "CNY", Custodian: "汇丰银行"},
+					Reason: "利息分红",
+				},
+			},
+		},
+		Income: v1.Income{
+			Details: []v1.IncomeItem{{
+				Date:           date("2024-01-10"),
+				Gross:          decimal.NewFromInt(30000),
+				InsuranceAndHF: decimal.NewFromInt(4500),
+				Tax:            decimal.NewFromInt(1200),
+			}},
+		},
+	}
+
+	j, err := NewJournal(data, Options{Currency: "CNY", Date: date("2024-04-01").Time})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteBeancount(buf, j); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		"2024-01-02 commodity C000000\n  name: \"博时现金宝货币B\"\n  code: \"000000\"\n",
+		"2024-01-02 open Assets:汇丰银行\n  custodian: \"汇丰银行\"\n",
+		"2024-01-02 * \"工资\"\n  Assets:汇丰银行  10000 CNY\n  Income:工资  -10000 CNY\n",
+		"2024-01-03 * \"买入\"\n  Assets:汇丰银行  5000 C000000 @@ 5000 CNY\n  Assets:汇丰银行  -5000 CNY\n",
+		"2024-01-10 * \"Salary\" #income\n  Income:Salary:Gross  -30000 CNY\n" +
+			"  Expenses:Salary:Insurance-And-HF  4500 CNY\n  Expenses:Salary:Tax  1200 CNY\n" +
+			"  Assets:Receivable:Salary  24300 CNY\n",
+		"2024-03-29 * \"利息分红\"\n  from: \"博时现金宝货币B\"\n  Assets:汇丰银行  44.73 CNY\n  Income:利息分红  -44.73 CNY\n",
+		"2024-04-01 price C000000 1 CNY\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain:\n%s\ngot:\n%s", expected, out)
+		}
+	}
+}
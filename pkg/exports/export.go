@@ -0,0 +1,46 @@
+package exports
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// Format 导出格式
+type Format string
+
+// Format 的可选值
+const (
+	FormatBeancount Format = "beancount"
+	FormatHledger   Format = "hledger"
+	FormatLedger    Format = "ledger"
+	FormatXLSX      Format = "xlsx"
+)
+
+// Formats 返回支持的导出格式
+func Formats() []Format {
+	return []Format{FormatBeancount, FormatHledger, FormatLedger, FormatXLSX}
+}
+
+// Export 将账本数据以指定格式导出
+func Export(ctx context.Context, w io.Writer, format Format, data *v1.Root, opts Options) error {
+	switch format {
+	case FormatBeancount:
+		j, err := NewJournal(data, opts)
+		if err != nil {
+			return err
+		}
+		return WriteBeancount(w, j)
+	case FormatHledger, FormatLedger:
+		j, err := NewJournal(data, opts)
+		if err != nil {
+			return err
+		}
+		return WriteLedger(w, j)
+	case FormatXLSX:
+		return WriteXLSX(ctx, w, data, opts)
+	}
+	return fmt.Errorf("unsupported export format: %q", format)
+}
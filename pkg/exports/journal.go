@@ -0,0 +1,506 @@
+package exports
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+
+	analyzersincome "github.com/yhlooo/dragon-acct/pkg/analyzers/income"
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// 账户名
+const (
+	// AccountAssets 资产账户前缀，后接托管机构
+	AccountAssets = "Assets"
+	// AccountIncome 收入账户前缀，后接交易原因
+	AccountIncome = "Income"
+	// AccountExpenses 支出账户前缀，后接交易原因
+	AccountExpenses = "Expenses"
+	// AccountSalaryGross 税前工资
+	AccountSalaryGross = "Income:Salary:Gross"
+	// AccountSalaryInsuranceAndHF 工资中扣除的保险和住房公积金
+	AccountSalaryInsuranceAndHF = "Expenses:Salary:Insurance-And-HF"
+	// AccountSalaryTax 工资中扣除的个人所得税
+	AccountSalaryTax = "Expenses:Salary:Tax"
+	// AccountSalaryReceivable 税后工资
+	//
+	// 税后工资到账由资产交易记录，这里记入应收账户以免重复计算
+	AccountSalaryReceivable = "Assets:Receivable:Salary"
+
+	// defaultAccountComponent 托管机构或原因为空时使用的账户名
+	defaultAccountComponent = "Other"
+)
+
+// TagIncome 收入明细生成的交易的标签
+const TagIncome = "income"
+
+// Options 导出选项
+type Options struct {
+	// 报告货币，商品单价和收入均以该货币计
+	Currency string
+	// 商品当前单价的日期，为空时使用当天
+	Date time.Time
+	// 显示历史持仓（数量为 0 的商品），用于 XLSX 格式
+	ShowHistory bool
+	// 额外输出合并后的原始账本数据，用于 XLSX 格式
+	Raw bool
+	// 收入分析选项，用于 XLSX 格式
+	Income analyzersincome.Options
+}
+
+// Journal 复式记账日记账
+//
+// 是 Beancount 、 hledger 等纯文本记账格式的公共模型
+type Journal struct {
+	// 报告货币
+	Currency string
+	// 开始日期（最早的交易日期）
+	StartDate time.Time
+	// 商品
+	Commodities []Commodity
+	// 账户
+	Accounts []Account
+	// 价格
+	Prices []Price
+	// 交易
+	Transactions []JournalTransaction
+}
+
+// Commodity 商品
+type Commodity struct {
+	// 符号
+	Symbol string
+	// 商品名
+	Name string
+	// 代号
+	Code string
+	// 是否基础商品（货币）
+	Base bool
+}
+
+// Account 账户
+type Account struct {
+	// 账户名
+	Name string
+	// 托管机构
+	Custodian string
+	// 开户日期
+	OpenDate time.Time
+}
+
+// Price 价格
+type Price struct {
+	// 日期
+	Date time.Time
+	// 商品符号
+	Commodity string
+	// 单价
+	Amount Amount
+}
+
+// Amount 金额
+type Amount struct {
+	// 数量
+	Quantity decimal.Decimal
+	// 商品符号
+	Commodity string
+}
+
+// JournalTransaction 交易
+type JournalTransaction struct {
+	// 日期
+	Date time.Time
+	// 说明
+	Narration string
+	// 标签
+	Tags []string
+	// 元数据（按键排序输出）
+	Meta map[string]string
+	// 分录
+	Postings []Posting
+}
+
+// Posting 分录
+type Posting struct {
+	// 账户
+	Account string
+	// 金额
+	Amount Amount
+	// 总价，不为空时表示按该总价换算
+	TotalPrice *Amount
+}
+
+// NewJournal 将账本数据转换为日记账
+func NewJournal(data *v1.Root, opts Options) (*Journal, error) {
+	if opts.Currency == "" {
+		return nil, fmt.Errorf("currency is required")
+	}
+	if opts.Date.IsZero() {
+		now := time.Now()
+		opts.Date = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	b := &journalBuilder{
+		journal:  &Journal{Currency: opts.Currency},
+		symbols:  map[string]string{},
+		used:     map[string]string{},
+		accounts: map[string]*Account{},
+	}
+	b.addCommodities(data.Assets.Goods, opts.Currency)
+
+	for _, t := range data.Assets.Transactions {
+		b.addTransaction(t)
+	}
+	for _, item := range data.Income.Details {
+		b.addIncome(item, opts.Currency)
+	}
+	sort.SliceStable(b.journal.Transactions, func(i, j int) bool {
+		return b.journal.Transactions[i].Date.Before(b.journal.Transactions[j].Date)
+	})
+
+	b.addPrices(data.Assets, opts)
+	b.complete()
+	return b.journal, nil
+}
+
+// journalBuilder 日记账构建器
+type journalBuilder struct {
+	journal *Journal
+	// 商品名到符号
+	symbols map[string]string
+	// 符号到商品名
+	used map[string]string
+	// 账户名到账户
+	accounts map[string]*Account
+}
+
+// addCommodities 添加商品
+func (b *journalBuilder) addCommodities(goods []v1.GoodsInfo, currency string) {
+	b.commodity(v1.GoodsInfo{Name: currency, Base: true})
+	for _, g := range goods {
+		b.commodity(g)
+	}
+}
+
+// commodity 返回商品对应的符号，商品不存在时添加
+func (b *journalBuilder) commodity(g v1.GoodsInfo) string {
+	if symbol, ok := b.symbols[g.Name]; ok {
+		return symbol
+	}
+	symbol := ""
+	for _, candidate := range []string{g.Name, strings.ToUpper(g.Name), g.Code, "C" + g.Code} {
+		if !IsValidCommoditySymbol(candidate) {
+			continue
+		}
+		if _, ok := b.used[candidate]; ok {
+			continue
+		}
+		symbol = candidate
+		break
+	}
+	if symbol == "" {
+		h := sha256.Sum256([]byte(g.Name))
+		symbol = "G" + strings.ToUpper(hex.EncodeToString(h[:])[:8])
+	}
+	b.symbols[g.Name] = symbol
+	b.used[symbol] = g.Name
+	b.journal.Commodities = append(b.journal.Commodities, Commodity{
+		Symbol: symbol,
+		Name:   g.Name,
+		Code:   g.Code,
+		Base:   g.Base,
+	})
+	return symbol
+}
+
+// account 返回账户名，账户不存在时添加
+func (b *journalBuilder) account(prefix, component, custodian string, date time.Time) string {
+	name := prefix + ":" + AccountComponent(component)
+	if a, ok := b.accounts[name]; ok {
+		if date.Before(a.OpenDate) {
+			a.OpenDate = date
+		}
+		return name
+	}
+	b.accounts[name] = &Account{Name: name, Custodian: custodian, OpenDate: date}
+	return name
+}
+
+// addTransaction 添加资产交易
+func (b *journalBuilder) addTransaction(t v1.Transaction) {
+	date := t.Date.Time
+	jt := JournalTransaction{
+		Date:      date,
+		Narration: t.Reason,
+		Meta:      map[string]string{},
+	}
+	if t.Comment != "" {
+		if jt.Narration == "" {
+			jt.Narration = t.Comment
+		} else {
+			jt.Meta["comment"] = t.Comment
+		}
+	}
+	if t.ID != "" {
+		jt.Meta["id"] = t.ID
+	}
+
+	// 数量为 0 的一方（如分红的来源）不产生分录
+	from, to := t.From, t.To
+	if from != nil && from.Quantity.IsZero() {
+		jt.Meta["from"] = from.Name
+		from = nil
+	}
+	if to != nil && to.Quantity.IsZero() {
+		jt.Meta["to"] = to.Name
+		to = nil
+	}
+
+	var fromPosting, toPosting *Posting
+	if from != nil {
+		fromPosting = &Posting{
+			Account: b.account(AccountAssets, from.Custodian, from.Custodian, date),
+			Amount:  Amount{Quantity: from.Quantity.Neg(), Commodity: b.commodity(v1.GoodsInfo{Name: from.Name})},
+		}
+	}
+	if to != nil {
+		toPosting = &Posting{
+			Account: b.account(AccountAssets, to.Custodian, to.Custodian, date),
+			Amount:  Amount{Quantity: to.Quantity, Commodity: b.commodity(v1.GoodsInfo{Name: to.Name})},
+		}
+	}
+
+	switch {
+	case fromPosting != nil && toPosting != nil:
+		if fromPosting.Amount.Commodity != toPosting.Amount.Commodity {
+			// 非基础商品一方按另一方的总价换算
+			if b.isBase(toPosting.Amount.Commodity) && !b.isBase(fromPosting.Amount.Commodity) {
+				fromPosting.TotalPrice = &Amount{Quantity: toPosting.Amount.Quantity, Commodity: toPosting.Amount.Commodity}
+			} else {
+				toPosting.TotalPrice = &Amount{
+					Quantity:  fromPosting.Amount.Quantity.Neg(),
+					Commodity: fromPosting.Amount.Commodity,
+				}
+			}
+			jt.Postings = []Posting{*toPosting, *fromPosting}
+		} else {
+			// 同一商品在托管机构间转移，差额记为支出
+			jt.Postings = []Posting{*toPosting, *fromPosting}
+			if diff := fromPosting.Amount.Quantity.Add(toPosting.Amount.Quantity); !diff.IsZero() {
+				jt.Postings = append(jt.Postings, Posting{
+					Account: b.account(AccountExpenses, t.Reason, "", date),
+					Amount:  Amount{Quantity: diff.Neg(), Commodity: fromPosting.Amount.Commodity},
+				})
+			}
+		}
+	case toPosting != nil:
+		// 流入
+		jt.Postings = []Posting{*toPosting, {
+			Account: b.account(AccountIncome, t.Reason, "", date),
+			Amount:  Amount{Quantity: toPosting.Amount.Quantity.Neg(), Commodity: toPosting.Amount.Commodity},
+		}}
+	case fromPosting != nil:
+		// 流出
+		jt.Postings = []Posting{{
+			Account: b.account(AccountExpenses, t.Reason, "", date),
+			Amount:  Amount{Quantity: fromPosting.Amount.Quantity.Neg(), Commodity: fromPosting.Amount.Commodity},
+		}, *fromPosting}
+	default:
+		// 没有数量变化的交易（如待调整数量的拆股）不产生分录
+		return
+	}
+	b.journal.Transactions = append(b.journal.Transactions, jt)
+}
+
+// addIncome 添加收入明细
+//
+// 税前总额记入收入，保险和住房公积金、税记入支出，税后部分记入应收账户
+func (b *journalBuilder) addIncome(item v1.IncomeItem, currency string) {
+	date := item.Date.Time
+	symbol := b.commodity(v1.GoodsInfo{Name: currency, Base: true})
+	jt := JournalTransaction{
+		Date:      date,
+		Narration: item.Comment,
+		Tags:      []string{TagIncome},
+		Meta:      map[string]string{},
+	}
+	if jt.Narration == "" {
+		jt.Narration = "Salary"
+	}
+	for k, v := range item.Tags {
+		jt.Meta[MetaKey(k)] = v
+	}
+	if !item.ConsumptionProportion.IsZero() {
+		jt.Meta["consumption-proportion"] = item.ConsumptionProportion.String()
+	}
+
+	jt.Postings = append(jt.Postings, Posting{
+		Account: b.fixedAccount(AccountSalaryGross, date),
+		Amount:  Amount{Quantity: item.Gross.Neg(), Commodity: symbol},
+	})
+	if !item.InsuranceAndHF.IsZero() {
+		jt.Postings = append(jt.Postings, Posting{
+			Account: b.fixedAccount(AccountSalaryInsuranceAndHF, date),
+			Amount:  Amount{Quantity: item.InsuranceAndHF, Commodity: symbol},
+		})
+	}
+	if !item.Tax.IsZero() {
+		jt.Postings = append(jt.Postings, Posting{
+			Account: b.fixedAccount(AccountSalaryTax, date),
+			Amount:  Amount{Quantity: item.Tax, Commodity: symbol},
+		})
+	}
+	jt.Postings = append(jt.Postings, Posting{
+		Account: b.fixedAccount(AccountSalaryReceivable, date),
+		Amount:  Amount{Quantity: item.Gross.Sub(item.InsuranceAndHF).Sub(item.Tax), Commodity: symbol},
+	})
+	b.journal.Transactions = append(b.journal.Transactions, jt)
+}
+
+// fixedAccount 返回固定名称的账户名，账户不存在时添加
+func (b *journalBuilder) fixedAccount(name string, date time.Time) string {
+	if a, ok := b.accounts[name]; ok {
+		if date.Before(a.OpenDate) {
+			a.OpenDate = date
+		}
+		return name
+	}
+	b.accounts[name] = &Account{Name: name, OpenDate: date}
+	return name
+}
+
+// isBase 判断商品是否基础商品
+func (b *journalBuilder) isBase(symbol string) bool {
+	for _, c := range b.journal.Commodities {
+		if c.Symbol == symbol {
+			return c.Base
+		}
+	}
+	return false
+}
+
+// addPrices 添加价格
+//
+// 检查点中的单价作为当天的价格，商品信息中的单价作为导出当天的价格
+func (b *journalBuilder) addPrices(assets v1.Assets, opts Options) {
+	currency := b.commodity(v1.GoodsInfo{Name: opts.Currency, Base: true})
+	add := func(date time.Time, name string, price decimal.Decimal) {
+		symbol, ok := b.symbols[name]
+		if !ok || symbol == currency || price.IsZero() {
+			return
+		}
+		b.journal.Prices = append(b.journal.Prices, Price{
+			Date:      date,
+			Commodity: symbol,
+			Amount:    Amount{Quantity: price, Commodity: currency},
+		})
+	}
+	for _, cp := range assets.Checkpoints {
+		for _, g := range cp.Goods {
+			add(cp.Date.Time, g.Name, g.Price)
+		}
+	}
+	for _, g := range assets.Goods {
+		add(opts.Date, g.Name, g.Price)
+	}
+	sort.SliceStable(b.journal.Prices, func(i, j int) bool {
+		return b.journal.Prices[i].Date.Before(b.journal.Prices[j].Date)
+	})
+}
+
+// complete 补全开始日期和账户列表
+func (b *journalBuilder) complete() {
+	j := b.journal
+	for _, t := range j.Transactions {
+		if j.StartDate.IsZero() || t.Date.Before(j.StartDate) {
+			j.StartDate = t.Date
+		}
+	}
+	for _, p := range j.Prices {
+		if j.StartDate.IsZero() || p.Date.Before(j.StartDate) {
+			j.StartDate = p.Date
+		}
+	}
+	for _, a := range b.accounts {
+		j.Accounts = append(j.Accounts, *a)
+	}
+	sort.Slice(j.Accounts, func(i, k int) bool {
+		return j.Accounts[i].Name < j.Accounts[k].Name
+	})
+}
+
+// IsValidCommoditySymbol 判断是否合法的商品符号
+//
+// 合法的符号以大写字母开头，以大写字母或数字结尾，中间可包含大写字母、数字和 ' . _ - ，长度不超过 24
+func IsValidCommoditySymbol(s string) bool {
+	if len(s) == 0 || len(s) > 24 {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				return false
+			}
+		case c == '\'' || c == '.' || c == '_' || c == '-':
+			if i == 0 || i == len(s)-1 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// AccountComponent 将名字转换为合法的账户名组成部分
+//
+// 空白和标点替换为 - ，首字母大写，为空时使用 Other
+func AccountComponent(s string) string {
+	var ret []rune
+	for _, c := range strings.TrimSpace(s) {
+		switch {
+		case c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)):
+			ret = append(ret, c)
+		case c >= unicode.MaxASCII && !unicode.IsSpace(c) && !unicode.IsPunct(c):
+			ret = append(ret, c)
+		case len(ret) > 0 && ret[len(ret)-1] != '-':
+			ret = append(ret, '-')
+		}
+	}
+	for len(ret) > 0 && ret[len(ret)-1] == '-' {
+		ret = ret[:len(ret)-1]
+	}
+	if len(ret) == 0 {
+		return defaultAccountComponent
+	}
+	ret[0] = unicode.ToUpper(ret[0])
+	return string(ret)
+}
+
+// MetaKey 将名字转换为合法的元数据键
+//
+// 元数据键以小写字母开头，只包含字母、数字、 - 和 _
+func MetaKey(s string) string {
+	var ret []rune
+	for _, c := range s {
+		switch {
+		case c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-' || c == '_'):
+			ret = append(ret, c)
+		default:
+			ret = append(ret, '-')
+		}
+	}
+	if len(ret) == 0 || !unicode.IsLower(ret[0]) {
+		ret = append([]rune{'x', '-'}, ret...)
+	}
+	return string(ret)
+}
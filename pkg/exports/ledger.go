@@ -0,0 +1,108 @@
+package exports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WriteLedger 以 hledger （兼容 Ledger ）日记账格式输出日记账
+//
+// 商品直接使用商品名（必要时加引号），元数据作为标签写在注释中
+func WriteLedger(w io.Writer, j *Journal) error {
+	bw := bufio.NewWriter(w)
+
+	names := make(map[string]string, len(j.Commodities))
+	for _, c := range j.Commodities {
+		names[c.Symbol] = ledgerCommodity(c.Name)
+	}
+	amount := func(a Amount) string {
+		return a.Quantity.String() + " " + names[a.Commodity]
+	}
+
+	// 商品
+	for _, c := range j.Commodities {
+		_, _ = fmt.Fprintf(bw, "commodity %s\n", names[c.Symbol])
+		if c.Code != "" {
+			_, _ = fmt.Fprintf(bw, "    ; code: %s\n", ledgerTagValue(c.Code))
+		}
+		if c.Base {
+			_, _ = fmt.Fprintln(bw, "    ; base:")
+		}
+	}
+	_, _ = fmt.Fprintln(bw)
+
+	// 账户
+	for _, a := range j.Accounts {
+		_, _ = fmt.Fprintf(bw, "account %s\n", a.Name)
+		if a.Custodian != "" {
+			_, _ = fmt.Fprintf(bw, "    ; custodian: %s\n", ledgerTagValue(a.Custodian))
+		}
+	}
+	_, _ = fmt.Fprintln(bw)
+
+	// 价格
+	for _, p := range j.Prices {
+		_, _ = fmt.Fprintf(bw, "P %s %s %s\n", ledgerDate(p.Date), names[p.Commodity], amount(p.Amount))
+	}
+	_, _ = fmt.Fprintln(bw)
+
+	// 交易
+	for _, t := range j.Transactions {
+		_, _ = fmt.Fprintln(bw, strings.TrimSpace(ledgerDate(t.Date)+" "+ledgerDescription(t.Narration)))
+		for _, tag := range t.Tags {
+			_, _ = fmt.Fprintf(bw, "    ; %s:\n", tag)
+		}
+		for _, k := range sortedKeys(t.Meta) {
+			_, _ = fmt.Fprintf(bw, "    ; %s: %s\n", k, ledgerTagValue(t.Meta[k]))
+		}
+		for _, p := range t.Postings {
+			_, _ = fmt.Fprintf(bw, "    %s  %s", p.Account, amount(p.Amount))
+			if p.TotalPrice != nil {
+				_, _ = fmt.Fprintf(bw, " @@ %s", amount(*p.TotalPrice))
+			}
+			_, _ = fmt.Fprintln(bw)
+		}
+		_, _ = fmt.Fprintln(bw)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write journal error: %w", err)
+	}
+	return nil
+}
+
+// ledgerDate 返回日记账日期
+func ledgerDate(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// ledgerCommodity 返回日记账商品符号
+//
+// 只包含字母的符号原样输出，否则加双引号
+func ledgerCommodity(name string) string {
+	simple := name != ""
+	for _, c := range name {
+		if !unicode.IsLetter(c) {
+			simple = false
+			break
+		}
+	}
+	if simple {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `'`) + `"`
+}
+
+// ledgerDescription 返回交易描述，去掉会被解析为注释的字符
+func ledgerDescription(s string) string {
+	return strings.TrimSpace(strings.NewReplacer(";", ",", "\r", "", "\n", " ").Replace(s))
+}
+
+// ledgerTagValue 返回标签值，标签值以逗号或行尾结束
+func ledgerTagValue(s string) string {
+	return strings.TrimSpace(strings.NewReplacer(",", "，", "\r", "", "\n", " ").Replace(s))
+}
@@ -0,0 +1,62 @@
+package exports
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestWriteLedger 测试 WriteLedger 方法
+func TestWriteLedger(t *testing.T) {
+	date, _ := time.Parse(time.DateOnly, "2024-01-03")
+	j := &Journal{
+		Currency: "CNY",
+		Commodities: []Commodity{
+			{Symbol: "CNY", Name: "CNY", Base: true},
+			{Symbol: "GABCD", Name: "沪深300 ETF", Code: "510300"},
+		},
+		Accounts: []Account{{Name: "Assets:汇丰银行", Custodian: "汇丰银行", OpenDate: date}},
+		Prices: []Price{{
+			Date:      date,
+			Commodity: "GABCD",
+			Amount:    Amount{Quantity: decimal.RequireFromString("3.5"), Commodity: "CNY"},
+		}},
+		Transactions: []JournalTransaction{{
+			Date:      date,
+			Narration: "买入; 定投",
+			Meta:      map[string]string{"id": "A1,B2"},
+			Postings: []Posting{
+				{
+					Account:    "Assets:汇丰银行",
+					Amount:     Amount{Quantity: decimal.NewFromInt(100), Commodity: "GABCD"},
+					TotalPrice: &Amount{Quantity: decimal.NewFromInt(350), Commodity: "CNY"},
+				},
+				{
+					Account: "Assets:汇丰银行",
+					Amount:  Amount{Quantity: decimal.NewFromInt(-350), Commodity: "CNY"},
+				},
+			},
+		}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteLedger(buf, j); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		"commodity CNY\n    ; base:\n",
+		"commodity \"沪深300 ETF\"\n    ; code: 510300\n",
+		"account Assets:汇丰银行\n    ; custodian: 汇丰银行\n",
+		"P 2024-01-03 \"沪深300 ETF\" 3.5 CNY\n",
+		"2024-01-03 买入, 定投\n    ; id: A1，B2\n" +
+			"    Assets:汇丰银行  100 \"沪深300 ETF\" @@ 350 CNY\n    Assets:汇丰银行  -350 CNY\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain:\n%s\ngot:\n%s", expected, out)
+		}
+	}
+}
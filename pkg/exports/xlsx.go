@@ -0,0 +1,459 @@
+package exports
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	analyzersincome "github.com/yhlooo/dragon-acct/pkg/analyzers/income"
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// xlsxNumber 数值单元格
+type xlsxNumber decimal.Decimal
+
+// xlsxPercent 百分比单元格
+type xlsxPercent decimal.Decimal
+
+// xlsxWriter XLSX 工作簿构建器
+type xlsxWriter struct {
+	file *excelize.File
+	// 已创建的工作表数
+	sheets int
+
+	numberStyle  int
+	percentStyle int
+	dateStyle    int
+	headerStyle  int
+	footerStyle  int
+}
+
+// WriteXLSX 以 XLSX 格式输出资产报告和收入报告
+//
+// 资产报告的每个部分、收入明细和按标签聚合的收入各为一个工作表，金额为数值单元格，比例为百分比格式
+func WriteXLSX(ctx context.Context, w io.Writer, data *v1.Root, opts Options) error {
+	// 住房公积金只计入资产报告，不写入原始账本数据
+	assets := data.Assets
+	analyzersincome.AddHousingFund(&assets, &data.Income, opts.Income.Insurance)
+	assetsReport, err := analyzersassets.Analyse(ctx, &assets, analyzersassets.Options{
+		ShowHistory: opts.ShowHistory,
+	})
+	if err != nil {
+		return fmt.Errorf("analyse assets error: %w", err)
+	}
+	incomeReport, err := analyzersincome.Analyse(ctx, &data.Income, opts.Income)
+	if err != nil {
+		return fmt.Errorf("analyse income error: %w", err)
+	}
+
+	xw, err := newXLSXWriter()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = xw.file.Close() }()
+
+	if err := xw.writeAssets(assetsReport.(*analyzersassets.Report), opts.ShowHistory); err != nil {
+		return err
+	}
+	if err := xw.writeIncome(incomeReport.(*analyzersincome.Report)); err != nil {
+		return err
+	}
+	if opts.Raw {
+		if err := xw.writeRaw(data); err != nil {
+			return err
+		}
+	}
+
+	if _, err := xw.file.WriteTo(w); err != nil {
+		return fmt.Errorf("write xlsx error: %w", err)
+	}
+	return nil
+}
+
+// newXLSXWriter 创建 XLSX 工作簿构建器
+func newXLSXWriter() (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	xw := &xlsxWriter{file: f}
+
+	numberFormat := "#,##0.00"
+	percentFormat := "0.00%"
+	dateFormat := "yyyy-mm-dd"
+	var err error
+	if xw.numberStyle, err = f.NewStyle(&excelize.Style{CustomNumFmt: &numberFormat}); err != nil {
+		return nil, fmt.Errorf("create number style error: %w", err)
+	}
+	if xw.percentStyle, err = f.NewStyle(&excelize.Style{CustomNumFmt: &percentFormat}); err != nil {
+		return nil, fmt.Errorf("create percent style error: %w", err)
+	}
+	if xw.dateStyle, err = f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return nil, fmt.Errorf("create date style error: %w", err)
+	}
+	if xw.headerStyle, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#D9E1F2"}},
+	}); err != nil {
+		return nil, fmt.Errorf("create header style error: %w", err)
+	}
+	if xw.footerStyle, err = f.NewStyle(&excelize.Style{
+		Font:         &excelize.Font{Bold: true},
+		CustomNumFmt: &numberFormat,
+	}); err != nil {
+		return nil, fmt.Errorf("create footer style error: %w", err)
+	}
+	return xw, nil
+}
+
+// writeSheet 写入一个工作表
+//
+// 单元格可以是 string 、 xlsxNumber 、 xlsxPercent 、 time.Time 或 v1.Date
+func (xw *xlsxWriter) writeSheet(name string, header []string, rows [][]interface{}) error {
+	name = xlsxSheetName(name)
+	if xw.sheets == 0 {
+		// 重命名默认工作表
+		if err := xw.file.SetSheetName(xw.file.GetSheetName(0), name); err != nil {
+			return fmt.Errorf("rename sheet %q error: %w", name, err)
+		}
+	} else if _, err := xw.file.NewSheet(name); err != nil {
+		return fmt.Errorf("create sheet %q error: %w", name, err)
+	}
+	xw.sheets++
+
+	// 表头
+	for i, h := range header {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		if err := xw.file.SetCellValue(name, cell, h); err != nil {
+			return fmt.Errorf("set cell %s!%s error: %w", name, cell, err)
+		}
+	}
+	if len(header) > 0 {
+		end, _ := excelize.CoordinatesToCellName(len(header), 1)
+		if err := xw.file.SetCellStyle(name, "A1", end, xw.headerStyle); err != nil {
+			return fmt.Errorf("set header style of sheet %q error: %w", name, err)
+		}
+		if err := xw.file.SetPanes(name, &excelize.Panes{
+			Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft",
+		}); err != nil {
+			return fmt.Errorf("freeze header of sheet %q error: %w", name, err)
+		}
+	}
+
+	// 数据
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = xlsxTextWidth(h)
+	}
+	for r, row := range rows {
+		for c, v := range row {
+			cell, _ := excelize.CoordinatesToCellName(c+1, r+2)
+			if err := xw.setCell(name, cell, v); err != nil {
+				return err
+			}
+			if c < len(widths) {
+				if w := xlsxCellWidth(v); w > widths[c] {
+					widths[c] = w
+				}
+			}
+		}
+	}
+	for i, w := range widths {
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		if err := xw.file.SetColWidth(name, col, col, float64(w+2)); err != nil {
+			return fmt.Errorf("set column width of sheet %q error: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setCell 设置单元格的值和格式
+func (xw *xlsxWriter) setCell(sheet, cell string, v interface{}) error {
+	var value interface{}
+	style := 0
+	switch v := v.(type) {
+	case xlsxNumber:
+		value, style = decimal.Decimal(v).InexactFloat64(), xw.numberStyle
+	case xlsxPercent:
+		value, style = decimal.Decimal(v).InexactFloat64(), xw.percentStyle
+	case v1.Date:
+		value, style = v.Time, xw.dateStyle
+	case time.Time:
+		value, style = v, xw.dateStyle
+	default:
+		value = v
+	}
+	if err := xw.file.SetCellValue(sheet, cell, value); err != nil {
+		return fmt.Errorf("set cell %s!%s error: %w", sheet, cell, err)
+	}
+	if style != 0 {
+		if err := xw.file.SetCellStyle(sheet, cell, cell, style); err != nil {
+			return fmt.Errorf("set style of cell %s!%s error: %w", sheet, cell, err)
+		}
+	}
+	return nil
+}
+
+// writeFooter 在工作表最后一行之后写入合计行
+func (xw *xlsxWriter) writeFooter(sheet string, row int, values []interface{}) error {
+	for c, v := range values {
+		if v == nil {
+			continue
+		}
+		cell, _ := excelize.CoordinatesToCellName(c+1, row)
+		if err := xw.setCell(sheet, cell, v); err != nil {
+			return err
+		}
+		if _, ok := v.(xlsxPercent); ok {
+			continue
+		}
+		if err := xw.file.SetCellStyle(sheet, cell, cell, xw.footerStyle); err != nil {
+			return fmt.Errorf("set style of cell %s!%s error: %w", sheet, cell, err)
+		}
+	}
+	return nil
+}
+
+// writeAssets 写入资产报告
+func (xw *xlsxWriter) writeAssets(r *analyzersassets.Report, showHistory bool) error {
+	// 所有商品
+	var rows [][]interface{}
+	for _, g := range r.AllGoods() {
+		if g.Quantity.IsZero() && !showHistory {
+			continue
+		}
+		rows = append(rows, []interface{}{
+			g.Name, g.Custodian, g.Code, string(g.Risk),
+			xlsxNumber(g.Price), xlsxNumber(g.Quantity), xlsxNumber(g.Value),
+			xlsxNumber(g.ProfitAndLoss), xlsxPercent(g.RateOfReturn), xlsxPercent(g.AnnualizedRateOfReturn),
+		})
+	}
+	if err := xw.writeSheet("All Goods", []string{
+		"Name", "Custodian", "Code", "Risk", "Price", "Quantity", "Value", "P/L", "RR", "XIRR",
+	}, rows); err != nil {
+		return err
+	}
+
+	// 持仓
+	rows = nil
+	total, totalRatio := decimal.Zero, decimal.Zero
+	for _, g := range r.HoldingGoods() {
+		rows = append(rows, []interface{}{g.Name, g.Custodian, xlsxNumber(g.Value), xlsxPercent(g.Ratio)})
+		if !g.Base || g.Value.IsPositive() {
+			total = total.Add(g.Value)
+			totalRatio = totalRatio.Add(g.Ratio)
+		}
+	}
+	if err := xw.writeSheet("Holding", []string{"Name", "Custodian", "Value", "Ratio"}, rows); err != nil {
+		return err
+	}
+	if err := xw.writeFooter("Holding", len(rows)+2, []interface{}{
+		nil, "Total", xlsxNumber(total), xlsxPercent(totalRatio),
+	}); err != nil {
+		return err
+	}
+
+	// 风险
+	rows = nil
+	for _, g := range r.Risks() {
+		rows = append(rows, []interface{}{string(g.Risk), xlsxNumber(g.Value), xlsxPercent(g.Ratio)})
+	}
+	if err := xw.writeSheet("Risks", []string{"Risk", "Value", "Ratio"}, rows); err != nil {
+		return err
+	}
+
+	// 托管机构
+	baseGoods := r.BaseGoods()
+	header := append(append([]string{"Custodian"}, baseGoods...), "Others", "Total", "Ratio")
+	rows = nil
+	for _, c := range r.Custodians() {
+		row := []interface{}{c.Custodian}
+		for _, name := range baseGoods {
+			row = append(row, xlsxNumber(c.BaseGoods[name]))
+		}
+		row = append(row, xlsxNumber(c.Others), xlsxNumber(c.Total), xlsxPercent(c.Ratio))
+		rows = append(rows, row)
+	}
+	if err := xw.writeSheet("Custodians", header, rows); err != nil {
+		return err
+	}
+
+	// 检查点
+	rows = nil
+	for _, cp := range r.Checkpoints() {
+		profitAndLoss, rateOfReturn, annualizedRateOfReturn := cp.Report.TotalProfitAndLoss()
+		rows = append(rows, []interface{}{
+			cp.Date, xlsxNumber(cp.Report.TotalValue()),
+			xlsxNumber(profitAndLoss), xlsxPercent(rateOfReturn), xlsxPercent(annualizedRateOfReturn),
+		})
+	}
+	if err := xw.writeSheet("Checkpoints", []string{"Date", "Total", "P/L", "RR", "XIRR"}, rows); err != nil {
+		return err
+	}
+
+	// 总体损益
+	profitAndLoss, rateOfReturn, annualizedRateOfReturn := r.TotalProfitAndLoss()
+	return xw.writeSheet("Total P&L", []string{"Total", "P/L", "RR", "XIRR"}, [][]interface{}{{
+		xlsxNumber(r.TotalValue()), xlsxNumber(profitAndLoss),
+		xlsxPercent(rateOfReturn), xlsxPercent(annualizedRateOfReturn),
+	}})
+}
+
+// writeIncome 写入收入报告
+func (xw *xlsxWriter) writeIncome(r *analyzersincome.Report) error {
+	var rows [][]interface{}
+	for _, item := range r.Details() {
+		rows = append(rows, []interface{}{
+			item.Date,
+			xlsxNumber(item.Gross), xlsxNumber(item.InsuranceAndHF), xlsxNumber(item.Tax), xlsxNumber(item.TakeHome),
+			xlsxPercent(item.ConsumptionProportion), xlsxNumber(item.Consumption),
+			xlsxTags(item.Tags), item.Comment,
+		})
+	}
+	if err := xw.writeSheet("Income", []string{
+		"Date", "Gross", "Insurance & HF", "Tax", "Take Home", "%Consumption", "Consumption", "Tags", "Comment",
+	}, rows); err != nil {
+		return err
+	}
+
+	groups := r.GroupByTags()
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rows = nil
+		for _, item := range groups[k] {
+			rows = append(rows, []interface{}{
+				item.TagValue,
+				xlsxNumber(item.Gross), xlsxNumber(item.InsuranceAndHF), xlsxNumber(item.Tax),
+				xlsxNumber(item.TakeHome), xlsxPercent(item.ConsumptionProportion), xlsxNumber(item.Consumption),
+			})
+		}
+		if err := xw.writeSheet("Income by "+k, []string{
+			k, "Gross", "Insurance & HF", "Tax", "Take Home", "%Consumption", "Consumption",
+		}, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRaw 写入原始账本数据
+func (xw *xlsxWriter) writeRaw(data *v1.Root) error {
+	var rows [][]interface{}
+	for _, g := range data.Assets.Goods {
+		rows = append(rows, []interface{}{
+			g.Name, g.Code, string(g.Risk), xlsxNumber(g.Price), xlsxBool(g.Base), xlsxBool(g.IgnoreReturn),
+		})
+	}
+	if err := xw.writeSheet("Ledger Goods", []string{
+		"Name", "Code", "Risk", "Price", "Base", "IgnoreReturn",
+	}, rows); err != nil {
+		return err
+	}
+
+	rows = nil
+	for _, t := range data.Assets.Transactions {
+		row := []interface{}{t.Date}
+		for _, g := range []*v1.Goods{t.From, t.To} {
+			if g == nil {
+				row = append(row, "", "", "")
+				continue
+			}
+			row = append(row, xlsxNumber(g.Quantity), g.Name, g.Custodian)
+		}
+		row = append(row, t.Reason, t.Comment, t.ID)
+		rows = append(rows, row)
+	}
+	if err := xw.writeSheet("Ledger Transactions", []string{
+		"Date", "From.Quantity", "From.Name", "From.Custodian", "To.Quantity", "To.Name", "To.Custodian",
+		"Reason", "Comment", "ID",
+	}, rows); err != nil {
+		return err
+	}
+
+	rows = nil
+	for _, cp := range data.Assets.Checkpoints {
+		for _, g := range cp.Goods {
+			rows = append(rows, []interface{}{cp.Date, g.Name, xlsxNumber(g.Price)})
+		}
+	}
+	if err := xw.writeSheet("Ledger Checkpoints", []string{"Date", "Name", "Price"}, rows); err != nil {
+		return err
+	}
+
+	rows = nil
+	for _, item := range data.Income.Details {
+		rows = append(rows, []interface{}{
+			item.Date,
+			xlsxNumber(item.Gross), xlsxNumber(item.InsuranceAndHF), xlsxNumber(item.Tax),
+			xlsxPercent(item.ConsumptionProportion), xlsxTags(item.Tags), item.Comment,
+		})
+	}
+	return xw.writeSheet("Ledger Income", []string{
+		"Date", "Gross", "InsuranceAndHF", "Tax", "ConsumptionProportion", "Tags", "Comment",
+	}, rows)
+}
+
+// xlsxSheetName 返回合法的工作表名
+//
+// 工作表名不能包含 : \ / ? * [ ] ，且不超过 31 个字符
+func xlsxSheetName(name string) string {
+	name = strings.NewReplacer(":", "-", `\`, "-", "/", "-", "?", "", "*", "", "[", "(", "]", ")").Replace(name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	return name
+}
+
+// xlsxTags 返回标签的文本表示
+func xlsxTags(tags map[string]string) string {
+	ret := make([]string, 0, len(tags))
+	for k, v := range tags {
+		ret = append(ret, k+":"+v)
+	}
+	sort.Strings(ret)
+	return strings.Join(ret, " ")
+}
+
+// xlsxBool 返回布尔值的文本表示，为 false 时为空
+func xlsxBool(v bool) string {
+	if v {
+		return "true"
+	}
+	return ""
+}
+
+// xlsxCellWidth 返回单元格内容的大致显示宽度
+func xlsxCellWidth(v interface{}) int {
+	switch v := v.(type) {
+	case string:
+		return xlsxTextWidth(v)
+	case xlsxNumber:
+		return len(decimal.Decimal(v).StringFixedBank(2)) + 3
+	case xlsxPercent:
+		return len(decimal.Decimal(v).Shift(2).StringFixedBank(2)) + 1
+	case v1.Date, time.Time:
+		return 10
+	}
+	return 0
+}
+
+// xlsxTextWidth 返回文本的大致显示宽度，宽字符计为 2
+func xlsxTextWidth(s string) int {
+	w := 0
+	for _, c := range s {
+		if c > 0x2E80 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	return w
+}
@@ -0,0 +1,93 @@
+package exports
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestWriteXLSX 测试 WriteXLSX 方法
+func TestWriteXLSX(t *testing.T) {
+	date := func(s string) v1.Date {
+		d, _ := time.Parse(time.DateOnly, s)
+		return v1.Date{Time: d}
+	}
+	data := &v1.Root{
+		Assets: v1.Assets{
+			Goods: []v1.GoodsInfo{
+				{Name: "CNY", Risk: v1.Risk0, Price: decimal.NewFromInt(1), Base: true},
+				{Name: "ETF", Risk: v1.Risk3, Price: decimal.NewFromInt(12)},
+			},
+			Transactions: []v1.Transaction{
+				{
+					Date:   date("2024-01-02"),
+					To:     &v1.Goods{Quantity: decimal.NewFromInt(2000), Name: "CNY", Custodian: "Bank"},
+					Reason: "工资",
+				},
+				{
+					Date: date("2024-01-03"),
+					From: &v1.Goods{Quantity: decimal.NewFromInt(1000), Name: "CNY", Custodian: "Bank"},
+					To:   &v1.Goods{Quantity: decimal.NewFromInt(100), Name: "ETF", Custodian: "Bank"},
+				},
+			},
+		},
+		Income: v1.Income{
+			Details: []v1.IncomeItem{{
+				Date:  date("2024-01-10"),
+				Gross: decimal.NewFromInt(30000),
+				Tags:  map[string]string{"company": "A"},
+			}},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteXLSX(context.Background(), buf, data, Options{Currency: "CNY", Raw: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := excelize.OpenReader(buf)
+	if err != nil {
+		t.Fatalf("open xlsx error: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	expectedSheets := []string{
+		"All Goods", "Holding", "Risks", "Custodians", "Checkpoints", "Total P&L",
+		"Income", "Income by company",
+		"Ledger Goods", "Ledger Transactions", "Ledger Checkpoints", "Ledger Income",
+	}
+	if sheets := f.GetSheetList(); !reflect.DeepEqual(sheets, expectedSheets) {
+		t.Errorf("unexpected sheets: %v (expected: %v)", sheets, expectedSheets)
+	}
+
+	// 数值单元格
+	cell, err := f.GetCellValue("Holding", "C2", excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatalf("get cell error: %v", err)
+	}
+	if cell != "1000" {
+		t.Errorf("unexpected Holding!C2: %q (expected: \"1000\")", cell)
+	}
+	cellType, err := f.GetCellType("Holding", "C2")
+	if err != nil {
+		t.Fatalf("get cell type error: %v", err)
+	}
+	if cellType != excelize.CellTypeNumber && cellType != excelize.CellTypeUnset {
+		t.Errorf("unexpected Holding!C2 type: %v (expected: number)", cellType)
+	}
+
+	// 百分比单元格
+	cell, err = f.GetCellValue("Holding", "D2")
+	if err != nil {
+		t.Fatalf("get cell error: %v", err)
+	}
+	if cell != "45.45%" {
+		t.Errorf("unexpected Holding!D2: %q (expected: \"45.45%%\")", cell)
+	}
+}
@@ -0,0 +1,296 @@
+package imports
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// bankStatementRecord 银行流水记录
+type bankStatementRecord struct {
+	// 交易日期
+	Date v1.Date
+	// 交易金额，收入为正，支出为负
+	Amount decimal.Decimal
+	// 交易后余额
+	Balance decimal.Decimal
+	// 是否有余额
+	HasBalance bool
+	// 币种
+	Currency string
+	// 交易类型或摘要
+	Type string
+	// 交易说明（如对方户名、交易备注）
+	Description string
+}
+
+// bankStatement 银行流水
+type bankStatement struct {
+	// 银行（托管机构）
+	Custodian string
+	// 流水记录（按时间正序）
+	Records []bankStatementRecord
+	// 判断是否利息记录
+	IsInterest func(record bankStatementRecord) bool
+}
+
+// Convert 将银行流水转换为交易，并核对流水中的余额
+func (s *bankStatement) Convert(ctx context.Context) (*v1.Assets, error) {
+	if err := s.Reconcile(ctx); err != nil {
+		return nil, err
+	}
+
+	goods := newGoodsSet()
+	ret := &v1.Assets{}
+	for _, record := range s.Records {
+		if record.Amount.IsZero() {
+			continue
+		}
+		goods.Add(v1.GoodsInfo{Name: record.Currency, Base: true})
+
+		t := v1.Transaction{
+			Date:    record.Date,
+			Comment: strings.TrimSpace(record.Type + " " + record.Description),
+		}
+		g := &v1.Goods{Quantity: record.Amount.Abs(), Name: record.Currency, Custodian: s.Custodian}
+		switch {
+		case s.IsInterest(record):
+			t.Reason = reasonInterestDividend
+			if record.Amount.IsPositive() {
+				t.To = g
+			} else {
+				t.From = g
+			}
+		case record.Amount.IsPositive():
+			t.Reason = reasonDeposit
+			t.To = g
+		default:
+			t.Reason = reasonWithdrawal
+			t.From = g
+		}
+		ret.Transactions = append(ret.Transactions, t)
+	}
+	ret.Goods = goods.List()
+	return ret, nil
+}
+
+// Reconcile 核对流水
+//
+// 以第一条记录的交易前余额为期初余额，逐条累加交易金额，应与每条记录的交易后余额（即最终与期末余额）一致
+func (s *bankStatement) Reconcile(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	balances := map[string]decimal.Decimal{}
+	opening := map[string]decimal.Decimal{}
+	for i, record := range s.Records {
+		if !record.HasBalance {
+			continue
+		}
+		balance, ok := balances[record.Currency]
+		if !ok {
+			// 期初余额
+			balance = record.Balance.Sub(record.Amount)
+			opening[record.Currency] = balance
+		}
+		balance = balance.Add(record.Amount)
+		if !balance.Equal(record.Balance) {
+			return fmt.Errorf(
+				"statement balance mismatch at record %d (%s %s %s): expected %s, got %s",
+				i+1, record.Date, record.Amount, record.Currency, balance, record.Balance,
+			)
+		}
+		balances[record.Currency] = balance
+	}
+	for currency, closing := range balances {
+		logger.Info(fmt.Sprintf(
+			"%s statement reconciled: %s opening balance %s, closing balance %s",
+			s.Custodian, currency, opening[currency], closing,
+		))
+	}
+	return nil
+}
+
+// bankColumns 银行流水各字段可能的列名
+type bankColumns struct {
+	Date        []string
+	Income      []string
+	Expense     []string
+	Amount      []string
+	Balance     []string
+	Currency    []string
+	Type        []string
+	Description []string
+}
+
+// currencyCodes 中文币种名对应的货币代码
+var currencyCodes = map[string]string{
+	"人民币":  "CNY",
+	"美元":   "USD",
+	"港币":   "HKD",
+	"港元":   "HKD",
+	"欧元":   "EUR",
+	"英镑":   "GBP",
+	"日元":   "JPY",
+	"澳元":   "AUD",
+	"加元":   "CAD",
+	"新加坡元": "SGD",
+}
+
+// currencyCode 返回币种对应的货币代码，未知币种原样返回
+func currencyCode(name string) string {
+	name = strings.TrimSpace(name)
+	if code, ok := currencyCodes[name]; ok {
+		return code
+	}
+	return name
+}
+
+// readBankStatement 读取银行流水 CSV （从表头行开始）
+//
+// 收入和支出可以是分开的两列，也可以是带符号的一列金额。以 # 开头的说明行会被忽略。记录按日期正序返回
+func readBankStatement(text []byte, columns bankColumns, defaultCurrency string) ([]bankStatementRecord, error) {
+	// 去掉说明行
+	var lines []string
+	for _, line := range strings.Split(string(text), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	csvR := csv.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	csvR.FieldsPerRecord = -1
+	csvR.LazyQuotes = true
+	csvR.TrimLeadingSpace = true
+	rows, err := csvR.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv error: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	// 确定各字段所在列
+	index := map[string]int{}
+	for i, name := range rows[0] {
+		index[strings.TrimSpace(name)] = i
+	}
+	find := func(names []string) int {
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	dateI := find(columns.Date)
+	incomeI := find(columns.Income)
+	expenseI := find(columns.Expense)
+	amountI := find(columns.Amount)
+	if dateI < 0 || (amountI < 0 && (incomeI < 0 || expenseI < 0)) {
+		return nil, fmt.Errorf("required columns not found in header: %v", rows[0])
+	}
+	balanceI := find(columns.Balance)
+	currencyI := find(columns.Currency)
+	typeI := find(columns.Type)
+	descriptionI := find(columns.Description)
+
+	var ret []bankStatementRecord
+	for lineI, row := range rows[1:] {
+		get := func(i int) string {
+			if i < 0 || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+		date, err := parseDate(get(dateI))
+		if err != nil {
+			// 空行或表尾合计行
+			continue
+		}
+		record := bankStatementRecord{
+			Date:        v1.Date{Time: date},
+			Currency:    currencyCode(get(currencyI)),
+			Type:        get(typeI),
+			Description: get(descriptionI),
+		}
+		if record.Currency == "" {
+			record.Currency = defaultCurrency
+		}
+
+		if amountI >= 0 {
+			record.Amount, err = parseAmount(get(amountI))
+			if err != nil {
+				return nil, fmt.Errorf("parse amount %q at line %d error: %w", get(amountI), lineI+2, err)
+			}
+		} else {
+			income, err := parseAmount(get(incomeI))
+			if err != nil {
+				return nil, fmt.Errorf("parse income %q at line %d error: %w", get(incomeI), lineI+2, err)
+			}
+			expense, err := parseAmount(get(expenseI))
+			if err != nil {
+				return nil, fmt.Errorf("parse expense %q at line %d error: %w", get(expenseI), lineI+2, err)
+			}
+			record.Amount = income.Abs().Sub(expense.Abs())
+		}
+
+		if balance := get(balanceI); balance != "" {
+			record.Balance, err = parseAmount(balance)
+			if err != nil {
+				return nil, fmt.Errorf("parse balance %q at line %d error: %w", balance, lineI+2, err)
+			}
+			record.HasBalance = true
+		}
+		ret = append(ret, record)
+	}
+
+	// 按时间倒序导出的流水需要反转
+	if isDescending(ret) {
+		for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+			ret[i], ret[j] = ret[j], ret[i]
+		}
+	}
+	return ret, nil
+}
+
+// isDescending 判断流水是否按时间倒序排列
+//
+// 首尾日期相同时根据余额变化判断
+func isDescending(records []bankStatementRecord) bool {
+	if len(records) < 2 {
+		return false
+	}
+	first, last := records[0], records[len(records)-1]
+	if !first.Date.Equal(last.Date.Time) {
+		return first.Date.After(last.Date.Time)
+	}
+	second := records[1]
+	if first.HasBalance && second.HasBalance && first.Currency == second.Currency {
+		return !first.Balance.Add(second.Amount).Equal(second.Balance)
+	}
+	return false
+}
+
+// currencyFromPreamble 从表头前的说明中获取币种，如“币种:[人民币]”
+func currencyFromPreamble(text []byte, defaultCurrency string) string {
+	for _, line := range strings.Split(string(text), "\n") {
+		_, after, ok := strings.Cut(line, "币种")
+		if !ok {
+			continue
+		}
+		after = strings.Trim(after, ":：[] \t\r#")
+		if i := strings.IndexAny(after, "] \t"); i >= 0 {
+			after = after[:i]
+		}
+		if code, ok := currencyCodes[after]; ok {
+			return code
+		}
+	}
+	return defaultCurrency
+}
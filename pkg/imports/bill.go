@@ -0,0 +1,304 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// billCurrency 账单货币
+const billCurrency = "CNY"
+
+// billColumns 账单各字段可能的列名
+type billColumns struct {
+	Time         []string
+	Category     []string
+	Counterparty []string
+	Description  []string
+	Direction    []string
+	Amount       []string
+	Method       []string
+	Status       []string
+	ID           []string
+	Comment      []string
+}
+
+// billRecord 账单记录
+type billRecord struct {
+	// 交易时间
+	Time string
+	// 交易分类
+	Category string
+	// 交易对方
+	Counterparty string
+	// 商品说明
+	Description string
+	// 收/支
+	Direction string
+	// 金额
+	Amount decimal.Decimal
+	// 收/付款方式
+	Method string
+	// 交易状态
+	Status string
+	// 交易订单号
+	ID string
+	// 备注
+	Comment string
+}
+
+// readBill 读取账单 CSV （从表头行开始）
+func readBill(text []byte, columns billColumns) ([]billRecord, error) {
+	csvR := csv.NewReader(bytes.NewReader(text))
+	csvR.FieldsPerRecord = -1
+	csvR.LazyQuotes = true
+	csvR.TrimLeadingSpace = true
+	rows, err := csvR.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv error: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	// 确定各字段所在列
+	index := map[string]int{}
+	for i, name := range rows[0] {
+		index[strings.TrimSpace(name)] = i
+	}
+	find := func(names []string) int {
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	timeI := find(columns.Time)
+	directionI := find(columns.Direction)
+	amountI := find(columns.Amount)
+	if timeI < 0 || directionI < 0 || amountI < 0 {
+		return nil, fmt.Errorf("required columns not found in header: %v", rows[0])
+	}
+	categoryI := find(columns.Category)
+	counterpartyI := find(columns.Counterparty)
+	descriptionI := find(columns.Description)
+	methodI := find(columns.Method)
+	statusI := find(columns.Status)
+	idI := find(columns.ID)
+	commentI := find(columns.Comment)
+
+	var ret []billRecord
+	for lineI, row := range rows[1:] {
+		get := func(i int) string {
+			if i < 0 || i >= len(row) {
+				return ""
+			}
+			v := strings.TrimSpace(row[i])
+			if v == "/" {
+				// 微信支付账单中的空值
+				return ""
+			}
+			return v
+		}
+		if len(row) <= amountI || len(row) <= directionI || get(timeI) == "" {
+			// 空行或表尾说明行
+			continue
+		}
+		amount, err := parseAmount(get(amountI))
+		if err != nil {
+			return nil, fmt.Errorf("parse amount %q at line %d error: %w", get(amountI), lineI+2, err)
+		}
+		ret = append(ret, billRecord{
+			Time:         get(timeI),
+			Category:     get(categoryI),
+			Counterparty: get(counterpartyI),
+			Description:  get(descriptionI),
+			Direction:    get(directionI),
+			Amount:       amount,
+			Method:       get(methodI),
+			Status:       get(statusI),
+			ID:           get(idI),
+			Comment:      get(commentI),
+		})
+	}
+	return ret, nil
+}
+
+var (
+	// billFundPurchasePattern 基金买入的商品说明
+	billFundPurchasePattern = regexp.MustCompile(`(买入|申购|认购|定投)`)
+	// billFundRedemptionPattern 基金卖出的商品说明
+	billFundRedemptionPattern = regexp.MustCompile(`(卖出|赎回)`)
+	// billFundSharesPattern 商品说明中的基金份额，如“100.00份”
+	billFundSharesPattern = regexp.MustCompile(`([\d,]+(?:\.\d+)?)\s*份`)
+	// billFundIncomePattern 货币基金收益的商品说明
+	billFundIncomePattern = regexp.MustCompile(`收益发放`)
+	// billFundNameTrimPattern 从商品说明中提取基金名时需要去掉的部分
+	billFundNameTrimPattern = regexp.MustCompile(`^(蚂蚁财富|理财通)[-－]|[-－]?(买入|申购|认购|定投|卖出|赎回|收益发放).*$|[-－]\d{4}\.\d{2}\.\d{2}`)
+	// billMethodTrimPattern 从支付方式中去掉卡号等后缀
+	billMethodTrimPattern = regexp.MustCompile(`[(（].*[)）]$`)
+)
+
+// billConverter 将账单记录转换为交易
+type billConverter struct {
+	// 钱包托管机构，如“支付宝”
+	custodian string
+	// 钱包余额的支付方式名
+	walletMethods map[string]bool
+	// 钱包内货币基金的支付方式名，如“余额宝”
+	walletFunds map[string]bool
+	// 判断是否投资理财记录
+	isInvestment func(record billRecord) bool
+	// 判断交易状态是否有效
+	isValidStatus func(status string) bool
+
+	goods *goodsSet
+}
+
+// Convert 转换账单记录
+func (c *billConverter) Convert(ctx context.Context, records []billRecord) (*v1.Assets, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	c.goods = newGoodsSet()
+	c.goods.Add(v1.GoodsInfo{Name: billCurrency, Base: true})
+	ret := &v1.Assets{}
+	for i := len(records) - 1; i >= 0; i-- {
+		// 账单按时间倒序排列
+		record := records[i]
+		if !c.isValidStatus(record.Status) {
+			logger.V(1).Info(fmt.Sprintf("skip bill record %s with status %q", record.ID, record.Status))
+			continue
+		}
+		date, err := parseDate(record.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		t, ok := c.convert(logger, record)
+		if !ok {
+			logger.V(1).Info(fmt.Sprintf("skip bill record %s: %s %s", record.ID, record.Description, record.Amount))
+			continue
+		}
+		t.Date = v1.Date{Time: date}
+		t.ID = record.ID
+		ret.Transactions = append(ret.Transactions, t)
+	}
+	ret.Goods = c.goods.List()
+	return ret, nil
+}
+
+// convert 转换一条账单记录，返回 false 表示忽略该记录
+//
+// 基金买入和卖出的份额取自商品说明，商品说明中没有份额时按 tradeTransaction 记录为份额待确认
+func (c *billConverter) convert(logger logr.Logger, record billRecord) (v1.Transaction, bool) {
+	amount := record.Amount.Abs()
+	comment := strings.TrimSpace(strings.Join([]string{record.Counterparty, record.Description, record.Comment}, " "))
+
+	// 投资理财
+	if c.isInvestment(record) {
+		fund := billFundName(record.Description)
+		switch {
+		case fund == "":
+		case billFundIncomePattern.MatchString(record.Description):
+			c.goods.Add(v1.GoodsInfo{Name: fund})
+			return v1.Transaction{
+				From:    &v1.Goods{Name: fund, Custodian: c.custodian},
+				To:      &v1.Goods{Quantity: amount, Name: fund, Custodian: c.custodian},
+				Reason:  reasonInterestDividend,
+				Comment: comment,
+			}, true
+		case billFundPurchasePattern.MatchString(record.Description):
+			c.goods.Add(v1.GoodsInfo{Name: fund})
+			shares := &v1.Goods{Quantity: billFundShares(record.Description), Name: fund, Custodian: c.custodian}
+			return tradeTransaction(logger, true, c.goodsOf(record.Method, amount), shares, comment), true
+		case billFundRedemptionPattern.MatchString(record.Description):
+			c.goods.Add(v1.GoodsInfo{Name: fund})
+			shares := &v1.Goods{Quantity: billFundShares(record.Description), Name: fund, Custodian: c.custodian}
+			return tradeTransaction(logger, false, c.goodsOf(record.Method, amount), shares, comment), true
+		}
+	}
+
+	// 收支
+	reason := record.Category
+	switch record.Direction {
+	case "支出":
+		if reason == "" {
+			reason = reasonExpense
+		}
+		return v1.Transaction{
+			From:    c.goodsOf(record.Method, amount),
+			Reason:  reason,
+			Comment: comment,
+		}, true
+	case "收入":
+		if reason == "" {
+			reason = reasonIncome
+		}
+		return v1.Transaction{
+			To:      c.goodsOf(record.Method, amount),
+			Reason:  reason,
+			Comment: comment,
+		}, true
+	}
+
+	// 不计收支的记录（如账户间转账）
+	return v1.Transaction{}, false
+}
+
+// goodsOf 根据收/付款方式返回收付的商品
+//
+// 使用钱包内货币基金收付时为该基金，否则为对应托管机构的货币
+func (c *billConverter) goodsOf(method string, amount decimal.Decimal) *v1.Goods {
+	method = strings.TrimSpace(method)
+	if c.walletFunds[method] {
+		c.goods.Add(v1.GoodsInfo{Name: method})
+		return &v1.Goods{Quantity: amount, Name: method, Custodian: c.custodian}
+	}
+	return &v1.Goods{Quantity: amount, Name: billCurrency, Custodian: c.custodianOf(method)}
+}
+
+// custodianOf 根据支付方式返回资金托管机构
+//
+// 使用钱包余额支付时为钱包托管机构，使用银行卡等支付时为对应的银行卡
+func (c *billConverter) custodianOf(method string) string {
+	method = strings.TrimSpace(method)
+	if method == "" || c.walletMethods[method] {
+		return c.custodian
+	}
+	for _, m := range strings.Split(method, "&") {
+		// 组合支付时取第一个支付方式
+		m = strings.TrimSpace(billMethodTrimPattern.ReplaceAllString(m, ""))
+		if m == "" || c.walletMethods[m] {
+			return c.custodian
+		}
+		return m
+	}
+	return c.custodian
+}
+
+// billFundName 从商品说明中提取基金名
+func billFundName(description string) string {
+	return strings.TrimSpace(billFundNameTrimPattern.ReplaceAllString(description, ""))
+}
+
+// billFundShares 从商品说明中提取基金份额，没有份额时返回零
+func billFundShares(description string) decimal.Decimal {
+	m := billFundSharesPattern.FindStringSubmatch(description)
+	if m == nil {
+		return decimal.Zero
+	}
+	shares, err := decimal.NewFromString(strings.ReplaceAll(m[1], ",", ""))
+	if err != nil {
+		return decimal.Zero
+	}
+	return shares
+}
@@ -0,0 +1,64 @@
+package imports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// unconfirmedShares 商品份额未知时交易备注的前缀
+const unconfirmedShares = "份额待确认"
+
+// goodsSet 按添加顺序去重的商品信息集合
+type goodsSet struct {
+	names map[string]int
+	goods []v1.GoodsInfo
+}
+
+// newGoodsSet 创建 goodsSet
+func newGoodsSet() *goodsSet {
+	return &goodsSet{names: map[string]int{}}
+}
+
+// Add 添加商品信息，已存在同名商品时补充其缺失的代号和基础商品标记
+func (s *goodsSet) Add(info v1.GoodsInfo) {
+	if info.Name == "" {
+		return
+	}
+	if i, ok := s.names[info.Name]; ok {
+		if s.goods[i].Code == "" {
+			s.goods[i].Code = info.Code
+		}
+		s.goods[i].Base = s.goods[i].Base || info.Base
+		return
+	}
+	s.names[info.Name] = len(s.goods)
+	s.goods = append(s.goods, info)
+}
+
+// List 返回所有商品信息
+func (s *goodsSet) List() []v1.GoodsInfo {
+	return s.goods
+}
+
+// tradeTransaction 返回以货币买入（ buy 为 true ）或卖出商品的交易
+//
+// 导入的记录中没有商品份额时（ goods 数量为零）仍记录现金一侧，使货币余额与原始记录一致，
+// 商品份额记为零，在备注中标记份额待确认并提示用户补充实际份额
+func tradeTransaction(logger logr.Logger, buy bool, cash, goods *v1.Goods, comment string) v1.Transaction {
+	t := v1.Transaction{From: goods, To: cash, Reason: reasonSell, Comment: comment}
+	if buy {
+		t.From, t.To, t.Reason = cash, goods, reasonBuy
+	}
+	if goods.Quantity.IsZero() {
+		t.Comment = strings.TrimSpace(unconfirmedShares + ": " + comment)
+		logger.Info(fmt.Sprintf(
+			"WARN shares of %s unknown in %s %s %s (%s), please set the quantity manually",
+			goods.Name, t.Reason, cash.Quantity, cash.Name, comment,
+		))
+	}
+	return t
+}
@@ -0,0 +1,103 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const alipayDefaultCustodian = "支付宝"
+
+// alipayColumns 支付宝账单各字段可能的列名（兼容新旧两种导出格式）
+var alipayColumns = billColumns{
+	Time:         []string{"交易时间", "交易创建时间", "付款时间"},
+	Category:     []string{"交易分类", "类型"},
+	Counterparty: []string{"交易对方"},
+	Description:  []string{"商品说明", "商品名称"},
+	Direction:    []string{"收/支"},
+	Amount:       []string{"金额", "金额（元）", "金额(元)"},
+	Method:       []string{"收/付款方式"},
+	Status:       []string{"交易状态"},
+	ID:           []string{"交易订单号", "交易号"},
+	Comment:      []string{"备注"},
+}
+
+func init() {
+	Register(alipayImporter{})
+}
+
+// alipayImporter 支付宝账单导入器
+type alipayImporter struct{}
+
+var _ Importer = alipayImporter{}
+
+// Name 返回导入器名
+func (alipayImporter) Name() string {
+	return "alipay"
+}
+
+// Description 返回导入器描述
+func (alipayImporter) Description() string {
+	return "Alipay (支付宝) bill CSV"
+}
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+func (alipayImporter) Detect(data []byte) bool {
+	text, err := decodeText(data, "")
+	if err != nil {
+		return false
+	}
+	if !bytes.Contains(text, []byte("支付宝")) {
+		return false
+	}
+	_, ok := skipPreamble(text, "交易", "收/支", "金额")
+	return ok
+}
+
+// Import 导入数据
+func (alipayImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	return ImportAlipay(ctx, r, opts)
+}
+
+// ImportAlipay 导入支付宝账单
+//
+// 基金收益发放记录转换为资产交易，基金买入和卖出记录转换为货币与基金的交换（没有份额时记为份额待确认），其它收入和支出记录转换为单边的收支交易
+func ImportAlipay(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read data error: %w", err)
+	}
+	text, err := decodeText(raw, "")
+	if err != nil {
+		return nil, err
+	}
+	text, ok := skipPreamble(text, "交易", "收/支", "金额")
+	if !ok {
+		return nil, fmt.Errorf("bill header not found")
+	}
+	records, err := readBill(text, alipayColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	custodian := opts.Custodian
+	if custodian == "" {
+		custodian = alipayDefaultCustodian
+	}
+	c := &billConverter{
+		custodian:     custodian,
+		walletMethods: map[string]bool{"余额": true, "账户余额": true},
+		walletFunds:   map[string]bool{"余额宝": true},
+		isInvestment: func(record billRecord) bool {
+			return record.Category == "投资理财" || strings.HasPrefix(record.Description, "蚂蚁财富")
+		},
+		isValidStatus: func(status string) bool {
+			return !strings.Contains(status, "关闭") && !strings.Contains(status, "失败")
+		},
+	}
+	return c.Convert(ctx, records)
+}
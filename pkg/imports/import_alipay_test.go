@@ -0,0 +1,112 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/shopspring/decimal"
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// alipayTestCSV 支付宝账单（按时间倒序）
+const alipayTestCSV = `支付宝交易记录明细查询
+账号:[test@example.com]
+起始日期:[2024-01-01 00:00:00]    终止日期:[2024-02-01 00:00:00]
+---------------------------------交易记录明细列表------------------------------------
+交易时间,交易分类,交易对方,对方账号,商品说明,收/支,金额,收/付款方式,交易状态,交易订单号,商户订单号,备注,
+2024-01-06 10:00:00,投资理财,蚂蚁财富,,蚂蚁财富-易方达蓝筹精选混合-卖出-500.00份,收入,600.00,余额,交易成功,T6,,,
+2024-01-05 10:00:00,投资理财,蚂蚁财富,,蚂蚁财富-易方达蓝筹精选混合-买入,支出,1000.00,招商银行储蓄卡(1234),交易成功,T5,,,
+2024-01-04 09:00:00,投资理财,蚂蚁财富,,余额宝-2024.01.03-收益发放,不计收支,1.23,余额宝,交易成功,T4,,,
+2024-01-03 12:00:00,退款,某商家,,午餐-退款,不计收支,20.00,余额,退款成功,T3,,,
+2024-01-03 11:00:00,餐饮美食,某商家,,午餐,支出,20.00,余额,交易关闭,T2,,,
+2024-01-02 08:00:00,餐饮美食,早餐店,,早餐,支出,8.50,花呗&余额,交易成功,T1,,,
+2024-01-01 08:00:00,转账红包,张三,,红包,收入,100.00,余额宝,交易成功,T0,,,
+------------------------------------------------------------------------------------
+共7笔记录
+`
+
+// transactionString 返回交易的简要描述，用于测试比较
+func transactionString(t v1.Transaction) string {
+	goods := func(g *v1.Goods) string {
+		if g == nil {
+			return "-"
+		}
+		return g.Quantity.String() + " " + g.Name + "(" + g.Custodian + ")"
+	}
+	return t.Date.String() + " " + goods(t.From) + " -> " + goods(t.To) + " " + t.Reason + " " + t.ID
+}
+
+// TestImportAlipay 测试 ImportAlipay 方法
+func TestImportAlipay(t *testing.T) {
+	// 支付宝导出的账单为 GBK 编码
+	raw, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(alipayTestCSV))
+	if err != nil {
+		t.Fatalf("encode gbk error: %v", err)
+	}
+	if !(alipayImporter{}).Detect(raw) {
+		t.Errorf("alipay bill not detected")
+	}
+	if (wechatImporter{}).Detect(raw) {
+		t.Errorf("alipay bill detected as wechat bill")
+	}
+
+	var warnings []string
+	ctx := logr.NewContext(context.Background(), funcr.New(func(_, args string) {
+		if strings.Contains(args, "WARN") {
+			warnings = append(warnings, args)
+		}
+	}, funcr.Options{}))
+	ret, err := ImportAlipay(ctx, bytes.NewReader(raw), Options{})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+
+	expected := []string{
+		"2024-01-01 - -> 100 余额宝(支付宝) 转账红包 T0",
+		"2024-01-02 8.5 CNY(花呗) -> - 餐饮美食 T1",
+		"2024-01-04 0 余额宝(支付宝) -> 1.23 余额宝(支付宝) 利息分红 T4",
+		"2024-01-05 1000 CNY(招商银行储蓄卡) -> 0 易方达蓝筹精选混合(支付宝) 买入 T5",
+		"2024-01-06 500 易方达蓝筹精选混合(支付宝) -> 600 CNY(支付宝) 卖出 T6",
+	}
+	if len(ret.Transactions) != len(expected) {
+		t.Fatalf("unexpected transactions: %v (expected %d transactions)", ret.Transactions, len(expected))
+	}
+	for i, e := range expected {
+		if got := transactionString(ret.Transactions[i]); got != e {
+			t.Errorf("unexpected transaction %d: %q (expected: %q)", i, got, e)
+		}
+	}
+	// 份额未知的基金买入记录现金并提示
+	if !strings.HasPrefix(ret.Transactions[3].Comment, unconfirmedShares) {
+		t.Errorf("unexpected comment of transaction 3: %q", ret.Transactions[3].Comment)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "易方达蓝筹精选混合") {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	// 基金买入和卖出的现金变动与账单一致
+	cash := map[string]decimal.Decimal{}
+	for _, tx := range ret.Transactions {
+		if tx.From != nil && tx.From.Name == billCurrency {
+			cash[tx.From.Custodian] = cash[tx.From.Custodian].Sub(tx.From.Quantity)
+		}
+		if tx.To != nil && tx.To.Name == billCurrency {
+			cash[tx.To.Custodian] = cash[tx.To.Custodian].Add(tx.To.Quantity)
+		}
+	}
+	expectedCash := map[string]string{"花呗": "-8.5", "招商银行储蓄卡": "-1000", "支付宝": "600"}
+	if len(cash) != len(expectedCash) {
+		t.Errorf("unexpected cash flows: %v (expected: %v)", cash, expectedCash)
+	}
+	for custodian, e := range expectedCash {
+		if cash[custodian].String() != e {
+			t.Errorf("unexpected cash flow of %s: %s (expected: %s)", custodian, cash[custodian], e)
+		}
+	}
+}
@@ -0,0 +1,603 @@
+package imports
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const (
+	beancountDefaultCurrency = "CNY"
+	// beancountIncomeTag 由收入明细导出的交易的标签，导入时跳过
+	beancountIncomeTag = "income"
+)
+
+func init() {
+	Register(beancountImporter{})
+}
+
+// beancountImporter Beancount 导入器
+type beancountImporter struct{}
+
+var _ Importer = beancountImporter{}
+
+// Name 返回导入器名
+func (beancountImporter) Name() string {
+	return "beancount"
+}
+
+// Description 返回导入器描述
+func (beancountImporter) Description() string {
+	return "Beancount ledger (open, commodity, price and transaction directives)"
+}
+
+// beancountDetectPattern Beancount 特有的指令
+var beancountDetectPattern = regexp.MustCompile(`(?m)^(\d{4}-\d{2}-\d{2}\s+(open|commodity)\s|option\s+")`)
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+func (beancountImporter) Detect(data []byte) bool {
+	return beancountDetectPattern.Match(data)
+}
+
+// Import 导入数据
+func (beancountImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	return ImportBeancount(ctx, r, opts)
+}
+
+// beancountDirective Beancount 指令
+type beancountDirective struct {
+	// 行号
+	Line int
+	// 日期
+	Date time.Time
+	// 指令类型（ open 、 commodity 、 price 、 txn 等）
+	Type string
+	// 指令参数（已去掉引号的字符串和其它标记）
+	Args []string
+	// 标签
+	Tags []string
+	// 元数据
+	Meta map[string]string
+	// 分录
+	Postings []beancountPosting
+}
+
+// beancountPosting Beancount 分录
+type beancountPosting struct {
+	Account   string
+	Quantity  decimal.Decimal
+	Commodity string
+	// 没有金额，需要根据其它分录推算
+	Elided bool
+	// 权重（用于配平的金额），为空时等于 Quantity Commodity
+	Weight *beancountAmount
+}
+
+// beancountAmount 金额
+type beancountAmount struct {
+	Quantity  decimal.Decimal
+	Commodity string
+}
+
+// ImportBeancount 导入 Beancount 账本
+//
+// 支持 open 、 commodity 、 price 和交易指令，其余指令被忽略。
+// Assets 和 Liabilities 账户视为托管机构，与 Income 、 Expenses 、 Equity 账户之间的分录转换为单边交易
+func ImportBeancount(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	directives, options, err := parseBeancount(r)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := opts.Currency
+	if currency == "" {
+		currency = options["operating_currency"]
+	}
+	if currency == "" {
+		currency = beancountDefaultCurrency
+	}
+	c := &beancountConverter{
+		currency:   currency,
+		custodian:  opts.Custodian,
+		names:      map[string]string{},
+		custodians: map[string]string{},
+		goods:      newGoodsSet(),
+		prices:     map[string]beancountAmount{},
+		priceDate:  map[string]time.Time{},
+	}
+
+	// 先处理声明，再处理交易。商品声明先于账户处理，以便账户中的商品符号映射为商品名
+	for _, d := range directives {
+		if d.Type == "commodity" {
+			c.commodity(d)
+		}
+	}
+	for _, d := range directives {
+		if d.Type == "open" {
+			c.open(d)
+		}
+	}
+	var transactions []v1.Transaction
+	for _, d := range directives {
+		switch d.Type {
+		case "price":
+			if err := c.price(d); err != nil {
+				return nil, fmt.Errorf("line %d: %w", d.Line, err)
+			}
+		case "txn":
+			ts, err := c.transaction(d)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", d.Line, err)
+			}
+			if len(ts) == 0 {
+				logger.V(1).Info(fmt.Sprintf("skip transaction at line %d", d.Line))
+			}
+			transactions = append(transactions, ts...)
+		}
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.Before(transactions[j].Date.Time)
+	})
+
+	goods := c.goods.List()
+	for i := range goods {
+		if price, ok := c.prices[goods[i].Name]; ok {
+			goods[i].Price = price.Quantity
+		} else if goods[i].Name == currency {
+			goods[i].Price = decimal.NewFromInt(1)
+		}
+	}
+	return &v1.Assets{Goods: goods, Transactions: transactions}, nil
+}
+
+// beancountConverter 将 Beancount 指令转换为商品和交易
+type beancountConverter struct {
+	currency  string
+	custodian string
+	// 商品符号到商品名
+	names map[string]string
+	// 账户到托管机构
+	custodians map[string]string
+	goods      *goodsSet
+	// 商品名到最新价格及其日期
+	prices    map[string]beancountAmount
+	priceDate map[string]time.Time
+}
+
+// open 处理 open 指令
+func (c *beancountConverter) open(d beancountDirective) {
+	if len(d.Args) == 0 {
+		return
+	}
+	account := d.Args[0]
+	if custodian := d.Meta["custodian"]; custodian != "" {
+		c.custodians[account] = custodian
+	}
+	if !isBeancountHolding(account) {
+		return
+	}
+	// 账户声明的商品，主货币视为基础商品，其它商品是否为基础商品由 commodity 指令决定
+	for _, arg := range d.Args[1:] {
+		for _, symbol := range strings.Split(arg, ",") {
+			if symbol = strings.TrimSpace(symbol); symbol != "" {
+				c.goods.Add(v1.GoodsInfo{Name: c.name(symbol), Base: symbol == c.currency})
+			}
+		}
+	}
+}
+
+// commodity 处理 commodity 指令
+func (c *beancountConverter) commodity(d beancountDirective) {
+	if len(d.Args) == 0 {
+		return
+	}
+	symbol := d.Args[0]
+	name := d.Meta["name"]
+	if name == "" {
+		name = symbol
+	}
+	c.names[symbol] = name
+	c.goods.Add(v1.GoodsInfo{
+		Name: name,
+		Code: d.Meta["code"],
+		Base: strings.EqualFold(d.Meta["base"], "TRUE") || symbol == c.currency,
+	})
+}
+
+// price 处理 price 指令，只保留以报告货币计的最新价格
+func (c *beancountConverter) price(d beancountDirective) error {
+	if len(d.Args) < 3 {
+		return fmt.Errorf("invalid price directive: %v", d.Args)
+	}
+	if d.Args[2] != c.currency {
+		return nil
+	}
+	quantity, err := decimal.NewFromString(d.Args[1])
+	if err != nil {
+		return fmt.Errorf("parse price %q error: %w", d.Args[1], err)
+	}
+	name := c.name(d.Args[0])
+	if last, ok := c.priceDate[name]; ok && d.Date.Before(last) {
+		return nil
+	}
+	c.priceDate[name] = d.Date
+	c.prices[name] = beancountAmount{Quantity: quantity, Commodity: d.Args[2]}
+	return nil
+}
+
+// transaction 处理交易指令
+func (c *beancountConverter) transaction(d beancountDirective) ([]v1.Transaction, error) {
+	for _, tag := range d.Tags {
+		if tag == beancountIncomeTag {
+			return nil, nil
+		}
+	}
+	if err := balanceBeancountPostings(d.Postings); err != nil {
+		return nil, err
+	}
+
+	var froms, tos []beancountPosting
+	reason := ""
+	for _, p := range d.Postings {
+		switch {
+		case !isBeancountHolding(p.Account):
+			if reason == "" {
+				reason = beancountAccountLeaf(p.Account)
+			}
+		case p.Quantity.IsNegative():
+			froms = append(froms, p)
+		case p.Quantity.IsPositive():
+			tos = append(tos, p)
+		}
+	}
+
+	narration := ""
+	if len(d.Args) > 0 {
+		narration = d.Args[len(d.Args)-1]
+	}
+	if reason == "" {
+		reason = narration
+	}
+	comment := d.Meta["comment"]
+	if comment == "" && narration != reason {
+		comment = narration
+	}
+	if len(d.Args) > 1 && d.Args[0] != "" {
+		// 收款人
+		comment = strings.TrimSpace(d.Args[0] + " " + comment)
+	}
+	base := v1.Transaction{
+		Date:    v1.Date{Time: d.Date},
+		Reason:  reason,
+		Comment: comment,
+		ID:      d.Meta["id"],
+	}
+
+	// 一出一进为交换，否则每个分录各自为一笔单边交易
+	if len(froms) == 1 && len(tos) == 1 {
+		t := base
+		t.From = c.goodsOf(froms[0])
+		t.To = c.goodsOf(tos[0])
+		return []v1.Transaction{t}, nil
+	}
+	var ret []v1.Transaction
+	for _, p := range froms {
+		t := base
+		t.From = c.goodsOf(p)
+		if name := d.Meta["to"]; name != "" {
+			t.To = &v1.Goods{Name: name, Custodian: t.From.Custodian}
+		}
+		ret = append(ret, t)
+	}
+	for _, p := range tos {
+		t := base
+		t.To = c.goodsOf(p)
+		if name := d.Meta["from"]; name != "" {
+			// 分红等来源数量为 0 的交易
+			t.From = &v1.Goods{Name: name, Custodian: t.To.Custodian}
+		}
+		ret = append(ret, t)
+	}
+	return ret, nil
+}
+
+// goodsOf 返回分录对应的商品
+func (c *beancountConverter) goodsOf(p beancountPosting) *v1.Goods {
+	name := c.name(p.Commodity)
+	c.goods.Add(v1.GoodsInfo{Name: name, Base: p.Commodity == c.currency})
+	return &v1.Goods{Quantity: p.Quantity.Abs(), Name: name, Custodian: c.custodianOf(p.Account)}
+}
+
+// name 返回商品符号对应的商品名
+func (c *beancountConverter) name(symbol string) string {
+	if name, ok := c.names[symbol]; ok {
+		return name
+	}
+	return symbol
+}
+
+// custodianOf 返回账户对应的托管机构
+func (c *beancountConverter) custodianOf(account string) string {
+	if c.custodian != "" {
+		return c.custodian
+	}
+	if custodian, ok := c.custodians[account]; ok {
+		return custodian
+	}
+	_, custodian, _ := strings.Cut(account, ":")
+	return custodian
+}
+
+// isBeancountHolding 判断账户是否资产或负债账户
+func isBeancountHolding(account string) bool {
+	return strings.HasPrefix(account, "Assets:") || strings.HasPrefix(account, "Liabilities:")
+}
+
+// beancountAccountLeaf 返回账户名最后一部分
+func beancountAccountLeaf(account string) string {
+	if i := strings.LastIndex(account, ":"); i >= 0 {
+		return account[i+1:]
+	}
+	return account
+}
+
+// balanceBeancountPostings 推算省略了金额的分录
+func balanceBeancountPostings(postings []beancountPosting) error {
+	elided := -1
+	sums := map[string]decimal.Decimal{}
+	var order []string
+	for i, p := range postings {
+		if p.Elided {
+			if elided >= 0 {
+				return fmt.Errorf("more than one posting without amount")
+			}
+			elided = i
+			continue
+		}
+		w := beancountAmount{Quantity: p.Quantity, Commodity: p.Commodity}
+		if p.Weight != nil {
+			w = *p.Weight
+		}
+		if _, ok := sums[w.Commodity]; !ok {
+			order = append(order, w.Commodity)
+		}
+		sums[w.Commodity] = sums[w.Commodity].Add(w.Quantity)
+	}
+	if elided < 0 {
+		return nil
+	}
+	var remaining []string
+	for _, commodity := range order {
+		if !sums[commodity].IsZero() {
+			remaining = append(remaining, commodity)
+		}
+	}
+	if len(remaining) != 1 {
+		return fmt.Errorf("can not infer amount of posting %q", postings[elided].Account)
+	}
+	postings[elided].Quantity = sums[remaining[0]].Neg()
+	postings[elided].Commodity = remaining[0]
+	postings[elided].Elided = false
+	return nil
+}
+
+// beancountDatePattern 指令行开头的日期
+var beancountDatePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\s+(\S+)(.*)$`)
+
+// parseBeancount 解析 Beancount 账本
+//
+// 返回指令列表和 option 指令设置的选项
+func parseBeancount(r io.Reader) ([]beancountDirective, map[string]string, error) {
+	var directives []beancountDirective
+	options := map[string]string{}
+	var cur *beancountDirective
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		raw := strings.TrimRight(scanner.Text(), "\r")
+		if lineNum == 1 {
+			raw = strings.TrimPrefix(raw, string(utf8BOM))
+		}
+		tokens := beancountTokens(raw)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		// 缩进行为元数据或分录
+		if raw[0] == ' ' || raw[0] == '\t' {
+			if cur == nil {
+				continue
+			}
+			if strings.HasSuffix(tokens[0], ":") && !strings.Contains(strings.TrimSuffix(tokens[0], ":"), ":") {
+				// 元数据
+				if len(tokens) > 1 {
+					cur.Meta[strings.TrimSuffix(tokens[0], ":")] = tokens[1]
+				}
+				continue
+			}
+			if cur.Type != "txn" {
+				continue
+			}
+			p, err := parseBeancountPosting(tokens)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			cur.Postings = append(cur.Postings, p)
+			continue
+		}
+
+		cur = nil
+		if tokens[0] == "option" && len(tokens) >= 3 {
+			if _, ok := options[tokens[1]]; !ok {
+				options[tokens[1]] = tokens[2]
+			}
+			continue
+		}
+		m := beancountDatePattern.FindStringSubmatch(raw)
+		if m == nil {
+			// include 、 plugin 等
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, m[1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: parse date %q error: %w", lineNum, m[1], err)
+		}
+		d := beancountDirective{
+			Line: lineNum,
+			Date: date,
+			Type: tokens[1],
+			Meta: map[string]string{},
+		}
+		if d.Type == "*" || d.Type == "!" || d.Type == "txn" {
+			d.Type = "txn"
+		}
+		for _, token := range tokens[2:] {
+			switch {
+			case strings.HasPrefix(token, "#") && d.Type == "txn":
+				d.Tags = append(d.Tags, token[1:])
+			case strings.HasPrefix(token, "^") && d.Type == "txn":
+				// 链接
+			default:
+				d.Args = append(d.Args, token)
+			}
+		}
+		directives = append(directives, d)
+		cur = &directives[len(directives)-1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read data error: %w", err)
+	}
+	return directives, options, nil
+}
+
+// parseBeancountPosting 解析分录
+//
+// 支持 Account [Quantity Commodity] [{Cost}] [@ Price | @@ TotalPrice]
+func parseBeancountPosting(tokens []string) (beancountPosting, error) {
+	p := beancountPosting{Account: tokens[0]}
+	if len(p.Account) > 0 && (p.Account[0] == '!' || p.Account[0] == '*') {
+		// 分录标记
+		tokens = tokens[1:]
+		if len(tokens) == 0 {
+			return p, fmt.Errorf("posting without account")
+		}
+		p.Account = tokens[0]
+	}
+	rest := tokens[1:]
+	if len(rest) < 2 {
+		p.Elided = true
+		return p, nil
+	}
+	quantity, err := decimal.NewFromString(rest[0])
+	if err != nil {
+		return p, fmt.Errorf("parse quantity %q error: %w", rest[0], err)
+	}
+	p.Quantity = quantity
+	p.Commodity = rest[1]
+	rest = rest[2:]
+
+	for len(rest) > 0 {
+		switch {
+		case strings.HasPrefix(rest[0], "{"):
+			// 成本，作为权重
+			costTokens := []string{}
+			for len(rest) > 0 {
+				costTokens = append(costTokens, rest[0])
+				done := strings.HasSuffix(rest[0], "}")
+				rest = rest[1:]
+				if done {
+					break
+				}
+			}
+			cost := strings.Join(costTokens, " ")
+			total := strings.HasPrefix(cost, "{{")
+			fields := strings.Fields(strings.Trim(cost, "{} "))
+			if len(fields) >= 2 {
+				q, err := decimal.NewFromString(strings.TrimSuffix(fields[0], ","))
+				if err != nil {
+					return p, fmt.Errorf("parse cost %q error: %w", cost, err)
+				}
+				if !total {
+					q = q.Mul(p.Quantity.Abs())
+				}
+				if p.Quantity.IsNegative() {
+					q = q.Neg()
+				}
+				p.Weight = &beancountAmount{Quantity: q, Commodity: strings.TrimSuffix(fields[1], ",")}
+			}
+		case rest[0] == "@" || rest[0] == "@@":
+			if len(rest) < 3 {
+				return p, fmt.Errorf("invalid price annotation: %v", rest)
+			}
+			q, err := decimal.NewFromString(rest[1])
+			if err != nil {
+				return p, fmt.Errorf("parse price %q error: %w", rest[1], err)
+			}
+			if rest[0] == "@" {
+				q = q.Mul(p.Quantity.Abs())
+			}
+			if p.Quantity.IsNegative() {
+				q = q.Neg()
+			}
+			p.Weight = &beancountAmount{Quantity: q, Commodity: rest[2]}
+			rest = rest[3:]
+		default:
+			rest = rest[1:]
+		}
+	}
+	return p, nil
+}
+
+// beancountTokens 将一行拆分为标记，去掉注释和字符串的引号
+func beancountTokens(line string) []string {
+	var tokens []string
+	var cur strings.Builder
+	inString := false
+	hasToken := false
+	flush := func() {
+		if hasToken {
+			tokens = append(tokens, cur.String())
+		}
+		cur.Reset()
+		hasToken = false
+	}
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case inString && ch == '\\' && i+1 < len(line):
+			i++
+			cur.WriteByte(line[i])
+		case inString && ch == '"':
+			inString = false
+		case inString:
+			cur.WriteByte(ch)
+		case ch == '"':
+			inString = true
+			hasToken = true
+		case ch == ';':
+			flush()
+			return tokens
+		case ch == ' ' || ch == '\t':
+			flush()
+		default:
+			cur.WriteByte(ch)
+			hasToken = true
+		}
+	}
+	flush()
+	return tokens
+}
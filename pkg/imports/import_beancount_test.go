@@ -0,0 +1,97 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yhlooo/dragon-acct/pkg/exports"
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestImportBeancountRoundTrip 测试导出为 Beancount 后再导入
+func TestImportBeancountRoundTrip(t *testing.T) {
+	date := func(s string) v1.Date {
+		d, _ := time.Parse(time.DateOnly, s)
+		return v1.Date{Time: d}
+	}
+	data := &v1.Root{
+		Assets: v1.Assets{
+			Goods: []v1.GoodsInfo{
+				{Name: "CNY", Price: decimal.NewFromInt(1), Base: true},
+				{Name: "USD", Price: decimal.RequireFromString("7.1"), Base: true},
+				{Name: "博时现金宝货币B", Code: "000000", Price: decimal.RequireFromString("1.02")},
+			},
+			Transactions: []v1.Transaction{
+				{
+					Date:   date("2024-01-02"),
+					To:     &v1.Goods{Quantity: decimal.NewFromInt(10000), Name: "CNY", Custodian: "汇丰银行"},
+					Reason: "工资",
+				},
+				{
+					Date:   date("2024-01-03"),
+					From:   &v1.Goods{Quantity: decimal.NewFromInt(5000), Name: "CNY", Custodian: "汇丰银行"},
+					To:     &v1.Goods{Quantity: decimal.NewFromInt(5000), Name: "博时现金宝货币B", Custodian: "汇丰银行"},
+					Reason: "买入",
+				},
+				{
+					Date:   date("2024-01-04"),
+					From:   &v1.Goods{Quantity: decimal.NewFromInt(710), Name: "CNY", Custodian: "汇丰银行"},
+					To:     &v1.Goods{Quantity: decimal.NewFromInt(100), Name: "USD", Custodian: "汇丰银行"},
+					Reason: "换汇",
+				},
+				{
+					Date:   date("2024-02-01"),
+					From:   &v1.Goods{Quantity: decimal.NewFromInt(20), Name: "USD", Custodian: "汇丰银行"},
+					Reason: "消费",
+				},
+			},
+		},
+	}
+
+	j, err := exports.NewJournal(data, exports.Options{Currency: "CNY", Date: date("2024-04-01").Time})
+	if err != nil {
+		t.Fatalf("new journal error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := exports.WriteBeancount(buf, j); err != nil {
+		t.Fatalf("write beancount error: %v", err)
+	}
+	ret, err := ImportBeancount(context.Background(), buf, Options{})
+	if err != nil {
+		t.Fatalf("import error: %v\n%s", err, buf.String())
+	}
+
+	expected := []string{
+		"2024-01-02 - -> 10000 CNY(汇丰银行) 工资 ",
+		"2024-01-03 5000 CNY(汇丰银行) -> 5000 博时现金宝货币B(汇丰银行) 买入 ",
+		"2024-01-04 710 CNY(汇丰银行) -> 100 USD(汇丰银行) 换汇 ",
+		"2024-02-01 20 USD(汇丰银行) -> - 消费 ",
+	}
+	if len(ret.Transactions) != len(expected) {
+		t.Fatalf("unexpected transactions: %v (expected %d transactions)", ret.Transactions, len(expected))
+	}
+	for i, e := range expected {
+		if got := transactionString(ret.Transactions[i]); got != e {
+			t.Errorf("unexpected transaction %d: %q (expected: %q)", i, got, e)
+		}
+	}
+
+	goods := map[string]v1.GoodsInfo{}
+	for _, g := range ret.Goods {
+		goods[g.Name] = g
+	}
+	for _, e := range data.Assets.Goods {
+		g, ok := goods[e.Name]
+		if !ok {
+			t.Errorf("goods %q not imported", e.Name)
+			continue
+		}
+		if g.Code != e.Code || g.Base != e.Base || !g.Price.Equal(e.Price) {
+			t.Errorf("unexpected goods %q: %+v (expected: %+v)", e.Name, g, e)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const cmbDefaultCustodian = "招商银行"
+
+// cmbColumns 招商银行流水各字段可能的列名（兼容网银和手机银行导出格式）
+var cmbColumns = bankColumns{
+	Date:        []string{"交易日期", "记账日期"},
+	Income:      []string{"收入"},
+	Expense:     []string{"支出"},
+	Amount:      []string{"交易金额"},
+	Balance:     []string{"余额", "联机余额"},
+	Currency:    []string{"货币", "币种"},
+	Type:        []string{"交易类型", "交易摘要"},
+	Description: []string{"交易备注", "对手信息"},
+}
+
+func init() {
+	Register(cmbImporter{})
+}
+
+// cmbImporter 招商银行流水导入器
+type cmbImporter struct{}
+
+var _ Importer = cmbImporter{}
+
+// Name 返回导入器名
+func (cmbImporter) Name() string {
+	return "cmb"
+}
+
+// Description 返回导入器描述
+func (cmbImporter) Description() string {
+	return "China Merchants Bank (招商银行) statement CSV"
+}
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+func (cmbImporter) Detect(data []byte) bool {
+	text, err := decodeText(data, "")
+	if err != nil {
+		return false
+	}
+	if !bytes.Contains(text, []byte("招商银行")) {
+		return false
+	}
+	_, ok := skipPreamble(text, "日期", "余额")
+	return ok
+}
+
+// Import 导入数据
+func (cmbImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	return ImportCMB(ctx, r, opts)
+}
+
+// ImportCMB 导入招商银行流水
+//
+// 收入和支出转换为托管在银行的货币的存入和支取，利息单独标记，并核对流水余额
+func ImportCMB(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read data error: %w", err)
+	}
+	text, err := decodeText(raw, "")
+	if err != nil {
+		return nil, err
+	}
+	table, ok := skipPreamble(text, "日期", "余额")
+	if !ok {
+		return nil, fmt.Errorf("statement header not found")
+	}
+	currency := opts.Currency
+	if currency == "" {
+		currency = currencyFromPreamble(text[:len(text)-len(table)], "CNY")
+	}
+	records, err := readBankStatement(table, cmbColumns, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	custodian := opts.Custodian
+	if custodian == "" {
+		custodian = cmbDefaultCustodian
+	}
+	s := &bankStatement{
+		Custodian: custodian,
+		Records:   records,
+		IsInterest: func(record bankStatementRecord) bool {
+			return strings.Contains(record.Type, "利息") || strings.Contains(record.Type, "结息")
+		},
+	}
+	return s.Convert(ctx)
+}
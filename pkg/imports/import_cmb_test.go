@@ -0,0 +1,64 @@
+package imports
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// cmbTestCSV 招商银行流水（表头前为说明行）
+const cmbTestCSV = `# 招商银行交易流水
+# 账号: [6214********1234]
+# 币种: [美元]
+# 导出时间: [2024-02-01]
+交易日期,交易时间,收入,支出,余额,交易类型,交易备注
+20240105,10:00:00,,100.00,900.00,快捷支付,某商户
+20240110,09:00:00,"1,000.00",,"1,900.00",转账汇款,张三
+20240121,00:00:00,1.50,,"1,901.50",结息,
+# 合计: 收入 1001.50 支出 100.00
+`
+
+// TestImportCMB 测试 ImportCMB 方法
+func TestImportCMB(t *testing.T) {
+	if !(cmbImporter{}).Detect([]byte(cmbTestCSV)) {
+		t.Errorf("cmb statement not detected")
+	}
+
+	// 余额一致，币种取自说明行
+	ret, err := ImportCMB(context.Background(), strings.NewReader(cmbTestCSV), Options{})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	expected := []string{
+		"2024-01-05 100 USD(招商银行) -> - 出金 ",
+		"2024-01-10 - -> 1000 USD(招商银行) 入金 ",
+		"2024-01-21 - -> 1.5 USD(招商银行) 利息分红 ",
+	}
+	if len(ret.Transactions) != len(expected) {
+		t.Fatalf("unexpected transactions: %v (expected %d transactions)", ret.Transactions, len(expected))
+	}
+	for i, e := range expected {
+		if got := transactionString(ret.Transactions[i]); got != e {
+			t.Errorf("unexpected transaction %d: %q (expected: %q)", i, got, e)
+		}
+	}
+	if len(ret.Goods) != 1 || ret.Goods[0].Name != "USD" || !ret.Goods[0].Base {
+		t.Errorf("unexpected goods: %v", ret.Goods)
+	}
+
+	// 指定的币种优先于说明行
+	ret, err = ImportCMB(context.Background(), strings.NewReader(cmbTestCSV), Options{Currency: "CNY"})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if got := transactionString(ret.Transactions[0]); got != "2024-01-05 100 CNY(招商银行) -> - 出金 " {
+		t.Errorf("unexpected transaction 0: %q", got)
+	}
+
+	// 余额不一致
+	unbalanced := strings.Replace(cmbTestCSV, `"1,900.00"`, `"1,800.00"`, 1)
+	_, err = ImportCMB(context.Background(), strings.NewReader(unbalanced), Options{})
+	if err == nil || !strings.Contains(err.Error(), "balance mismatch at record 2") {
+		t.Errorf("expected balance mismatch error, got: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package imports
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
 	"fmt"
@@ -9,28 +10,71 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
 )
 
+func init() {
+	Register(futuImporter{})
+}
+
+// futuImporter 富途交易记录导入器
+type futuImporter struct{}
+
+var _ Importer = futuImporter{}
+
+// Name 返回导入器名
+func (futuImporter) Name() string {
+	return "futu"
+}
+
+// Description 返回导入器描述
+func (futuImporter) Description() string {
+	return "Futu (富途牛牛) order history CSV"
+}
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+func (futuImporter) Detect(data []byte) bool {
+	csvR := csv.NewReader(bytes.NewReader(data))
+	csvR.TrimLeadingSpace = true
+	csvR.LazyQuotes = true
+	csvR.FieldsPerRecord = -1
+	if _, err := csvR.Read(); err != nil {
+		return false
+	}
+	row, err := csvR.Read()
+	if err != nil || len(row) < 36 {
+		return false
+	}
+	return row[0] == "Buy" || row[0] == "Sell"
+}
+
+// Import 导入数据
+func (futuImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	return ImportFutu(ctx, r, opts)
+}
+
 // ImportFutu 导入富途交易记录
-func ImportFutu(ctx context.Context, r io.Reader, w io.Writer) error {
+//
+// 返回的 v1.Assets 中包含交易记录，以及交易涉及的商品信息（价格需要另外补充）
+func ImportFutu(_ context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
 
 	csvR := csv.NewReader(r)
 	csvR.TrimLeadingSpace = true
 	csvR.LazyQuotes = true
 	rows, err := csvR.ReadAll()
 	if err != nil {
-		return fmt.Errorf("read csv error: %w", err)
+		return nil, fmt.Errorf("read csv error: %w", err)
 	}
 
-	csvW := csv.NewWriter(w)
-	defer csvW.Flush()
-
+	ret := &v1.Assets{}
+	goods := newGoodsSet()
 	for i, row := range rows {
 		if i == 0 {
 			continue
 		}
 		if len(row) < 36 {
-			return fmt.Errorf("csv row too short: %d (at least 36)", len(row))
+			return nil, fmt.Errorf("csv row too short: %d (at least 36)", len(row))
 		}
 		if row[20] == "" {
 			// 没有成交
@@ -38,50 +82,51 @@ func ImportFutu(ctx context.Context, r io.Reader, w io.Writer) error {
 		}
 
 		side := row[0]
+		code := row[1]                                                               // 代码
 		name := row[2]                                                               // 产品名
 		currency := row[16]                                                          // 货币
 		quantity, err := decimal.NewFromString(strings.ReplaceAll(row[18], ",", "")) // 数量
 		if err != nil {
-			return fmt.Errorf("parse quantity %q error: %w", row[18], err)
+			return nil, fmt.Errorf("parse quantity %q error: %w", row[18], err)
 		}
 		price, err := decimal.NewFromString(strings.ReplaceAll(row[19], ",", "")) // 价格
 		if err != nil {
-			return fmt.Errorf("parse price %q error: %w", row[19], err)
+			return nil, fmt.Errorf("parse price %q error: %w", row[19], err)
 		}
 		amount, err := decimal.NewFromString(strings.ReplaceAll(row[20], ",", "")) // 金额
 		if err != nil {
-			return fmt.Errorf("parse amount %q error: %w", row[20], err)
+			return nil, fmt.Errorf("parse amount %q error: %w", row[20], err)
 		}
 		date, err := time.Parse("Jan 2, 2006 15:04:05 MST", strings.ReplaceAll(row[21], "ET", "EDT")) // 日期
 		if err != nil {
-			return fmt.Errorf("parse date %q error: %w", row[21], err)
+			return nil, fmt.Errorf("parse date %q error: %w", row[21], err)
 		}
 		fees, err := decimal.NewFromString(strings.ReplaceAll(row[35], ",", "")) // 手续费
 		if err != nil {
-			return fmt.Errorf("parse fees %q error: %w", row[35], err)
+			return nil, fmt.Errorf("parse fees %q error: %w", row[35], err)
 		}
 
+		t := v1.Transaction{
+			Date:    v1.Date{Time: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)},
+			Comment: fmt.Sprintf("price: %s, fees: %s", price.StringFixedBank(2), fees.StringFixedBank(2)),
+		}
 		switch side {
 		case "Sell":
-			_ = csvW.Write([]string{
-				date.Format(time.DateOnly),
-				quantity.StringFixedBank(2), name, "",
-				amount.Sub(fees).StringFixedBank(2), currency, "",
-				"", fmt.Sprintf("price: %s, fees: %s", price.StringFixedBank(2), fees.StringFixedBank(2)),
-			})
+			t.From = &v1.Goods{Quantity: quantity.RoundBank(2), Name: name, Custodian: opts.Custodian}
+			t.To = &v1.Goods{Quantity: amount.Sub(fees).RoundBank(2), Name: currency, Custodian: opts.Custodian}
 		case "Buy":
-			_ = csvW.Write([]string{
-				date.Format(time.DateOnly),
-				amount.Add(fees).StringFixedBank(2), currency, "",
-				quantity.StringFixedBank(2), name, "",
-				"", fmt.Sprintf("price: %s, fees: %s", price.StringFixedBank(2), fees.StringFixedBank(2)),
-			})
+			t.From = &v1.Goods{Quantity: amount.Add(fees).RoundBank(2), Name: currency, Custodian: opts.Custodian}
+			t.To = &v1.Goods{Quantity: quantity.RoundBank(2), Name: name, Custodian: opts.Custodian}
 		default:
-			return fmt.Errorf("invalid side %q, expected 'Sell' or 'Buy'", side)
+			return nil, fmt.Errorf("invalid side %q, expected 'Sell' or 'Buy'", side)
 		}
+		ret.Transactions = append(ret.Transactions, t)
+		goods.Add(v1.GoodsInfo{Name: name, Code: code})
+		goods.Add(v1.GoodsInfo{Name: currency, Base: true})
 	}
+	ret.Goods = goods.List()
 
-	return nil
+	return ret, nil
 }
 
 // FutuTransaction 富途交易记录
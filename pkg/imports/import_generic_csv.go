@@ -0,0 +1,618 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-logr/logr"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const (
+	genericCSVDefaultCustodian = "CSV"
+	genericCSVDefaultCurrency  = "CNY"
+)
+
+func init() {
+	Register(genericCSVImporter{})
+}
+
+// genericCSVImporter 通用 CSV 导入器
+type genericCSVImporter struct{}
+
+var _ Importer = genericCSVImporter{}
+
+// Name 返回导入器名
+func (genericCSVImporter) Name() string {
+	return "generic-csv"
+}
+
+// Description 返回导入器描述
+func (genericCSVImporter) Description() string {
+	return "Any CSV statement described by a YAML mapping file (--mapping)"
+}
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+//
+// 通用 CSV 需要映射文件，不参与自动识别
+func (genericCSVImporter) Detect([]byte) bool {
+	return false
+}
+
+// Import 导入数据
+func (genericCSVImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	if opts.Mapping == "" {
+		return nil, fmt.Errorf("mapping file is required for generic-csv data")
+	}
+	mapping, err := LoadGenericCSVMapping(opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	return ImportGenericCSV(ctx, r, mapping, opts)
+}
+
+// GenericCSVMapping 通用 CSV 映射配置
+type GenericCSVMapping struct {
+	// 文件编码（ auto 、 utf-8 、 gbk 、 gb18030 ），为空时自动判断
+	Encoding string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	// 字段分隔符，默认为 ,
+	Delimiter string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+	// 表头之前需要跳过的行数
+	SkipRows int `json:"skipRows,omitempty" yaml:"skipRows,omitempty"`
+	// 表尾需要跳过的行数（如合计行）
+	SkipFooterRows int `json:"skipFooterRows,omitempty" yaml:"skipFooterRows,omitempty"`
+	// 没有表头行，此时列只能用序号指定
+	NoHeader bool `json:"noHeader,omitempty" yaml:"noHeader,omitempty"`
+
+	// 各字段所在列
+	Columns GenericCSVColumns `json:"columns" yaml:"columns"`
+	// 日期格式（ Go 时间格式），为空时尝试常见格式
+	DateLayout string `json:"dateLayout,omitempty" yaml:"dateLayout,omitempty"`
+	// 小数点，默认为 .
+	DecimalSeparator string `json:"decimalSeparator,omitempty" yaml:"decimalSeparator,omitempty"`
+	// 千分位分隔符，默认为 , （小数点为 , 时默认为 . ）
+	ThousandsSeparator string `json:"thousandsSeparator,omitempty" yaml:"thousandsSeparator,omitempty"`
+	// 金额符号约定
+	Sign GenericCSVSign `json:"sign,omitempty" yaml:"sign,omitempty"`
+
+	// 托管机构，为空时使用导入选项中的值
+	Custodian string `json:"custodian,omitempty" yaml:"custodian,omitempty"`
+	// 货币，数据中没有币种列时使用，为空时使用导入选项中的值
+	Currency string `json:"currency,omitempty" yaml:"currency,omitempty"`
+	// 收入交易的默认原因，默认为“收入”
+	InflowReason string `json:"inflowReason,omitempty" yaml:"inflowReason,omitempty"`
+	// 支出交易的默认原因，默认为“支出”
+	OutflowReason string `json:"outflowReason,omitempty" yaml:"outflowReason,omitempty"`
+	// 根据说明等字段调整交易的规则，按顺序匹配第一条
+	Rules []GenericCSVRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// GenericCSVColumns 通用 CSV 各字段所在列
+type GenericCSVColumns struct {
+	// 日期（必须）
+	Date CSVColumn `json:"date" yaml:"date"`
+	// 带符号的金额，与 Income 、 Expense 二选一
+	Amount CSVColumn `json:"amount,omitempty" yaml:"amount,omitempty"`
+	// 收入金额
+	Income CSVColumn `json:"income,omitempty" yaml:"income,omitempty"`
+	// 支出金额
+	Expense CSVColumn `json:"expense,omitempty" yaml:"expense,omitempty"`
+	// 收/支方向，指定时 Amount 取绝对值，方向由 Sign.Inflow 和 Sign.Outflow 判断
+	Direction CSVColumn `json:"direction,omitempty" yaml:"direction,omitempty"`
+	// 币种
+	Currency CSVColumn `json:"currency,omitempty" yaml:"currency,omitempty"`
+	// 说明，规则默认匹配该列
+	Description CSVColumn `json:"description,omitempty" yaml:"description,omitempty"`
+	// 规则中指定商品时该商品的数量，为空时商品数量记为零并在备注中标记份额待确认
+	Quantity CSVColumn `json:"quantity,omitempty" yaml:"quantity,omitempty"`
+	// 交易 ID
+	ID CSVColumn `json:"id,omitempty" yaml:"id,omitempty"`
+	// 备注，多列以空格连接（说明列总是在最前）
+	Comment []CSVColumn `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// GenericCSVSign 金额符号约定
+type GenericCSVSign struct {
+	// 金额为正时表示支出（如信用卡账单）
+	OutflowPositive bool `json:"outflowPositive,omitempty" yaml:"outflowPositive,omitempty"`
+	// 收/支方向列中表示收入的值
+	Inflow []string `json:"inflow,omitempty" yaml:"inflow,omitempty"`
+	// 收/支方向列中表示支出的值
+	Outflow []string `json:"outflow,omitempty" yaml:"outflow,omitempty"`
+}
+
+// GenericCSVRule 通用 CSV 规则
+type GenericCSVRule struct {
+	// 匹配的正则表达式
+	Match string `json:"match" yaml:"match"`
+	// 匹配的列，默认为说明列
+	Column CSVColumn `json:"column,omitempty" yaml:"column,omitempty"`
+	// 只匹配指定方向的交易（ inflow 、 outflow ），为空时都匹配
+	Direction string `json:"direction,omitempty" yaml:"direction,omitempty"`
+	// 跳过匹配的记录
+	Skip bool `json:"skip,omitempty" yaml:"skip,omitempty"`
+	// 交易的另一方商品，指定时交易为货币与该商品的交换（如买入基金）
+	Goods string `json:"goods,omitempty" yaml:"goods,omitempty"`
+	// 商品的托管机构，为空时与货币相同，货币的托管机构始终为账单的托管机构
+	Custodian string `json:"custodian,omitempty" yaml:"custodian,omitempty"`
+	// 交易原因
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// CSVColumn CSV 列
+//
+// YAML 中为字符串时表示列名，为整数时表示从 0 开始的列序号
+type CSVColumn struct {
+	Name  string
+	Index int
+	isSet bool
+}
+
+// IsSet 返回是否指定了列
+func (c CSVColumn) IsSet() bool {
+	return c.isSet
+}
+
+// String 返回列的描述
+func (c CSVColumn) String() string {
+	if c.Name != "" {
+		return fmt.Sprintf("%q", c.Name)
+	}
+	return fmt.Sprintf("#%d", c.Index)
+}
+
+// UnmarshalYAML 从 YAML 反序列化
+func (c *CSVColumn) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: column must be a name or an index", value.Line)
+	}
+	*c = CSVColumn{isSet: true}
+	if value.Tag == "!!int" {
+		if err := value.Decode(&c.Index); err != nil {
+			return err
+		}
+		if c.Index < 0 {
+			return fmt.Errorf("line %d: column index must not be negative", value.Line)
+		}
+		return nil
+	}
+	c.Name = value.Value
+	return nil
+}
+
+// MarshalYAML 序列化为 YAML
+func (c CSVColumn) MarshalYAML() (interface{}, error) {
+	if !c.isSet {
+		return nil, nil
+	}
+	if c.Name != "" {
+		return c.Name, nil
+	}
+	return c.Index, nil
+}
+
+// LoadGenericCSVMapping 从文件加载通用 CSV 映射配置
+func LoadGenericCSVMapping(path string) (*GenericCSVMapping, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mapping file %q error: %w", path, err)
+	}
+	mapping := &GenericCSVMapping{}
+	if err := yaml.Unmarshal(raw, mapping); err != nil {
+		return nil, fmt.Errorf("unmarshal mapping file %q error: %w", path, err)
+	}
+	if err := mapping.Complete(); err != nil {
+		return nil, fmt.Errorf("invalid mapping file %q: %w", path, err)
+	}
+	return mapping, nil
+}
+
+// Complete 校验配置并补全默认值
+func (m *GenericCSVMapping) Complete() error {
+	if !m.Columns.Date.IsSet() {
+		return fmt.Errorf("columns.date is required")
+	}
+	if !m.Columns.Amount.IsSet() && (!m.Columns.Income.IsSet() || !m.Columns.Expense.IsSet()) {
+		return fmt.Errorf("columns.amount or both columns.income and columns.expense are required")
+	}
+	if m.Columns.Direction.IsSet() && (len(m.Sign.Inflow) == 0 || len(m.Sign.Outflow) == 0) {
+		return fmt.Errorf("sign.inflow and sign.outflow are required when columns.direction is set")
+	}
+	if m.SkipRows < 0 || m.SkipFooterRows < 0 {
+		return fmt.Errorf("skipRows and skipFooterRows must not be negative")
+	}
+	if m.Delimiter == "" {
+		m.Delimiter = ","
+	}
+	if utf8.RuneCountInString(m.Delimiter) != 1 {
+		return fmt.Errorf("delimiter must be a single character, got %q", m.Delimiter)
+	}
+	if m.DecimalSeparator == "" {
+		m.DecimalSeparator = "."
+	}
+	if m.ThousandsSeparator == "" && m.DecimalSeparator != "," {
+		m.ThousandsSeparator = ","
+	} else if m.ThousandsSeparator == "" {
+		m.ThousandsSeparator = "."
+	}
+	if m.ThousandsSeparator == m.DecimalSeparator {
+		return fmt.Errorf("decimalSeparator and thousandsSeparator must be different")
+	}
+	if m.InflowReason == "" {
+		m.InflowReason = reasonIncome
+	}
+	if m.OutflowReason == "" {
+		m.OutflowReason = reasonExpense
+	}
+
+	for i := range m.Rules {
+		rule := &m.Rules[i]
+		pattern, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return fmt.Errorf("compile rules[%d].match %q error: %w", i, rule.Match, err)
+		}
+		rule.pattern = pattern
+		switch rule.Direction {
+		case "", genericCSVInflow, genericCSVOutflow:
+		default:
+			return fmt.Errorf("rules[%d].direction must be %q or %q", i, genericCSVInflow, genericCSVOutflow)
+		}
+		if !rule.Column.IsSet() {
+			if !m.Columns.Description.IsSet() {
+				return fmt.Errorf("rules[%d].column is required when columns.description is not set", i)
+			}
+			rule.Column = m.Columns.Description
+		}
+	}
+	if m.NoHeader {
+		for _, c := range m.columns() {
+			if c.IsSet() && c.Name != "" {
+				return fmt.Errorf("column %s must be an index when noHeader is set", c)
+			}
+		}
+	}
+	return nil
+}
+
+// columns 返回配置中用到的所有列
+func (m *GenericCSVMapping) columns() []CSVColumn {
+	columns := []CSVColumn{
+		m.Columns.Date, m.Columns.Amount, m.Columns.Income, m.Columns.Expense, m.Columns.Direction,
+		m.Columns.Currency, m.Columns.Description, m.Columns.Quantity, m.Columns.ID,
+	}
+	columns = append(columns, m.Columns.Comment...)
+	for _, rule := range m.Rules {
+		columns = append(columns, rule.Column)
+	}
+	return columns
+}
+
+const (
+	genericCSVInflow  = "inflow"
+	genericCSVOutflow = "outflow"
+)
+
+// ImportGenericCSV 根据映射配置导入 CSV
+//
+// 每条记录转换为托管机构的货币的收入或支出；规则中指定商品时转换为货币与该商品的交换
+func ImportGenericCSV(ctx context.Context, r io.Reader, mapping *GenericCSVMapping, opts Options) (*v1.Assets, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read data error: %w", err)
+	}
+	text, err := decodeText(raw, mapping.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	csvR := csv.NewReader(bytes.NewReader(text))
+	csvR.Comma, _ = utf8.DecodeRuneInString(mapping.Delimiter)
+	csvR.FieldsPerRecord = -1
+	csvR.LazyQuotes = true
+	csvR.TrimLeadingSpace = true
+	rows, err := csvR.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv error: %w", err)
+	}
+
+	// 去掉表头前和表尾的行
+	if mapping.SkipRows >= len(rows) {
+		return &v1.Assets{}, nil
+	}
+	rows = rows[mapping.SkipRows:]
+	var header []string
+	firstLine := mapping.SkipRows + 1
+	if !mapping.NoHeader {
+		header = rows[0]
+		rows = rows[1:]
+		firstLine++
+	}
+	if mapping.SkipFooterRows >= len(rows) {
+		return &v1.Assets{}, nil
+	}
+	rows = rows[:len(rows)-mapping.SkipFooterRows]
+
+	c := &genericCSVConverter{
+		mapping:   mapping,
+		custodian: mapping.Custodian,
+		currency:  mapping.Currency,
+		index:     map[string]int{},
+		goods:     newGoodsSet(),
+	}
+	if c.custodian == "" {
+		c.custodian = opts.Custodian
+	}
+	if c.custodian == "" {
+		c.custodian = genericCSVDefaultCustodian
+	}
+	if c.currency == "" {
+		c.currency = opts.Currency
+	}
+	if c.currency == "" {
+		c.currency = genericCSVDefaultCurrency
+	}
+	for i, name := range header {
+		c.index[strings.TrimSpace(name)] = i
+	}
+	if err := c.checkColumns(); err != nil {
+		return nil, fmt.Errorf("%w, header: %v", err, header)
+	}
+
+	var transactions []v1.Transaction
+	for i, row := range rows {
+		t, ok, err := c.convert(logger, row)
+		if err != nil {
+			return nil, fmt.Errorf("convert line %d error: %w", firstLine+i, err)
+		}
+		if !ok {
+			logger.V(1).Info(fmt.Sprintf("skip line %d: %v", firstLine+i, row))
+			continue
+		}
+		transactions = append(transactions, t)
+	}
+
+	// 按时间倒序导出的数据需要反转
+	if n := len(transactions); n > 1 && transactions[0].Date.After(transactions[n-1].Date.Time) {
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			transactions[i], transactions[j] = transactions[j], transactions[i]
+		}
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.Before(transactions[j].Date.Time)
+	})
+
+	return &v1.Assets{
+		Goods:        c.goods.List(),
+		Transactions: transactions,
+	}, nil
+}
+
+// genericCSVConverter 将 CSV 行转换为交易
+type genericCSVConverter struct {
+	mapping   *GenericCSVMapping
+	custodian string
+	currency  string
+	// 列名到列序号
+	index map[string]int
+	goods *goodsSet
+}
+
+// checkColumns 检查映射中的列名是否都存在于表头
+func (c *genericCSVConverter) checkColumns() error {
+	if c.mapping.NoHeader {
+		return nil
+	}
+	for _, col := range c.mapping.columns() {
+		if col.Name == "" {
+			continue
+		}
+		if _, ok := c.index[col.Name]; !ok {
+			return fmt.Errorf("column %s not found in header", col)
+		}
+	}
+	return nil
+}
+
+// get 获取行中指定列的值
+func (c *genericCSVConverter) get(row []string, col CSVColumn) string {
+	if !col.IsSet() {
+		return ""
+	}
+	i := col.Index
+	if col.Name != "" {
+		i = c.index[col.Name]
+	}
+	if i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// convert 转换一行，返回 false 表示忽略该行
+func (c *genericCSVConverter) convert(logger logr.Logger, row []string) (v1.Transaction, bool, error) {
+	m := c.mapping
+
+	dateStr := c.get(row, m.Columns.Date)
+	if dateStr == "" {
+		// 空行
+		return v1.Transaction{}, false, nil
+	}
+	date, err := c.parseDate(dateStr)
+	if err != nil {
+		return v1.Transaction{}, false, err
+	}
+
+	// 金额，收入为正，支出为负
+	var amount decimal.Decimal
+	if m.Columns.Amount.IsSet() {
+		amount, err = c.parseAmount(c.get(row, m.Columns.Amount))
+		if err != nil {
+			return v1.Transaction{}, false, fmt.Errorf("parse amount error: %w", err)
+		}
+		if m.Sign.OutflowPositive {
+			amount = amount.Neg()
+		}
+	} else {
+		income, err := c.parseAmount(c.get(row, m.Columns.Income))
+		if err != nil {
+			return v1.Transaction{}, false, fmt.Errorf("parse income error: %w", err)
+		}
+		expense, err := c.parseAmount(c.get(row, m.Columns.Expense))
+		if err != nil {
+			return v1.Transaction{}, false, fmt.Errorf("parse expense error: %w", err)
+		}
+		amount = income.Abs().Sub(expense.Abs())
+	}
+	if m.Columns.Direction.IsSet() {
+		direction := c.get(row, m.Columns.Direction)
+		switch {
+		case containsString(m.Sign.Inflow, direction):
+			amount = amount.Abs()
+		case containsString(m.Sign.Outflow, direction):
+			amount = amount.Abs().Neg()
+		default:
+			// 不计收支的记录
+			return v1.Transaction{}, false, nil
+		}
+	}
+	if amount.IsZero() {
+		return v1.Transaction{}, false, nil
+	}
+	direction := genericCSVInflow
+	if amount.IsNegative() {
+		direction = genericCSVOutflow
+	}
+
+	currency := c.currency
+	if v := c.get(row, m.Columns.Currency); v != "" {
+		currency = currencyCode(v)
+	}
+	comments := []string{c.get(row, m.Columns.Description)}
+	for _, col := range m.Columns.Comment {
+		comments = append(comments, c.get(row, col))
+	}
+	t := v1.Transaction{
+		Date:    v1.Date{Time: date},
+		Comment: strings.Join(strings.Fields(strings.Join(comments, " ")), " "),
+		ID:      c.get(row, m.Columns.ID),
+	}
+
+	// 应用规则
+	var rule *GenericCSVRule
+	for i := range m.Rules {
+		if m.Rules[i].Direction != "" && m.Rules[i].Direction != direction {
+			continue
+		}
+		if m.Rules[i].pattern.MatchString(c.get(row, m.Rules[i].Column)) {
+			rule = &m.Rules[i]
+			break
+		}
+	}
+	if rule != nil {
+		if rule.Skip {
+			return v1.Transaction{}, false, nil
+		}
+		t.Reason = rule.Reason
+	}
+
+	c.goods.Add(v1.GoodsInfo{Name: currency, Base: true})
+	cash := &v1.Goods{Quantity: amount.Abs(), Name: currency, Custodian: c.custodian}
+
+	if rule != nil && rule.Goods != "" {
+		// 货币与商品的交换
+		quantity, err := c.parseAmount(c.get(row, m.Columns.Quantity))
+		if err != nil {
+			return v1.Transaction{}, false, fmt.Errorf("parse quantity error: %w", err)
+		}
+		custodian := c.custodian
+		if rule.Custodian != "" {
+			custodian = rule.Custodian
+		}
+		c.goods.Add(v1.GoodsInfo{Name: rule.Goods})
+		goods := &v1.Goods{Quantity: quantity.Abs(), Name: rule.Goods, Custodian: custodian}
+		trade := tradeTransaction(logger, direction == genericCSVOutflow, cash, goods, t.Comment)
+		trade.Date, trade.ID = t.Date, t.ID
+		if t.Reason != "" {
+			trade.Reason = t.Reason
+		}
+		return trade, true, nil
+	}
+
+	if direction == genericCSVOutflow {
+		t.From = cash
+		if t.Reason == "" {
+			t.Reason = m.OutflowReason
+		}
+	} else {
+		t.To = cash
+		if t.Reason == "" {
+			t.Reason = m.InflowReason
+		}
+	}
+	return t, true, nil
+}
+
+// parseDate 按配置的格式解析日期
+func (c *genericCSVConverter) parseDate(s string) (time.Time, error) {
+	if c.mapping.DateLayout == "" {
+		return parseDate(s)
+	}
+	t, err := time.Parse(c.mapping.DateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse date %q with layout %q error: %w", s, c.mapping.DateLayout, err)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// parseAmount 按配置的小数点和千分位分隔符解析金额
+//
+// 支持括号和后置负号表示的负数，如 (12.00) 、 12.00-
+func (c *genericCSVConverter) parseAmount(s string) (decimal.Decimal, error) {
+	s = strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	if strings.HasSuffix(s, "-") {
+		negative = !negative
+		s = strings.TrimSpace(strings.TrimSuffix(s, "-"))
+	}
+	s = strings.ReplaceAll(s, c.mapping.ThousandsSeparator, "")
+	if c.mapping.DecimalSeparator != "." {
+		s = strings.ReplaceAll(s, c.mapping.DecimalSeparator, ".")
+	}
+	d, err := parseAmount(s)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if negative {
+		d = d.Neg()
+	}
+	return d, nil
+}
+
+// containsString 判断 items 中是否包含 s
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package imports
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestImportGenericCSV 测试 ImportGenericCSV 方法
+func TestImportGenericCSV(t *testing.T) {
+	mappingYAML := `
+delimiter: ";"
+skipRows: 2
+skipFooterRows: 1
+columns:
+  date: Buchungstag
+  amount: Betrag
+  description: Verwendungszweck
+  quantity: 4
+  comment: [Empfänger]
+dateLayout: "02.01.2006"
+decimalSeparator: ","
+custodian: DKB
+currency: EUR
+rules:
+  - match: ^Umbuchung
+    skip: true
+  - match: ETF
+    direction: outflow
+    goods: VWCE
+    custodian: Broker
+  - match: Zinsen
+    reason: 利息
+`
+	data := `Kontostand;1.234,56
+Zeitraum;2024
+Buchungstag;Empfänger;Verwendungszweck;Betrag;Anteile
+04.01.2024;;Sparplan ETF;-50,00;
+03.01.2024;Shop;Einkauf;-1.000,50;
+02.01.2024;;Umbuchung;100,00;
+02.01.2024;;Sparplan ETF;(200,00);1,5
+01.01.2024;Bank;Zinsen;3,21;
+Summe;;;-1.097,29;
+`
+	mapping := &GenericCSVMapping{}
+	if err := yaml.Unmarshal([]byte(mappingYAML), mapping); err != nil {
+		t.Fatalf("unmarshal mapping error: %v", err)
+	}
+	if err := mapping.Complete(); err != nil {
+		t.Fatalf("complete mapping error: %v", err)
+	}
+
+	ret, err := ImportGenericCSV(context.Background(), strings.NewReader(data), mapping, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, tx := range ret.Transactions {
+		got = append(got, strings.Join([]string{
+			tx.Date.String(), goodsString(tx.From), goodsString(tx.To), tx.Reason, tx.Comment,
+		}, "|"))
+	}
+	expected := []string{
+		"2024-01-01|<nil>|3.21 EUR (DKB)|利息|Zinsen Bank",
+		"2024-01-02|200 EUR (DKB)|1.5 VWCE (Broker)|买入|Sparplan ETF",
+		"2024-01-03|1000.5 EUR (DKB)|<nil>|支出|Einkauf Shop",
+		// 份额未知时记录现金并标记份额待确认
+		"2024-01-04|50 EUR (DKB)|0 VWCE (Broker)|买入|份额待确认: Sparplan ETF",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected transactions:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+	if len(ret.Goods) != 2 {
+		t.Errorf("unexpected goods count: %d (expected: 2)", len(ret.Goods))
+	}
+}
+
+// goodsString 返回商品的描述
+func goodsString(g *v1.Goods) string {
+	if g == nil {
+		return "<nil>"
+	}
+	return g.Quantity.String() + " " + g.Name + " (" + g.Custodian + ")"
+}
@@ -0,0 +1,538 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const (
+	ibkrDefaultCustodian = "IBKR"
+
+	ibkrRecordTrade           = "Trade"
+	ibkrRecordCashTransaction = "CashTransaction"
+	ibkrRecordCorporateAction = "CorporateAction"
+)
+
+// ibkrFieldAliases IBKR Flex Query CSV 列名到 XML 属性名的映射（均为规范化后的名称）
+var ibkrFieldAliases = map[string]string{
+	"currencyprimary": "currency",
+	"assetclass":      "assetcategory",
+}
+
+func init() {
+	Register(ibkrImporter{})
+}
+
+// ibkrImporter Interactive Brokers Flex Query 导入器
+type ibkrImporter struct{}
+
+var _ Importer = ibkrImporter{}
+
+// Name 返回导入器名
+func (ibkrImporter) Name() string {
+	return "ibkr"
+}
+
+// Description 返回导入器描述
+func (ibkrImporter) Description() string {
+	return "Interactive Brokers Flex Query statement (XML or CSV)"
+}
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+func (ibkrImporter) Detect(data []byte) bool {
+	if bytes.Contains(data, []byte("<FlexQueryResponse")) {
+		return true
+	}
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	return bytes.Contains(firstLine, []byte("ClientAccountID")) || bytes.HasPrefix(firstLine, []byte(`"BOF"`))
+}
+
+// Import 导入数据
+func (ibkrImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	return ImportIBKR(ctx, r, opts)
+}
+
+// ibkrRecord IBKR Flex Query 记录
+type ibkrRecord struct {
+	// 记录类型
+	Type string
+	// 字段，键为规范化后的字段名
+	Fields map[string]string
+}
+
+// Get 获取字段值
+func (r ibkrRecord) Get(name string) string {
+	return strings.TrimSpace(r.Fields[normalizeIBKRFieldName(name)])
+}
+
+// Decimal 获取数值字段，字段为空时返回 0
+func (r ibkrRecord) Decimal(name string) (decimal.Decimal, error) {
+	v := strings.ReplaceAll(r.Get(name), ",", "")
+	if v == "" || v == "--" {
+		return decimal.Zero, nil
+	}
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("parse %s %q error: %w", name, v, err)
+	}
+	return d, nil
+}
+
+// Date 获取日期，依次尝试 names 中的字段
+func (r ibkrRecord) Date(names ...string) (v1.Date, error) {
+	for _, name := range names {
+		v := r.Get(name)
+		if v == "" {
+			continue
+		}
+		// 兼容 20240304 、 2024-03-04 、 20240304;103000 、 2024-03-04, 10:30:00 等格式
+		digits := strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, v)
+		if len(digits) < 8 {
+			return v1.Date{}, fmt.Errorf("invalid %s: %q", name, v)
+		}
+		t, err := time.Parse("20060102", digits[:8])
+		if err != nil {
+			return v1.Date{}, fmt.Errorf("parse %s %q error: %w", name, v, err)
+		}
+		return v1.Date{Time: t}, nil
+	}
+	return v1.Date{}, fmt.Errorf("date not found in %s record", r.Type)
+}
+
+// ImportIBKR 导入 Interactive Brokers Flex Query 报表
+//
+// 支持 XML 和 CSV 格式，导入交易（含换汇）、股息、预扣税、费用、利息、出入金和公司行动
+func ImportIBKR(_ context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read data error: %w", err)
+	}
+
+	var records []ibkrRecord
+	if bytes.Contains(raw, []byte("<FlexQueryResponse")) {
+		records, err = readIBKRXML(raw)
+	} else {
+		records, err = readIBKRCSV(raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	custodian := opts.Custodian
+	if custodian == "" {
+		custodian = ibkrDefaultCustodian
+	}
+	c := &ibkrConverter{custodian: custodian, goods: newGoodsSet()}
+	for i, record := range records {
+		if err := c.Convert(record); err != nil {
+			return nil, fmt.Errorf("convert %s record %d error: %w", record.Type, i+1, err)
+		}
+	}
+	sort.SliceStable(c.transactions, func(i, j int) bool {
+		return c.transactions[i].Date.Before(c.transactions[j].Date.Time)
+	})
+
+	return &v1.Assets{
+		Goods:        c.goods.List(),
+		Transactions: c.transactions,
+	}, nil
+}
+
+// readIBKRXML 读取 XML 格式的 Flex Query 报表
+func readIBKRXML(raw []byte) ([]ibkrRecord, error) {
+	dec := xml.NewDecoder(bytes.NewReader(raw))
+	var ret []ibkrRecord
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read xml error: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case ibkrRecordTrade, ibkrRecordCashTransaction, ibkrRecordCorporateAction:
+		default:
+			continue
+		}
+		record := ibkrRecord{Type: start.Name.Local, Fields: make(map[string]string, len(start.Attr))}
+		for _, attr := range start.Attr {
+			record.Fields[normalizeIBKRFieldName(attr.Name.Local)] = attr.Value
+		}
+		ret = append(ret, record)
+	}
+	return ret, nil
+}
+
+// readIBKRCSV 读取 CSV 格式的 Flex Query 报表
+//
+// 报表可能包含多个段落，每个段落以表头行开始，根据表头中的字段判断段落中记录的类型
+func readIBKRCSV(raw []byte) ([]ibkrRecord, error) {
+	csvR := csv.NewReader(bytes.NewReader(raw))
+	csvR.FieldsPerRecord = -1
+	csvR.LazyQuotes = true
+	rows, err := csvR.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv error: %w", err)
+	}
+
+	var ret []ibkrRecord
+	var header []string
+	recordType := ""
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		switch row[0] {
+		case "BOF", "EOF", "BOA", "EOA", "BOS", "EOS", "BOT", "EOT":
+			// 报表头尾等标记行
+			continue
+		}
+		if isIBKRCSVHeader(row) {
+			header = make([]string, len(row))
+			fields := map[string]bool{}
+			for i, name := range row {
+				header[i] = normalizeIBKRFieldName(name)
+				fields[header[i]] = true
+			}
+			switch {
+			case fields["buysell"]:
+				recordType = ibkrRecordTrade
+			case fields["actionid"]:
+				recordType = ibkrRecordCorporateAction
+			case fields["amount"] && fields["type"]:
+				recordType = ibkrRecordCashTransaction
+			default:
+				recordType = ""
+			}
+			continue
+		}
+		if recordType == "" {
+			continue
+		}
+		record := ibkrRecord{Type: recordType, Fields: make(map[string]string, len(header))}
+		for i, name := range header {
+			if i < len(row) {
+				record.Fields[name] = row[i]
+			}
+		}
+		ret = append(ret, record)
+	}
+	return ret, nil
+}
+
+// isIBKRCSVHeader 判断是否 CSV 表头行
+func isIBKRCSVHeader(row []string) bool {
+	for _, col := range row {
+		switch normalizeIBKRFieldName(col) {
+		case "clientaccountid", "currencyprimary", "assetclass":
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeIBKRFieldName 规范化字段名（转小写并去掉非字母数字字符）
+func normalizeIBKRFieldName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+	if alias, ok := ibkrFieldAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// ibkrConverter 将 Flex Query 记录转换为交易
+type ibkrConverter struct {
+	custodian    string
+	goods        *goodsSet
+	transactions []v1.Transaction
+}
+
+// Convert 转换一条记录
+func (c *ibkrConverter) Convert(record ibkrRecord) error {
+	switch record.Type {
+	case ibkrRecordTrade:
+		return c.convertTrade(record)
+	case ibkrRecordCashTransaction:
+		return c.convertCashTransaction(record)
+	case ibkrRecordCorporateAction:
+		return c.convertCorporateAction(record)
+	}
+	return nil
+}
+
+// goodsOf 返回托管在 IBKR 的商品
+func (c *ibkrConverter) goodsOf(name string, quantity decimal.Decimal) *v1.Goods {
+	return &v1.Goods{Quantity: quantity, Name: name, Custodian: c.custodian}
+}
+
+// addSecurity 记录证券信息
+func (c *ibkrConverter) addSecurity(record ibkrRecord) string {
+	symbol := record.Get("symbol")
+	c.goods.Add(v1.GoodsInfo{Name: symbol, Code: record.Get("isin")})
+	return symbol
+}
+
+// addCurrency 记录货币信息
+func (c *ibkrConverter) addCurrency(currency string) string {
+	c.goods.Add(v1.GoodsInfo{Name: currency, Base: true})
+	return currency
+}
+
+// convertTrade 转换交易记录
+func (c *ibkrConverter) convertTrade(record ibkrRecord) error {
+	if detail := record.Get("levelOfDetail"); detail != "" && detail != "EXECUTION" {
+		// 忽略汇总记录
+		return nil
+	}
+
+	date, err := record.Date("tradeDate", "dateTime", "reportDate")
+	if err != nil {
+		return err
+	}
+	quantity, err := record.Decimal("quantity")
+	if err != nil {
+		return err
+	}
+	proceeds, err := record.Decimal("proceeds")
+	if err != nil {
+		return err
+	}
+	commission, err := record.Decimal("ibCommission")
+	if err != nil {
+		return err
+	}
+	price := record.Get("tradePrice")
+	currency := c.addCurrency(record.Get("currency"))
+	commissionCurrency := record.Get("ibCommissionCurrency")
+	if commissionCurrency == "" {
+		commissionCurrency = currency
+	}
+
+	// 确定交易物
+	var goods string
+	reason := ""
+	if record.Get("assetCategory") == "CASH" {
+		// 换汇，如 USD.HKD 表示以 HKD 买卖 USD
+		base, _, _ := strings.Cut(record.Get("symbol"), ".")
+		goods = c.addCurrency(base)
+		reason = reasonExchange
+	} else {
+		goods = c.addSecurity(record)
+	}
+
+	// 佣金与交易货币相同时计入交易金额，否则单独记录
+	amount := proceeds
+	if commissionCurrency == currency {
+		amount = amount.Add(commission)
+	} else if !commission.IsZero() {
+		c.addCurrency(commissionCurrency)
+		c.transactions = append(c.transactions, v1.Transaction{
+			Date:    date,
+			From:    c.goodsOf(commissionCurrency, commission.Neg()),
+			Reason:  reasonCommission,
+			Comment: fmt.Sprintf("commission of %s", record.Get("symbol")),
+			ID:      ibkrSubID(record, "commission"),
+		})
+	}
+
+	t := v1.Transaction{
+		Date:    date,
+		Reason:  reason,
+		Comment: fmt.Sprintf("price: %s, commission: %s %s", price, commission.Neg(), commissionCurrency),
+		ID:      ibkrID(record),
+	}
+	if quantity.IsNegative() {
+		t.From = c.goodsOf(goods, quantity.Neg())
+		t.To = c.goodsOf(currency, amount)
+	} else {
+		t.From = c.goodsOf(currency, amount.Neg())
+		t.To = c.goodsOf(goods, quantity)
+	}
+	c.transactions = append(c.transactions, t)
+	return nil
+}
+
+// convertCashTransaction 转换现金记录
+func (c *ibkrConverter) convertCashTransaction(record ibkrRecord) error {
+	if record.Get("levelOfDetail") == "SUMMARY" {
+		// 忽略汇总记录
+		return nil
+	}
+
+	date, err := record.Date("dateTime", "settleDate", "reportDate")
+	if err != nil {
+		return err
+	}
+	amount, err := record.Decimal("amount")
+	if err != nil {
+		return err
+	}
+	if amount.IsZero() {
+		return nil
+	}
+	currency := c.addCurrency(record.Get("currency"))
+	cashType := record.Get("type")
+	t := v1.Transaction{
+		Date:    date,
+		Comment: record.Get("description"),
+		ID:      ibkrID(record),
+	}
+
+	switch cashType {
+	case "Dividends", "Payment In Lieu Of Dividends":
+		// 股息记为证券的收益
+		symbol := c.addSecurity(record)
+		t.Reason = reasonInterestDividend
+		if amount.IsPositive() {
+			t.From = c.goodsOf(symbol, decimal.Zero)
+			t.To = c.goodsOf(currency, amount)
+		} else {
+			t.From = c.goodsOf(currency, amount.Neg())
+			t.To = c.goodsOf(symbol, decimal.Zero)
+		}
+	case "Withholding Tax":
+		// 预扣税记为证券的成本
+		t.Reason = reasonWithholdingTax
+		if symbol := record.Get("symbol"); symbol != "" {
+			c.addSecurity(record)
+			if amount.IsNegative() {
+				t.From = c.goodsOf(currency, amount.Neg())
+				t.To = c.goodsOf(symbol, decimal.Zero)
+			} else {
+				t.From = c.goodsOf(symbol, decimal.Zero)
+				t.To = c.goodsOf(currency, amount)
+			}
+			break
+		}
+		c.setCashFlow(&t, currency, amount)
+	default:
+		switch cashType {
+		case "Deposits/Withdrawals", "Deposits & Withdrawals":
+			t.Reason = reasonDeposit
+			if amount.IsNegative() {
+				t.Reason = reasonWithdrawal
+			}
+		case "Broker Interest Received", "Broker Interest Paid", "Bond Interest Received", "Bond Interest Paid":
+			t.Reason = reasonInterestDividend
+		case "Other Fees", "Commission Adjustments", "Advisor Fees":
+			t.Reason = reasonFee
+		default:
+			t.Reason = cashType
+		}
+		c.setCashFlow(&t, currency, amount)
+	}
+
+	c.transactions = append(c.transactions, t)
+	return nil
+}
+
+// setCashFlow 将交易设置为单边的现金流入或流出
+func (c *ibkrConverter) setCashFlow(t *v1.Transaction, currency string, amount decimal.Decimal) {
+	if amount.IsNegative() {
+		t.From = c.goodsOf(currency, amount.Neg())
+	} else {
+		t.To = c.goodsOf(currency, amount)
+	}
+}
+
+// convertCorporateAction 转换公司行动记录
+func (c *ibkrConverter) convertCorporateAction(record ibkrRecord) error {
+	if detail := record.Get("levelOfDetail"); detail != "" && detail != "DETAIL" {
+		// 忽略汇总记录
+		return nil
+	}
+
+	date, err := record.Date("reportDate", "dateTime")
+	if err != nil {
+		return err
+	}
+	quantity, err := record.Decimal("quantity")
+	if err != nil {
+		return err
+	}
+	proceeds, err := record.Decimal("proceeds")
+	if err != nil {
+		return err
+	}
+	if quantity.IsZero() && proceeds.IsZero() {
+		return nil
+	}
+	symbol := c.addSecurity(record)
+	t := v1.Transaction{
+		Date:    date,
+		Reason:  reasonCorporateAction,
+		Comment: record.Get("description"),
+		ID:      ibkrID(record),
+	}
+
+	switch {
+	case quantity.IsNegative() && proceeds.IsPositive():
+		// 以现金收购等
+		currency := c.addCurrency(record.Get("currency"))
+		t.From = c.goodsOf(symbol, quantity.Neg())
+		t.To = c.goodsOf(currency, proceeds)
+	case quantity.IsPositive() && proceeds.IsNegative():
+		// 配股等
+		currency := c.addCurrency(record.Get("currency"))
+		t.From = c.goodsOf(currency, proceeds.Neg())
+		t.To = c.goodsOf(symbol, quantity)
+	case quantity.IsNegative():
+		// 合股、注销等，以负数量的目标商品减少持仓，不计入成本
+		t.From = c.goodsOf(symbol, decimal.Zero)
+		t.To = c.goodsOf(symbol, quantity)
+	default:
+		// 拆股、送股等
+		t.From = c.goodsOf(symbol, decimal.Zero)
+		t.To = c.goodsOf(symbol, quantity)
+	}
+
+	c.transactions = append(c.transactions, t)
+	return nil
+}
+
+// ibkrID 返回记录的外部编号
+func ibkrID(record ibkrRecord) string {
+	for _, name := range []string{"transactionID", "tradeID", "actionID"} {
+		if id := record.Get(name); id != "" {
+			return "IBKR-" + id
+		}
+	}
+	return ""
+}
+
+// ibkrSubID 返回从记录派生的交易的外部编号，记录没有编号时返回空
+func ibkrSubID(record ibkrRecord, suffix string) string {
+	id := ibkrID(record)
+	if id == "" {
+		return ""
+	}
+	return id + "-" + suffix
+}
@@ -0,0 +1,90 @@
+package imports
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const ibkrTestXML = `<FlexQueryResponse queryName="test" type="AF">
+<FlexStatements count="1">
+<FlexStatement accountId="U1234567" fromDate="20240101" toDate="20241231">
+<Trades>
+<Trade currency="USD" assetCategory="STK" symbol="AAPL" isin="US0378331005" tradeDate="20240304" quantity="10" tradePrice="150" proceeds="-1500" ibCommission="-1" ibCommissionCurrency="USD" buySell="BUY" transactionID="1001" levelOfDetail="EXECUTION" />
+<Trade currency="HKD" assetCategory="CASH" symbol="USD.HKD" tradeDate="20240305" quantity="-1000" tradePrice="7.8" proceeds="7800" ibCommission="-2" ibCommissionCurrency="USD" buySell="SELL" transactionID="1002" levelOfDetail="EXECUTION" />
+<Trade currency="HKD" assetCategory="CASH" symbol="USD.HKD" tradeDate="20240306" quantity="-100" tradePrice="7.8" proceeds="780" ibCommission="-2" ibCommissionCurrency="USD" buySell="SELL" levelOfDetail="EXECUTION" />
+</Trades>
+<CorporateActions>
+<CorporateAction currency="USD" assetCategory="STK" symbol="AAPL" isin="US0378331005" reportDate="20240610" quantity="-9" proceeds="0" description="AAPL SPLIT 1 FOR 10" actionID="3001" levelOfDetail="DETAIL" />
+</CorporateActions>
+<CashTransactions>
+<CashTransaction currency="USD" symbol="AAPL" isin="US0378331005" dateTime="20240515;202000" amount="2.4" type="Dividends" description="AAPL CASH DIVIDEND" transactionID="2001" levelOfDetail="DETAIL" />
+<CashTransaction currency="USD" symbol="AAPL" isin="US0378331005" dateTime="20240515;202000" amount="-0.24" type="Withholding Tax" description="AAPL US TAX" transactionID="2002" levelOfDetail="DETAIL" />
+<CashTransaction currency="USD" dateTime="20240603;202000" amount="1.5" type="Broker Interest Received" description="USD CREDIT INT FOR MAY-2024" transactionID="2003" levelOfDetail="DETAIL" />
+</CashTransactions>
+</FlexStatement>
+</FlexStatements>
+</FlexQueryResponse>`
+
+// TestImportIBKR 测试 ImportIBKR 方法
+func TestImportIBKR(t *testing.T) {
+	ret, err := ImportIBKR(context.Background(), strings.NewReader(ibkrTestXML), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		date     string
+		from, to string
+		reason   string
+	}{
+		{"2024-03-04", "1501 USD", "10 AAPL", ""},
+		{"2024-03-05", "2 USD", "", "手续费"},
+		{"2024-03-05", "1000 USD", "7800 HKD", "换汇"},
+		{"2024-03-06", "2 USD", "", "手续费"},
+		{"2024-03-06", "100 USD", "780 HKD", "换汇"},
+		{"2024-05-15", "0 AAPL", "2.4 USD", "利息分红"},
+		{"2024-05-15", "0.24 USD", "0 AAPL", "预扣税"},
+		{"2024-06-03", "", "1.5 USD", "利息分红"},
+		// 合股不计入成本
+		{"2024-06-10", "0 AAPL", "-9 AAPL", "公司行动"},
+	}
+	if len(ret.Transactions) != len(expected) {
+		t.Fatalf("unexpected transactions: %v (expected %d transactions)", ret.Transactions, len(expected))
+	}
+	goods := func(g *v1.Goods) string {
+		if g == nil {
+			return ""
+		}
+		if g.Custodian != "IBKR" {
+			t.Errorf("unexpected custodian: %q (expected: \"IBKR\")", g.Custodian)
+		}
+		return g.Quantity.String() + " " + g.Name
+	}
+	for i, e := range expected {
+		tx := ret.Transactions[i]
+		if tx.Date.String() != e.date || goods(tx.From) != e.from || goods(tx.To) != e.to || tx.Reason != e.reason {
+			t.Errorf(
+				"unexpected transaction %d: %s %q -> %q %q (expected: %s %q -> %q %q)",
+				i, tx.Date, goods(tx.From), goods(tx.To), tx.Reason, e.date, e.from, e.to, e.reason,
+			)
+		}
+	}
+
+	for i, id := range []string{"IBKR-1001", "IBKR-1002-commission", "IBKR-1002", "", ""} {
+		if ret.Transactions[i].ID != id {
+			t.Errorf("unexpected id of transaction %d: %q (expected: %q)", i, ret.Transactions[i].ID, id)
+		}
+	}
+
+	if len(ret.Goods) != 3 {
+		t.Errorf("unexpected goods: %v (expected: USD, AAPL and HKD)", ret.Goods)
+	}
+	for _, g := range ret.Goods {
+		if g.Name == "AAPL" && g.Code != "US0378331005" {
+			t.Errorf("unexpected code of AAPL: %q (expected: \"US0378331005\")", g.Code)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const icbcDefaultCustodian = "工商银行"
+
+// icbcColumns 工商银行流水各字段可能的列名
+var icbcColumns = bankColumns{
+	Date:        []string{"交易日期", "记账日期"},
+	Income:      []string{"记账金额(收入)", "记账金额（收入）", "交易金额(收入)"},
+	Expense:     []string{"记账金额(支出)", "记账金额（支出）", "交易金额(支出)"},
+	Amount:      []string{"发生额"},
+	Balance:     []string{"余额", "账户余额"},
+	Currency:    []string{"记账币种", "币种"},
+	Type:        []string{"摘要"},
+	Description: []string{"对方户名", "交易详情", "交易场所"},
+}
+
+func init() {
+	Register(icbcImporter{})
+}
+
+// icbcImporter 工商银行流水导入器
+type icbcImporter struct{}
+
+var _ Importer = icbcImporter{}
+
+// Name 返回导入器名
+func (icbcImporter) Name() string {
+	return "icbc"
+}
+
+// Description 返回导入器描述
+func (icbcImporter) Description() string {
+	return "Industrial and Commercial Bank of China (工商银行) statement CSV"
+}
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+func (icbcImporter) Detect(data []byte) bool {
+	text, err := decodeText(data, "")
+	if err != nil {
+		return false
+	}
+	if !bytes.Contains(text, []byte("工商银行")) && !bytes.Contains(text, []byte("记账金额")) {
+		return false
+	}
+	_, ok := skipPreamble(text, "交易日期", "摘要", "余额")
+	return ok
+}
+
+// Import 导入数据
+func (icbcImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	return ImportICBC(ctx, r, opts)
+}
+
+// ImportICBC 导入工商银行流水
+//
+// 收入和支出转换为托管在银行的货币的存入和支取，利息单独标记，并核对流水余额
+func ImportICBC(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read data error: %w", err)
+	}
+	text, err := decodeText(raw, "")
+	if err != nil {
+		return nil, err
+	}
+	table, ok := skipPreamble(text, "交易日期", "摘要", "余额")
+	if !ok {
+		return nil, fmt.Errorf("statement header not found")
+	}
+	currency := opts.Currency
+	if currency == "" {
+		currency = currencyFromPreamble(text[:len(text)-len(table)], "CNY")
+	}
+	records, err := readBankStatement(table, icbcColumns, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	custodian := opts.Custodian
+	if custodian == "" {
+		custodian = icbcDefaultCustodian
+	}
+	s := &bankStatement{
+		Custodian: custodian,
+		Records:   records,
+		IsInterest: func(record bankStatementRecord) bool {
+			return strings.Contains(record.Type, "利息") || strings.Contains(record.Type, "结息")
+		},
+	}
+	return s.Convert(ctx)
+}
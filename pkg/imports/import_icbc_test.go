@@ -0,0 +1,47 @@
+package imports
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// icbcTestCSV 工商银行流水（按时间倒序，收入和支出分列）
+const icbcTestCSV = "\xEF\xBB\xBF" + `中国工商银行借记账户历史明细
+交易日期,摘要,交易详情,交易场所,交易国家或地区简称,钞/汇,交易金额(收入),交易金额(支出),交易币种,记账金额(收入),记账金额(支出),记账币种,余额,对方户名
+2024-01-20,工资,,,,,"8,000.00",,人民币,"8,000.00",,人民币,"9,500.00",某公司
+2024-01-10,消费,超市,,,,,500.00,人民币,,500.00,人民币,"1,500.00",超市
+`
+
+// TestImportICBC 测试 ImportICBC 方法
+func TestImportICBC(t *testing.T) {
+	if !(icbcImporter{}).Detect([]byte(icbcTestCSV)) {
+		t.Errorf("icbc statement not detected")
+	}
+
+	ret, err := ImportICBC(context.Background(), strings.NewReader(icbcTestCSV), Options{Custodian: "ICBC"})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	expected := []string{
+		"2024-01-10 500 CNY(ICBC) -> - 出金 ",
+		"2024-01-20 - -> 8000 CNY(ICBC) 入金 ",
+	}
+	if len(ret.Transactions) != len(expected) {
+		t.Fatalf("unexpected transactions: %v (expected %d transactions)", ret.Transactions, len(expected))
+	}
+	for i, e := range expected {
+		if got := transactionString(ret.Transactions[i]); got != e {
+			t.Errorf("unexpected transaction %d: %q (expected: %q)", i, got, e)
+		}
+	}
+	if ret.Transactions[1].Comment != "工资 某公司" {
+		t.Errorf("unexpected comment: %q", ret.Transactions[1].Comment)
+	}
+
+	// 倒序流水反转后核对余额
+	unbalanced := strings.Replace(icbcTestCSV, `"1,500.00"`, `"1,600.00"`, 1)
+	if _, err := ImportICBC(context.Background(), strings.NewReader(unbalanced), Options{}); err == nil {
+		t.Errorf("expected balance mismatch error")
+	}
+}
@@ -0,0 +1,516 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const ofxDefaultCustodian = "OFX"
+
+func init() {
+	Register(ofxImporter{})
+}
+
+// ofxImporter OFX/QFX 导入器
+type ofxImporter struct{}
+
+var _ Importer = ofxImporter{}
+
+// Name 返回导入器名
+func (ofxImporter) Name() string {
+	return "ofx"
+}
+
+// Description 返回导入器描述
+func (ofxImporter) Description() string {
+	return "Open Financial Exchange statement (OFX/QFX, SGML or XML)"
+}
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+func (ofxImporter) Detect(data []byte) bool {
+	return bytes.Contains(data, []byte("OFXHEADER")) || bytes.Contains(data, []byte("<OFX>"))
+}
+
+// Import 导入数据
+func (ofxImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	return ImportOFX(ctx, r, opts)
+}
+
+// ofxNode OFX 元素
+type ofxNode struct {
+	// 元素名
+	Name string
+	// 值（仅叶子元素）
+	Value string
+	// 子元素
+	Children []*ofxNode
+}
+
+// Child 返回第一个指定名称的子元素
+func (n *ofxNode) Child(name string) *ofxNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Get 返回指定路径的后代元素的值
+func (n *ofxNode) Get(path ...string) string {
+	cur := n
+	for _, name := range path {
+		cur = cur.Child(name)
+	}
+	if cur == nil {
+		return ""
+	}
+	return cur.Value
+}
+
+// FindAll 返回所有指定名称的后代元素
+func (n *ofxNode) FindAll(name string) []*ofxNode {
+	if n == nil {
+		return nil
+	}
+	var ret []*ofxNode
+	for _, c := range n.Children {
+		if c.Name == name {
+			ret = append(ret, c)
+			continue
+		}
+		ret = append(ret, c.FindAll(name)...)
+	}
+	return ret
+}
+
+// ofxTagPattern OFX 标签
+var ofxTagPattern = regexp.MustCompile(`<(/?)([A-Za-z0-9_.]+)>`)
+
+// parseOFX 解析 OFX 文档
+//
+// 同时兼容 OFX 1.x 的 SGML 格式（叶子元素没有结束标签）和 OFX 2.x 的 XML 格式
+func parseOFX(data []byte) (*ofxNode, error) {
+	start := bytes.Index(data, []byte("<OFX>"))
+	if start < 0 {
+		return nil, fmt.Errorf("<OFX> element not found")
+	}
+	data = data[start:]
+
+	root := &ofxNode{}
+	stack := []*ofxNode{root}
+	// 当前是否处于有值但尚未关闭的叶子元素中
+	openLeaf := false
+	matches := ofxTagPattern.FindAllSubmatchIndex(data, -1)
+	for i, m := range matches {
+		closing := m[3] > m[2]
+		name := strings.ToUpper(string(data[m[4]:m[5]]))
+		textEnd := len(data)
+		if i+1 < len(matches) {
+			textEnd = matches[i+1][0]
+		}
+		text := strings.TrimSpace(string(data[m[1]:textEnd]))
+
+		if openLeaf {
+			// SGML 格式的叶子元素以下一个标签作为结束
+			stack = stack[:len(stack)-1]
+			openLeaf = false
+			if closing && stack[len(stack)-1].Children[len(stack[len(stack)-1].Children)-1].Name == name {
+				// XML 格式的叶子元素结束标签
+				continue
+			}
+		}
+
+		if closing {
+			// 关闭到对应的元素
+			for j := len(stack) - 1; j > 0; j-- {
+				if stack[j].Name == name {
+					stack = stack[:j]
+					break
+				}
+			}
+			continue
+		}
+
+		node := &ofxNode{Name: name, Value: text}
+		parent := stack[len(stack)-1]
+		parent.Children = append(parent.Children, node)
+		stack = append(stack, node)
+		openLeaf = text != ""
+	}
+
+	if len(root.Children) == 0 {
+		return nil, fmt.Errorf("empty ofx document")
+	}
+	return root.Children[0], nil
+}
+
+// ofxDate 解析 OFX 日期，如 20240115120000.000[-5:EST]
+func ofxDate(s string) (v1.Date, error) {
+	if len(s) < 8 {
+		return v1.Date{}, fmt.Errorf("invalid date: %q", s)
+	}
+	t, err := time.Parse("20060102", s[:8])
+	if err != nil {
+		return v1.Date{}, fmt.Errorf("parse date %q error: %w", s, err)
+	}
+	return v1.Date{Time: t}, nil
+}
+
+// ofxDecimal 解析 OFX 数值，为空时返回 0
+func ofxDecimal(s string) (decimal.Decimal, error) {
+	// 部分机构使用逗号作为小数点
+	if strings.Contains(s, ",") && !strings.Contains(s, ".") {
+		s = strings.ReplaceAll(s, ",", ".")
+	}
+	return parseAmount(s)
+}
+
+// ImportOFX 导入 OFX/QFX 报表
+//
+// 支持银行、信用卡和投资账户报表，证券以 SECLIST 中的代码作为商品名，以证券编号（如 CUSIP ）作为商品代号
+func ImportOFX(_ context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read data error: %w", err)
+	}
+	doc, err := parseOFX(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse ofx error: %w", err)
+	}
+
+	custodian := opts.Custodian
+	if custodian == "" {
+		custodian = doc.Get("SIGNONMSGSRSV1", "SONRS", "FI", "ORG")
+	}
+	c := &ofxConverter{
+		custodian:       custodian,
+		defaultCurrency: opts.Currency,
+		goods:           newGoodsSet(),
+		securities:      map[string]v1.GoodsInfo{},
+	}
+	if c.defaultCurrency == "" {
+		c.defaultCurrency = "USD"
+	}
+
+	// 证券信息
+	for _, secInfo := range doc.FindAll("SECINFO") {
+		id := secInfo.Get("SECID", "UNIQUEID")
+		name := secInfo.Get("TICKER")
+		if name == "" {
+			name = secInfo.Get("SECNAME")
+		}
+		if name == "" {
+			name = id
+		}
+		c.securities[id] = v1.GoodsInfo{Name: name, Code: id}
+	}
+
+	// 银行和信用卡账户
+	for _, stmt := range append(doc.FindAll("STMTRS"), doc.FindAll("CCSTMTRS")...) {
+		if err := c.convertBankStatement(stmt); err != nil {
+			return nil, err
+		}
+	}
+	// 投资账户
+	for _, stmt := range doc.FindAll("INVSTMTRS") {
+		if err := c.convertInvestmentStatement(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(c.transactions, func(i, j int) bool {
+		return c.transactions[i].Date.Before(c.transactions[j].Date.Time)
+	})
+	return &v1.Assets{
+		Goods:        c.goods.List(),
+		Transactions: c.transactions,
+	}, nil
+}
+
+// ofxConverter 将 OFX 报表转换为交易
+type ofxConverter struct {
+	custodian       string
+	defaultCurrency string
+	goods           *goodsSet
+	securities      map[string]v1.GoodsInfo
+	transactions    []v1.Transaction
+
+	// 当前报表的托管机构和货币
+	stmtCustodian string
+	stmtCurrency  string
+}
+
+// begin 开始转换一个报表
+func (c *ofxConverter) begin(stmt *ofxNode, defaultCustodian string) {
+	c.stmtCurrency = stmt.Get("CURDEF")
+	if c.stmtCurrency == "" {
+		c.stmtCurrency = c.defaultCurrency
+	}
+	c.stmtCustodian = c.custodian
+	if c.stmtCustodian == "" {
+		c.stmtCustodian = defaultCustodian
+	}
+	if c.stmtCustodian == "" {
+		c.stmtCustodian = ofxDefaultCustodian
+	}
+}
+
+// currencyGoods 返回当前报表中的货币
+func (c *ofxConverter) currencyGoods(currency string, quantity decimal.Decimal) *v1.Goods {
+	if currency == "" {
+		currency = c.stmtCurrency
+	}
+	c.goods.Add(v1.GoodsInfo{Name: currency, Base: true})
+	return &v1.Goods{Quantity: quantity, Name: currency, Custodian: c.stmtCustodian}
+}
+
+// securityGoods 返回当前报表中的证券
+func (c *ofxConverter) securityGoods(id string, quantity decimal.Decimal) *v1.Goods {
+	info, ok := c.securities[id]
+	if !ok {
+		info = v1.GoodsInfo{Name: id, Code: id}
+	}
+	c.goods.Add(info)
+	return &v1.Goods{Quantity: quantity, Name: info.Name, Custodian: c.stmtCustodian}
+}
+
+// convertBankStatement 转换银行或信用卡报表
+func (c *ofxConverter) convertBankStatement(stmt *ofxNode) error {
+	c.begin(stmt, stmt.Get("BANKACCTFROM", "BANKID"))
+	for _, trn := range stmt.FindAll("STMTTRN") {
+		if err := c.convertBankTransaction(trn, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertBankTransaction 转换银行交易
+func (c *ofxConverter) convertBankTransaction(trn *ofxNode, currency string) error {
+	date, err := ofxDate(trn.Get("DTPOSTED"))
+	if err != nil {
+		return err
+	}
+	amount, err := ofxDecimal(trn.Get("TRNAMT"))
+	if err != nil {
+		return fmt.Errorf("parse TRNAMT of %s error: %w", trn.Get("FITID"), err)
+	}
+	if amount.IsZero() {
+		return nil
+	}
+	if cur := trn.Get("CURRENCY", "CURSYM"); cur != "" {
+		currency = cur
+	}
+
+	t := v1.Transaction{
+		Date:    date,
+		Comment: strings.TrimSpace(trn.Get("NAME") + " " + trn.Get("MEMO")),
+		ID:      trn.Get("FITID"),
+	}
+	switch trn.Get("TRNTYPE") {
+	case "INT":
+		t.Reason = reasonInterestDividend
+	case "DIV":
+		t.Reason = reasonInterestDividend
+	case "FEE", "SRVCHG":
+		t.Reason = reasonFee
+	default:
+		t.Reason = reasonDeposit
+		if amount.IsNegative() {
+			t.Reason = reasonWithdrawal
+		}
+	}
+	if amount.IsNegative() {
+		t.From = c.currencyGoods(currency, amount.Neg())
+	} else {
+		t.To = c.currencyGoods(currency, amount)
+	}
+	c.transactions = append(c.transactions, t)
+	return nil
+}
+
+// convertInvestmentStatement 转换投资账户报表
+func (c *ofxConverter) convertInvestmentStatement(stmt *ofxNode) error {
+	c.begin(stmt, stmt.Get("INVACCTFROM", "BROKERID"))
+	list := stmt.Child("INVTRANLIST")
+	if list == nil {
+		return nil
+	}
+	for _, trn := range list.Children {
+		var err error
+		switch trn.Name {
+		case "BUYSTOCK", "BUYMF", "BUYDEBT", "BUYOPT", "BUYOTHER":
+			err = c.convertBuy(trn.Child("INVBUY"))
+		case "SELLSTOCK", "SELLMF", "SELLDEBT", "SELLOPT", "SELLOTHER":
+			err = c.convertSell(trn.Child("INVSELL"))
+		case "INCOME":
+			err = c.convertIncome(trn)
+		case "REINVEST":
+			err = c.convertReinvest(trn)
+		case "TRANSFER":
+			err = c.convertTransfer(trn)
+		case "INVBANKTRAN":
+			err = c.convertBankTransaction(trn.Child("STMTTRN"), "")
+		}
+		if err != nil {
+			return fmt.Errorf("convert %s %s error: %w", trn.Name, trn.Get("INVTRAN", "FITID"), err)
+		}
+	}
+	return nil
+}
+
+// ofxInvestmentTransaction 投资交易的公共字段
+type ofxInvestmentTransaction struct {
+	Date     v1.Date
+	ID       string
+	Memo     string
+	Security string
+	Currency string
+	Units    decimal.Decimal
+	Price    string
+	Fees     decimal.Decimal
+	Total    decimal.Decimal
+}
+
+// parseInvestmentTransaction 解析投资交易的公共字段
+func parseInvestmentTransaction(n *ofxNode) (ofxInvestmentTransaction, error) {
+	if n == nil {
+		return ofxInvestmentTransaction{}, fmt.Errorf("transaction detail not found")
+	}
+	ret := ofxInvestmentTransaction{
+		ID:       n.Get("INVTRAN", "FITID"),
+		Memo:     n.Get("INVTRAN", "MEMO"),
+		Security: n.Get("SECID", "UNIQUEID"),
+		Currency: n.Get("CURRENCY", "CURSYM"),
+		Price:    n.Get("UNITPRICE"),
+	}
+	if ret.Currency == "" {
+		ret.Currency = n.Get("ORIGCURRENCY", "CURSYM")
+	}
+	var err error
+	if ret.Date, err = ofxDate(n.Get("INVTRAN", "DTTRADE")); err != nil {
+		return ret, err
+	}
+	if ret.Units, err = ofxDecimal(n.Get("UNITS")); err != nil {
+		return ret, fmt.Errorf("parse UNITS error: %w", err)
+	}
+	if ret.Total, err = ofxDecimal(n.Get("TOTAL")); err != nil {
+		return ret, fmt.Errorf("parse TOTAL error: %w", err)
+	}
+	for _, name := range []string{"COMMISSION", "FEES", "TAXES", "LOAD"} {
+		fee, err := ofxDecimal(n.Get(name))
+		if err != nil {
+			return ret, fmt.Errorf("parse %s error: %w", name, err)
+		}
+		ret.Fees = ret.Fees.Add(fee)
+	}
+	return ret, nil
+}
+
+// convertBuy 转换买入交易
+func (c *ofxConverter) convertBuy(n *ofxNode) error {
+	trn, err := parseInvestmentTransaction(n)
+	if err != nil {
+		return err
+	}
+	c.transactions = append(c.transactions, v1.Transaction{
+		Date:    trn.Date,
+		From:    c.currencyGoods(trn.Currency, trn.Total.Abs()),
+		To:      c.securityGoods(trn.Security, trn.Units.Abs()),
+		Comment: fmt.Sprintf("price: %s, fees: %s", trn.Price, trn.Fees),
+		ID:      trn.ID,
+	})
+	return nil
+}
+
+// convertSell 转换卖出交易
+func (c *ofxConverter) convertSell(n *ofxNode) error {
+	trn, err := parseInvestmentTransaction(n)
+	if err != nil {
+		return err
+	}
+	c.transactions = append(c.transactions, v1.Transaction{
+		Date:    trn.Date,
+		From:    c.securityGoods(trn.Security, trn.Units.Abs()),
+		To:      c.currencyGoods(trn.Currency, trn.Total.Abs()),
+		Comment: fmt.Sprintf("price: %s, fees: %s", trn.Price, trn.Fees),
+		ID:      trn.ID,
+	})
+	return nil
+}
+
+// convertIncome 转换股息、利息等收入
+func (c *ofxConverter) convertIncome(n *ofxNode) error {
+	trn, err := parseInvestmentTransaction(n)
+	if err != nil {
+		return err
+	}
+	t := v1.Transaction{
+		Date:    trn.Date,
+		Reason:  reasonInterestDividend,
+		Comment: strings.TrimSpace(n.Get("INCOMETYPE") + " " + trn.Memo),
+		ID:      trn.ID,
+	}
+	if trn.Total.IsNegative() {
+		t.From = c.currencyGoods(trn.Currency, trn.Total.Neg())
+		t.To = c.securityGoods(trn.Security, decimal.Zero)
+	} else {
+		t.From = c.securityGoods(trn.Security, decimal.Zero)
+		t.To = c.currencyGoods(trn.Currency, trn.Total)
+	}
+	c.transactions = append(c.transactions, t)
+	return nil
+}
+
+// convertReinvest 转换红利再投资
+func (c *ofxConverter) convertReinvest(n *ofxNode) error {
+	trn, err := parseInvestmentTransaction(n)
+	if err != nil {
+		return err
+	}
+	c.transactions = append(c.transactions, v1.Transaction{
+		Date:    trn.Date,
+		From:    c.securityGoods(trn.Security, decimal.Zero),
+		To:      c.securityGoods(trn.Security, trn.Units.Abs()),
+		Reason:  reasonInterestDividend,
+		Comment: fmt.Sprintf("reinvest %s %s, price: %s", n.Get("INCOMETYPE"), trn.Total.Abs(), trn.Price),
+		ID:      trn.ID,
+	})
+	return nil
+}
+
+// convertTransfer 转换证券转入转出
+func (c *ofxConverter) convertTransfer(n *ofxNode) error {
+	trn, err := parseInvestmentTransaction(n)
+	if err != nil {
+		return err
+	}
+	t := v1.Transaction{
+		Date:    trn.Date,
+		Comment: trn.Memo,
+		ID:      trn.ID,
+	}
+	if n.Get("TFERACTION") == "OUT" || trn.Units.IsNegative() {
+		t.Reason = reasonTransferOut
+		t.From = c.securityGoods(trn.Security, trn.Units.Abs())
+	} else {
+		t.Reason = reasonTransferIn
+		t.To = c.securityGoods(trn.Security, trn.Units.Abs())
+	}
+	c.transactions = append(c.transactions, t)
+	return nil
+}
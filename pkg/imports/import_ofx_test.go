@@ -0,0 +1,104 @@
+package imports
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestParseOFX 测试 parseOFX 方法
+func TestParseOFX(t *testing.T) {
+	cases := map[string]string{
+		"sgml": `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>USD
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240105<TRNAMT>-12.5<FITID>1<NAME>Coffee</STMTTRN>
+<STMTTRN><TRNTYPE>INT<DTPOSTED>20240131<TRNAMT>0.3<FITID>2</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`,
+		"xml": `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>USD</CURDEF>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20240105</DTPOSTED><TRNAMT>-12.5</TRNAMT><FITID>1</FITID><NAME>Coffee</NAME></STMTTRN>
+<STMTTRN><TRNTYPE>INT</TRNTYPE><DTPOSTED>20240131</DTPOSTED><TRNAMT>0.3</TRNAMT><FITID>2</FITID></STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`,
+	}
+
+	for name, data := range cases {
+		doc, err := parseOFX([]byte(data))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		stmt := doc.FindAll("STMTRS")
+		if len(stmt) != 1 {
+			t.Errorf("%s: unexpected STMTRS count: %d (expected: 1)", name, len(stmt))
+			continue
+		}
+		if cur := stmt[0].Get("CURDEF"); cur != "USD" {
+			t.Errorf("%s: unexpected CURDEF: %q (expected: \"USD\")", name, cur)
+		}
+		trns := stmt[0].FindAll("STMTTRN")
+		if len(trns) != 2 {
+			t.Errorf("%s: unexpected STMTTRN count: %d (expected: 2)", name, len(trns))
+			continue
+		}
+		if v := trns[0].Get("NAME"); v != "Coffee" {
+			t.Errorf("%s: unexpected NAME: %q (expected: \"Coffee\")", name, v)
+		}
+		if v := trns[1].Get("TRNAMT"); v != "0.3" {
+			t.Errorf("%s: unexpected TRNAMT: %q (expected: \"0.3\")", name, v)
+		}
+	}
+}
+
+// ofxTestInvestment OFX 投资账户测试数据
+const ofxTestInvestment = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><FI><ORG>Broker</ORG></FI></SONRS></SIGNONMSGSRSV1>
+<INVSTMTMSGSRSV1><INVSTMTTRNRS><INVSTMTRS><CURDEF>USD</CURDEF>
+<INVTRANLIST>
+<BUYSTOCK><INVBUY><INVTRAN><FITID>b1</FITID><DTTRADE>20240110</DTTRADE></INVTRAN><SECID><UNIQUEID>037833100</UNIQUEID></SECID>
+<UNITS>10</UNITS><UNITPRICE>100</UNITPRICE><COMMISSION>1</COMMISSION><TOTAL>-1001</TOTAL></INVBUY></BUYSTOCK>
+<SELLSTOCK><INVSELL><INVTRAN><FITID>s1</FITID><DTTRADE>20240120</DTTRADE></INVTRAN><SECID><UNIQUEID>037833100</UNIQUEID></SECID>
+<UNITS>-5</UNITS><UNITPRICE>120</UNITPRICE><TOTAL>600</TOTAL></INVSELL></SELLSTOCK>
+<INVBANKTRAN><STMTTRN><TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20240105</DTPOSTED><TRNAMT>2000</TRNAMT><FITID>d1</FITID></STMTTRN></INVBANKTRAN>
+</INVTRANLIST>
+</INVSTMTRS></INVSTMTTRNRS></INVSTMTMSGSRSV1>
+<SECLISTMSGSRSV1><SECLIST><STOCKINFO><SECINFO><SECID><UNIQUEID>037833100</UNIQUEID></SECID><SECNAME>Apple Inc</SECNAME><TICKER>AAPL</TICKER></SECINFO></STOCKINFO></SECLIST></SECLISTMSGSRSV1>
+</OFX>`
+
+// TestImportOFX 测试 ImportOFX 方法
+func TestImportOFX(t *testing.T) {
+	ret, err := ImportOFX(context.Background(), strings.NewReader(ofxTestInvestment), Options{})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	expected := []string{
+		"2024-01-05 - -> 2000 USD(Broker) 入金 d1",
+		"2024-01-10 1001 USD(Broker) -> 10 AAPL(Broker)  b1",
+		"2024-01-20 5 AAPL(Broker) -> 600 USD(Broker)  s1",
+	}
+	if len(ret.Transactions) != len(expected) {
+		t.Fatalf("unexpected transactions: %v (expected %d transactions)", ret.Transactions, len(expected))
+	}
+	for i, e := range expected {
+		if got := transactionString(ret.Transactions[i]); got != e {
+			t.Errorf("unexpected transaction %d: %q (expected: %q)", i, got, e)
+		}
+	}
+	for _, g := range ret.Goods {
+		if g.Name == "AAPL" && g.Code != "037833100" {
+			t.Errorf("unexpected AAPL code: %q (expected: \"037833100\")", g.Code)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const (
+	qifDefaultCustodian = "QIF"
+	qifDefaultCurrency  = "CNY"
+)
+
+func init() {
+	Register(qifImporter{})
+}
+
+// qifImporter QIF 导入器
+type qifImporter struct{}
+
+var _ Importer = qifImporter{}
+
+// Name 返回导入器名
+func (qifImporter) Name() string {
+	return "qif"
+}
+
+// Description 返回导入器描述
+func (qifImporter) Description() string {
+	return "Quicken Interchange Format (QIF) bank and investment accounts"
+}
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+func (qifImporter) Detect(data []byte) bool {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, utf8BOM))
+	return bytes.HasPrefix(data, []byte("!Type:")) ||
+		bytes.HasPrefix(data, []byte("!Account")) ||
+		bytes.HasPrefix(data, []byte("!Option"))
+}
+
+// Import 导入数据
+func (qifImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	return ImportQIF(ctx, r, opts)
+}
+
+// qifRecord QIF 记录，键为字段代码（行首字符）
+type qifRecord map[byte]string
+
+// ImportQIF 导入 QIF 文件
+//
+// 支持银行、现金、信用卡账户（ !Type:Bank 等）和投资账户（ !Type:Invst ）。 QIF 不包含货币信息，使用选项中指定的货币
+func ImportQIF(_ context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	c := &qifConverter{
+		custodian: opts.Custodian,
+		currency:  opts.Currency,
+		goods:     newGoodsSet(),
+	}
+	if c.currency == "" {
+		c.currency = qifDefaultCurrency
+	}
+
+	scanner := bufio.NewScanner(r)
+	section := ""
+	record := qifRecord{}
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, string(utf8BOM))
+		}
+		if line == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "!"):
+			if strings.HasPrefix(line, "!Type:") || line == "!Account" {
+				section = strings.TrimPrefix(line, "!")
+			}
+			record = qifRecord{}
+		case line == "^":
+			if err := c.convert(section, record); err != nil {
+				return nil, fmt.Errorf("convert record ending at line %d error: %w", lineNum, err)
+			}
+			record = qifRecord{}
+		default:
+			if _, ok := record[line[0]]; !ok {
+				// 拆分交易等重复字段只取第一个
+				record[line[0]] = line[1:]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read data error: %w", err)
+	}
+
+	sort.SliceStable(c.transactions, func(i, j int) bool {
+		return c.transactions[i].Date.Before(c.transactions[j].Date.Time)
+	})
+	return &v1.Assets{
+		Goods:        c.goods.List(),
+		Transactions: c.transactions,
+	}, nil
+}
+
+// qifConverter 将 QIF 记录转换为交易
+type qifConverter struct {
+	custodian    string
+	currency     string
+	account      string
+	goods        *goodsSet
+	transactions []v1.Transaction
+}
+
+// custodianName 返回托管机构
+func (c *qifConverter) custodianName() string {
+	switch {
+	case c.custodian != "":
+		return c.custodian
+	case c.account != "":
+		return c.account
+	}
+	return qifDefaultCustodian
+}
+
+// cash 返回货币
+func (c *qifConverter) cash(quantity decimal.Decimal) *v1.Goods {
+	c.goods.Add(v1.GoodsInfo{Name: c.currency, Base: true})
+	return &v1.Goods{Quantity: quantity, Name: c.currency, Custodian: c.custodianName()}
+}
+
+// security 返回证券
+func (c *qifConverter) security(name string, quantity decimal.Decimal) *v1.Goods {
+	c.goods.Add(v1.GoodsInfo{Name: name})
+	return &v1.Goods{Quantity: quantity, Name: name, Custodian: c.custodianName()}
+}
+
+// convert 转换一条记录
+func (c *qifConverter) convert(section string, record qifRecord) error {
+	if section == "Account" {
+		// 账户信息，作为后续交易的托管机构
+		c.account = strings.TrimSpace(record['N'])
+		return nil
+	}
+	if !strings.HasPrefix(section, "Type:") {
+		return nil
+	}
+	switch strings.TrimPrefix(section, "Type:") {
+	case "Bank", "Cash", "CCard", "Oth A", "Oth L":
+		return c.convertBank(record)
+	case "Invst":
+		return c.convertInvestment(record)
+	}
+	// 分类、类别等列表
+	return nil
+}
+
+// convertBank 转换银行交易
+func (c *qifConverter) convertBank(record qifRecord) error {
+	date, err := qifDate(record['D'])
+	if err != nil {
+		return err
+	}
+	amount, err := qifAmount(record, 'T', 'U')
+	if err != nil {
+		return err
+	}
+	if amount.IsZero() {
+		return nil
+	}
+
+	t := v1.Transaction{
+		Date:    date,
+		Reason:  strings.TrimSpace(record['L']),
+		Comment: strings.TrimSpace(record['P'] + " " + record['M']),
+		ID:      strings.TrimSpace(record['N']),
+	}
+	if amount.IsNegative() {
+		if t.Reason == "" {
+			t.Reason = reasonExpense
+		}
+		t.From = c.cash(amount.Neg())
+	} else {
+		if t.Reason == "" {
+			t.Reason = reasonIncome
+		}
+		t.To = c.cash(amount)
+	}
+	c.transactions = append(c.transactions, t)
+	return nil
+}
+
+// convertInvestment 转换投资交易
+func (c *qifConverter) convertInvestment(record qifRecord) error {
+	date, err := qifDate(record['D'])
+	if err != nil {
+		return err
+	}
+	amount, err := qifAmount(record, 'T', 'U')
+	if err != nil {
+		return err
+	}
+	amount = amount.Abs()
+	quantity, err := qifAmount(record, 'Q')
+	if err != nil {
+		return err
+	}
+	quantity = quantity.Abs()
+	security := strings.TrimSpace(record['Y'])
+	action := strings.TrimSpace(record['N'])
+
+	t := v1.Transaction{
+		Date:    date,
+		Comment: strings.TrimSpace(record['P'] + " " + record['M']),
+	}
+	if price := strings.TrimSpace(record['I']); price != "" {
+		t.Comment = strings.TrimSpace(fmt.Sprintf("price: %s, commission: %s %s", price, record['O'], t.Comment))
+	}
+
+	switch strings.TrimSuffix(action, "X") {
+	case "Buy", "CvrShrt":
+		t.From = c.cash(amount)
+		t.To = c.security(security, quantity)
+	case "Sell", "ShtSell":
+		t.From = c.security(security, quantity)
+		t.To = c.cash(amount)
+	case "Div", "IntInc", "CGLong", "CGShort", "CGMid", "RtrnCap", "MiscInc":
+		t.Reason = reasonInterestDividend
+		if security != "" {
+			t.From = c.security(security, decimal.Zero)
+		}
+		t.To = c.cash(amount)
+	case "ReinvDiv", "ReinvInt", "ReinvLg", "ReinvSh", "ReinvMd":
+		t.Reason = reasonInterestDividend
+		t.From = c.security(security, decimal.Zero)
+		t.To = c.security(security, quantity)
+	case "ShrsIn":
+		t.Reason = reasonTransferIn
+		t.To = c.security(security, quantity)
+	case "ShrsOut":
+		t.Reason = reasonTransferOut
+		t.From = c.security(security, quantity)
+	case "StkSplit":
+		// 拆股时 Q 为拆分比例，无法得到数量变化
+		t.Reason = reasonCorporateAction
+		t.From = c.security(security, decimal.Zero)
+		t.To = c.security(security, decimal.Zero)
+		t.Comment = strings.TrimSpace(fmt.Sprintf("split ratio %s, please adjust quantity %s", quantity, t.Comment))
+	case "MiscExp", "MargInt":
+		t.Reason = reasonFee
+		t.From = c.cash(amount)
+	case "XIn", "Cash", "Contrib":
+		t.Reason = reasonDeposit
+		t.To = c.cash(amount)
+	case "XOut", "Withdrw":
+		t.Reason = reasonWithdrawal
+		t.From = c.cash(amount)
+	default:
+		return fmt.Errorf("unsupported investment action: %q", action)
+	}
+	c.transactions = append(c.transactions, t)
+	return nil
+}
+
+// qifAmount 解析金额字段，依次尝试 codes 中的字段，都不存在时返回 0
+func qifAmount(record qifRecord, codes ...byte) (decimal.Decimal, error) {
+	for _, code := range codes {
+		v, ok := record[code]
+		if !ok {
+			continue
+		}
+		d, err := parseAmount(v)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("parse %c %q error: %w", code, v, err)
+		}
+		return d, nil
+	}
+	return decimal.Zero, nil
+}
+
+// qifDatePattern QIF 日期，如 1/15/2024 、 01/15'24 、 1-15-24
+var qifDatePattern = regexp.MustCompile(`^\s*(\d{1,2})[/.-](\d{1,2})\s*['/.-]\s*(\d{2,4})\s*$`)
+
+// qifDate 解析 QIF 日期
+//
+// 支持美国格式（月/日/年，两位年份中 ' 表示 2000 年之后）和 ISO 格式
+func qifDate(s string) (v1.Date, error) {
+	if t, err := time.Parse(time.DateOnly, strings.TrimSpace(s)); err == nil {
+		return v1.Date{Time: t}, nil
+	}
+	m := qifDatePattern.FindStringSubmatch(s)
+	if m == nil {
+		return v1.Date{}, fmt.Errorf("unrecognized date: %q", s)
+	}
+	month, _ := strconv.Atoi(m[1])
+	day, _ := strconv.Atoi(m[2])
+	year, _ := strconv.Atoi(m[3])
+	if len(m[3]) == 2 {
+		if strings.Contains(s, "'") || year < 70 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Month() != time.Month(month) || t.Day() != day {
+		return v1.Date{}, fmt.Errorf("invalid date: %q", s)
+	}
+	return v1.Date{Time: t}, nil
+}
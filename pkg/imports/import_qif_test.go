@@ -0,0 +1,93 @@
+package imports
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// qifTestData QIF 测试数据，包含银行账户和投资账户
+const qifTestData = `!Type:Bank
+D01/05/2024
+T-12.50
+PCoffee
+^
+D1/31'24
+T1000.00
+LSalary
+N101
+^
+!Account
+NBroker
+TInvst
+^
+!Type:Invst
+D01/10/2024
+NContribX
+T5000
+^
+D01/11/2024
+NBuy
+YAAPL
+I100
+Q10
+T1000
+^
+D01/20/2024
+NSellX
+YAAPL
+Q5
+T600
+^
+D01/25/2024
+NWithdrwX
+T200
+^
+`
+
+// TestImportQIF 测试 ImportQIF 方法
+func TestImportQIF(t *testing.T) {
+	if !(qifImporter{}).Detect([]byte(qifTestData)) {
+		t.Errorf("qif data not detected")
+	}
+
+	ret, err := ImportQIF(context.Background(), strings.NewReader(qifTestData), Options{})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	expected := []string{
+		"2024-01-05 12.5 CNY(QIF) -> - 支出 ",
+		"2024-01-10 - -> 5000 CNY(Broker) 入金 ",
+		"2024-01-11 1000 CNY(Broker) -> 10 AAPL(Broker)  ",
+		"2024-01-20 5 AAPL(Broker) -> 600 CNY(Broker)  ",
+		"2024-01-25 200 CNY(Broker) -> - 出金 ",
+		"2024-01-31 - -> 1000 CNY(QIF) Salary 101",
+	}
+	if len(ret.Transactions) != len(expected) {
+		t.Fatalf("unexpected transactions: %v (expected %d transactions)", ret.Transactions, len(expected))
+	}
+	for i, e := range expected {
+		if got := transactionString(ret.Transactions[i]); got != e {
+			t.Errorf("unexpected transaction %d: %q (expected: %q)", i, got, e)
+		}
+	}
+	if len(ret.Goods) != 2 {
+		t.Errorf("unexpected goods: %v (expected 2 goods)", ret.Goods)
+	}
+
+	// 指定托管机构和货币
+	ret, err = ImportQIF(context.Background(), strings.NewReader(qifTestData), Options{Custodian: "Bank", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	if got := transactionString(ret.Transactions[1]); got != "2024-01-10 - -> 5000 USD(Bank) 入金 " {
+		t.Errorf("unexpected transaction 1: %q", got)
+	}
+
+	// 不支持的操作
+	unsupported := strings.Replace(qifTestData, "NWithdrwX", "NUnknown", 1)
+	_, err = ImportQIF(context.Background(), strings.NewReader(unsupported), Options{})
+	if err == nil || !strings.Contains(err.Error(), `unsupported investment action: "Unknown"`) {
+		t.Errorf("expected unsupported action error, got: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+const wechatDefaultCustodian = "微信支付"
+
+// wechatColumns 微信支付账单各字段的列名
+var wechatColumns = billColumns{
+	Time:         []string{"交易时间"},
+	Category:     []string{"交易类型"},
+	Counterparty: []string{"交易对方"},
+	Description:  []string{"商品"},
+	Direction:    []string{"收/支"},
+	Amount:       []string{"金额(元)", "金额（元）"},
+	Method:       []string{"支付方式"},
+	Status:       []string{"当前状态"},
+	ID:           []string{"交易单号"},
+	Comment:      []string{"备注"},
+}
+
+func init() {
+	Register(wechatImporter{})
+}
+
+// wechatImporter 微信支付账单导入器
+type wechatImporter struct{}
+
+var _ Importer = wechatImporter{}
+
+// Name 返回导入器名
+func (wechatImporter) Name() string {
+	return "wechat"
+}
+
+// Description 返回导入器描述
+func (wechatImporter) Description() string {
+	return "WeChat Pay (微信支付) bill CSV"
+}
+
+// Detect 根据数据内容判断是否可以由该导入器导入
+func (wechatImporter) Detect(data []byte) bool {
+	text, err := decodeText(data, "")
+	if err != nil {
+		return false
+	}
+	if !bytes.Contains(text, []byte("微信")) {
+		return false
+	}
+	_, ok := skipPreamble(text, "交易时间", "交易单号")
+	return ok
+}
+
+// Import 导入数据
+func (wechatImporter) Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	return ImportWechat(ctx, r, opts)
+}
+
+// ImportWechat 导入微信支付账单
+//
+// 理财通基金收益记录转换为资产交易，基金买入和卖出记录转换为货币与基金的交换（没有份额时记为份额待确认），其它收入和支出记录转换为单边的收支交易
+func ImportWechat(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read data error: %w", err)
+	}
+	text, err := decodeText(raw, "")
+	if err != nil {
+		return nil, err
+	}
+	text, ok := skipPreamble(text, "交易时间", "交易单号")
+	if !ok {
+		return nil, fmt.Errorf("bill header not found")
+	}
+	records, err := readBill(text, wechatColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	custodian := opts.Custodian
+	if custodian == "" {
+		custodian = wechatDefaultCustodian
+	}
+	c := &billConverter{
+		custodian:     custodian,
+		walletMethods: map[string]bool{"零钱": true},
+		walletFunds:   map[string]bool{"零钱通": true},
+		isInvestment: func(record billRecord) bool {
+			return strings.Contains(record.Category, "理财通") || strings.HasPrefix(record.Description, "理财通")
+		},
+		isValidStatus: func(status string) bool {
+			return !strings.Contains(status, "已全额退款") && !strings.Contains(status, "失败") &&
+				!strings.Contains(status, "关闭")
+		},
+	}
+	return c.Convert(ctx, records)
+}
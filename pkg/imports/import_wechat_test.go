@@ -0,0 +1,45 @@
+package imports
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// wechatTestCSV 微信支付账单（按时间倒序）
+const wechatTestCSV = "\xEF\xBB\xBF" + `微信支付账单明细,,,,,,,,,,
+微信昵称：[test],,,,,,,,,,
+----------------------微信支付账单明细列表--------------------,,,,,,,,,,
+交易时间,交易类型,交易对方,商品,收/支,金额(元),支付方式,当前状态,交易单号,商户单号,备注
+2024-01-05 10:00:00,理财通,理财通,理财通-华夏收益债券-申购,支出,¥500.00,零钱,支付成功,W5,/,/
+2024-01-04 10:00:00,理财通,理财通,理财通-华夏收益发放,收入,¥0.50,零钱通,支付成功,W4,/,/
+2024-01-03 10:00:00,商户消费,奶茶店,奶茶,支出,¥15.00,零钱,已全额退款,W3,/,/
+2024-01-02 10:00:00,商户消费,超市,日用品,支出,"¥1,234.50",招商银行(1234),支付成功,W2,/,/
+2024-01-01 10:00:00,微信红包,李四,/,收入,¥66.00,/,已存入零钱,W1,/,/
+`
+
+// TestImportWechat 测试 ImportWechat 方法
+func TestImportWechat(t *testing.T) {
+	if !(wechatImporter{}).Detect([]byte(wechatTestCSV)) {
+		t.Errorf("wechat bill not detected")
+	}
+
+	ret, err := ImportWechat(context.Background(), strings.NewReader(wechatTestCSV), Options{Custodian: "WeChat"})
+	if err != nil {
+		t.Fatalf("import error: %v", err)
+	}
+	expected := []string{
+		"2024-01-01 - -> 66 CNY(WeChat) 微信红包 W1",
+		"2024-01-02 1234.5 CNY(招商银行) -> - 商户消费 W2",
+		"2024-01-04 0 华夏(WeChat) -> 0.5 华夏(WeChat) 利息分红 W4",
+		"2024-01-05 500 CNY(WeChat) -> 0 华夏收益债券(WeChat) 买入 W5",
+	}
+	if len(ret.Transactions) != len(expected) {
+		t.Fatalf("unexpected transactions: %v (expected %d transactions)", ret.Transactions, len(expected))
+	}
+	for i, e := range expected {
+		if got := transactionString(ret.Transactions[i]); got != e {
+			t.Errorf("unexpected transaction %d: %q (expected: %q)", i, got, e)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package imports
+
+import (
+	"context"
+	"io"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// Options 导入选项
+type Options struct {
+	// 托管机构，为空时使用导入器默认值
+	Custodian string
+	// 货币，用于数据中未注明货币的情况，为空时使用导入器默认值
+	Currency string
+	// 映射配置文件路径，用于 generic-csv 等需要额外配置的导入器
+	Mapping string
+}
+
+// Importer 导入器
+type Importer interface {
+	// Name 返回导入器名
+	Name() string
+	// Description 返回导入器描述
+	Description() string
+	// Detect 根据数据内容判断是否可以由该导入器导入
+	Detect(data []byte) bool
+	// Import 导入数据
+	//
+	// 返回的 v1.Assets 中包含导入的交易记录，以及交易涉及的商品信息
+	Import(ctx context.Context, r io.Reader, opts Options) (*v1.Assets, error)
+}
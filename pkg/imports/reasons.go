@@ -0,0 +1,33 @@
+package imports
+
+// 各导入器生成交易时使用的原因，相同性质的变动使用相同的原因，以便按原因统计和查询
+const (
+	// reasonIncome 收入
+	reasonIncome = "收入"
+	// reasonExpense 支出
+	reasonExpense = "支出"
+	// reasonDeposit 现金转入账户
+	reasonDeposit = "入金"
+	// reasonWithdrawal 现金转出账户
+	reasonWithdrawal = "出金"
+	// reasonInterestDividend 利息、股息和基金收益
+	reasonInterestDividend = "利息分红"
+	// reasonFee 费用
+	reasonFee = "费用"
+	// reasonCommission 交易佣金
+	reasonCommission = "手续费"
+	// reasonWithholdingTax 预扣税
+	reasonWithholdingTax = "预扣税"
+	// reasonBuy 买入
+	reasonBuy = "买入"
+	// reasonSell 卖出
+	reasonSell = "卖出"
+	// reasonExchange 换汇
+	reasonExchange = "换汇"
+	// reasonTransferIn 证券转入账户
+	reasonTransferIn = "转入"
+	// reasonTransferOut 证券转出账户
+	reasonTransferOut = "转出"
+	// reasonCorporateAction 公司行动
+	reasonCorporateAction = "公司行动"
+)
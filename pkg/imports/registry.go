@@ -0,0 +1,85 @@
+package imports
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]Importer{}
+)
+
+// Register 注册导入器，导入器名重复时 panic
+func Register(importer Importer) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	name := importer.Name()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("importer %q already registered", name))
+	}
+	registry[name] = importer
+}
+
+// Get 获取指定名称的导入器
+func Get(name string) (Importer, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	importer, ok := registry[name]
+	return importer, ok
+}
+
+// List 返回所有已注册的导入器（按名称排序）
+func List() []Importer {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	ret := make([]Importer, 0, len(registry))
+	for _, importer := range registry {
+		ret = append(ret, importer)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name() < ret[j].Name()
+	})
+	return ret
+}
+
+// Names 返回所有已注册的导入器名（按名称排序）
+func Names() []string {
+	importers := List()
+	ret := make([]string, len(importers))
+	for i, importer := range importers {
+		ret[i] = importer.Name()
+	}
+	return ret
+}
+
+// Detect 根据数据内容确定导入器
+//
+// 没有或有多个导入器可以导入该数据时返回错误
+func Detect(data []byte) (Importer, error) {
+	var matched []Importer
+	for _, importer := range List() {
+		if importer.Detect(data) {
+			matched = append(matched, importer)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("can not detect data type, please specify it with --type")
+	case 1:
+		return matched[0], nil
+	}
+	names := make([]string, len(matched))
+	for i, importer := range matched {
+		names[i] = importer.Name()
+	}
+	return nil, fmt.Errorf(
+		"data type is ambiguous (%s), please specify it with --type",
+		strings.Join(names, ", "),
+	)
+}
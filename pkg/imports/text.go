@@ -0,0 +1,97 @@
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// utf8BOM UTF-8 字节顺序标记
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// decodeText 将文本转换为 UTF-8 编码
+//
+// encoding 为空时自动判断：合法的 UTF-8 文本原样返回，否则按 GB18030 （兼容 GBK ）解码
+func decodeText(raw []byte, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", "auto":
+		if utf8.Valid(raw) {
+			return bytes.TrimPrefix(raw, utf8BOM), nil
+		}
+	case "utf-8", "utf8":
+		return bytes.TrimPrefix(raw, utf8BOM), nil
+	case "gbk", "gb2312", "gb18030":
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %q", encoding)
+	}
+	ret, err := simplifiedchinese.GB18030.NewDecoder().Bytes(raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode text as gb18030 error: %w", err)
+	}
+	return ret, nil
+}
+
+// skipPreamble 跳过表头之前的说明行，返回从表头行开始的内容
+//
+// 表头行为第一个包含 headerKeys 中所有关键字的行
+func skipPreamble(text []byte, headerKeys ...string) ([]byte, bool) {
+	rest := text
+	for len(rest) > 0 {
+		line, next, _ := bytes.Cut(rest, []byte("\n"))
+		matched := true
+		for _, key := range headerKeys {
+			if !bytes.Contains(line, []byte(key)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rest, true
+		}
+		rest = next
+	}
+	return nil, false
+}
+
+// parseAmount 解析金额，忽略货币符号、千分位分隔符和空白字符
+func parseAmount(s string) (decimal.Decimal, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '¥', '￥', '$', ',', ' ', '\t':
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(s)
+}
+
+// dateLayouts 支持的日期格式
+var dateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	time.DateOnly,
+	"2006/1/2",
+	"20060102",
+}
+
+// parseDate 解析日期（忽略时间部分）
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date: %q", s)
+}
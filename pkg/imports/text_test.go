@@ -0,0 +1,65 @@
+package imports
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// TestDecodeText 测试 decodeText 方法
+func TestDecodeText(t *testing.T) {
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte("交易时间,金额"))
+	if err != nil {
+		t.Fatalf("encode gbk error: %v", err)
+	}
+	for _, c := range []struct {
+		raw      []byte
+		encoding string
+		expected string
+	}{
+		{raw: []byte("\xEF\xBB\xBF交易时间,金额"), expected: "交易时间,金额"},
+		{raw: gbk, expected: "交易时间,金额"},
+		{raw: gbk, encoding: "GBK", expected: "交易时间,金额"},
+		{raw: []byte("ascii"), encoding: "gb18030", expected: "ascii"},
+	} {
+		ret, err := decodeText(c.raw, c.encoding)
+		if err != nil {
+			t.Errorf("decode %q as %q error: %v", c.raw, c.encoding, err)
+			continue
+		}
+		if string(ret) != c.expected {
+			t.Errorf("decode %q as %q: expected %q, got %q", c.raw, c.encoding, c.expected, ret)
+		}
+	}
+	if _, err := decodeText(gbk, "big5"); err == nil {
+		t.Errorf("expected error for unsupported encoding")
+	}
+}
+
+// TestSkipPreamble 测试 skipPreamble 方法
+func TestSkipPreamble(t *testing.T) {
+	ret, ok := skipPreamble([]byte("账单\n交易时间说明\n交易时间,金额\n2024-01-01,1\n"), "交易时间", "金额")
+	if !ok || string(ret) != "交易时间,金额\n2024-01-01,1\n" {
+		t.Errorf("unexpected result: %q, %t", ret, ok)
+	}
+	if _, ok := skipPreamble([]byte("a\nb\n"), "交易时间"); ok {
+		t.Errorf("expected header not found")
+	}
+}
+
+// TestParseAmount 测试 parseAmount 方法
+func TestParseAmount(t *testing.T) {
+	for s, expected := range map[string]string{
+		"¥1,234.50": "1234.5",
+		"-￥ 20":     "-20",
+		"":          "0",
+	} {
+		ret, err := parseAmount(s)
+		if err != nil || ret.String() != expected {
+			t.Errorf("parse %q: expected %s, got %s (%v)", s, expected, ret, err)
+		}
+	}
+	if _, err := parseAmount("abc"); err == nil {
+		t.Errorf("expected error for invalid amount")
+	}
+}
@@ -1,6 +1,10 @@
 package v1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
 	"github.com/shopspring/decimal"
 )
 
@@ -12,6 +16,8 @@ type Assets struct {
 	Transactions []Transaction `json:"transactions,omitempty" yaml:"transactions,omitempty"`
 	// 期中检查点
 	Checkpoints []Checkpoint `json:"checkpoints,omitempty" yaml:"checkpoints,omitempty"`
+	// 商品价格序列
+	Prices []PriceRecord `json:"prices,omitempty" yaml:"prices,omitempty"`
 }
 
 // Transaction 交易
@@ -26,6 +32,17 @@ type Transaction struct {
 	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
 	// 备注
 	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	// 外部编号（如券商成交编号、账单订单号）
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// Fingerprint 返回交易指纹
+//
+// 指纹由日期、源商品、目标商品及外部编号计算得到，指纹相同的交易视为重复交易
+func (t Transaction) Fingerprint() string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s|%s|%s|%s", t.Date, t.From.fingerprint(), t.To.fingerprint(), t.ID)
+	return hex.EncodeToString(h.Sum(nil))[:16]
 }
 
 // Goods 商品（交易物）
@@ -38,6 +55,14 @@ type Goods struct {
 	Custodian string `json:"custodian,omitempty" yaml:"custodian,omitempty"`
 }
 
+// fingerprint 返回用于计算交易指纹的商品表示
+func (g *Goods) fingerprint() string {
+	if g == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s", g.Custodian, g.Name, g.Quantity.String())
+}
+
 // GoodsInfo 商品信息
 type GoodsInfo struct {
 	// 商品名
@@ -75,6 +100,16 @@ type Checkpoint struct {
 	Goods []CheckpointGoodsInfo `json:"goods,omitempty" yaml:"goods,omitempty"`
 }
 
+// PriceRecord 商品在某日的价格
+type PriceRecord struct {
+	// 日期
+	Date Date `json:"date" yaml:"date"`
+	// 商品名
+	Name string `json:"name" yaml:"name"`
+	// 单价
+	Price decimal.Decimal `json:"price" yaml:"price"`
+}
+
 // CheckpointGoodsInfo 检查点商品信息
 type CheckpointGoodsInfo struct {
 	// 商品名
@@ -0,0 +1,244 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+// tokenKind 的可选值
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+// token 词法单元
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// String 返回词法单元的字符串形式
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q at %d", t.value, t.pos)
+}
+
+// ops 运算符，注意需要先匹配较长的运算符
+var ops = []Op{
+	OpNotEqual, OpGreaterOrEqual, OpLessOrEqual, OpNotContains,
+	OpEqual, OpGreater, OpLess, OpContains,
+}
+
+// tokenize 将表达式拆分为词法单元
+func tokenize(expr string) ([]token, error) {
+	var ret []token
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			ret = append(ret, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			ret = append(ret, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			b := strings.Builder{}
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			i++
+			ret = append(ret, token{kind: tokenString, value: b.String(), pos: start})
+		default:
+			if op, ok := opAt(runes, i); ok {
+				ret = append(ret, token{kind: tokenOp, value: string(op), pos: i})
+				i += len([]rune(op))
+				continue
+			}
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				if _, ok := opAt(runes, i); ok {
+					break
+				}
+				i++
+			}
+			ret = append(ret, token{kind: tokenWord, value: string(runes[start:i]), pos: start})
+		}
+	}
+	return append(ret, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// opAt 返回 runes[i:] 开头的运算符
+func opAt(runes []rune, i int) (Op, bool) {
+	for _, op := range ops {
+		opRunes := []rune(op)
+		if i+len(opRunes) <= len(runes) && string(runes[i:i+len(opRunes)]) == string(op) {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// Parse 解析查询表达式
+//
+// 表达式由形如 field op value 的比较组成，可用 and 、 or 、 not 和括号组合，相邻的比较之间默认为 and 。
+// op 可以是 = 、 != 、 > 、 >= 、 < 、 <= 、 ~ （包含）或 !~ （不包含），含空白的 value 需要用引号括起来。
+// 空表达式匹配所有记录
+func Parse(expr string, fields Fields) (Expr, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fields: fields}
+	if p.peek().kind == tokenEOF {
+		return All{}, nil
+	}
+	ret, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+	return ret, nil
+}
+
+// parser 查询表达式语法分析器
+type parser struct {
+	tokens []token
+	i      int
+	fields Fields
+}
+
+// peek 返回下一个词法单元
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+// next 返回并跳过下一个词法单元
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+// isKeyword 判断词法单元是否为指定关键字
+func isKeyword(t token, keywords ...string) bool {
+	if t.kind != tokenWord {
+		return false
+	}
+	for _, k := range keywords {
+		if strings.EqualFold(t.value, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOr 解析或表达式
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for isKeyword(p.peek(), "or", "||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd 解析与表达式
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case isKeyword(t, "and", "&&"):
+			p.next()
+		case t.kind == tokenWord && !isKeyword(t, "or", "||"), t.kind == tokenLParen:
+			// 相邻的表达式之间默认为 and
+		default:
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+}
+
+// parseNot 解析非表达式
+func (p *parser) parseNot() (Expr, error) {
+	if isKeyword(p.peek(), "not", "!") {
+		p.next()
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: e}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary 解析括号或比较表达式
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected \")\" but got %s", closing)
+		}
+		return e, nil
+	case tokenWord:
+		op := p.next()
+		if op.kind != tokenOp {
+			return nil, fmt.Errorf("expected operator after field %q but got %s", t.value, op)
+		}
+		value := p.next()
+		if value.kind != tokenWord && value.kind != tokenString {
+			return nil, fmt.Errorf("expected value after %q but got %s", t.value+op.value, value)
+		}
+		e, err := newComparison(p.fields, t.value, Op(op.value), value.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid comparison at %d: %w", t.pos, err)
+		}
+		return e, nil
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
@@ -0,0 +1,252 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// FieldKind 字段类型
+type FieldKind string
+
+// FieldKind 的可选值
+const (
+	FieldString FieldKind = "string"
+	FieldNumber FieldKind = "number"
+	FieldDate   FieldKind = "date"
+)
+
+// Fields 可查询的字段及其类型
+//
+// 以 "." 结尾的字段为前缀字段，如 "tag." 匹配 "tag.company"
+type Fields map[string]FieldKind
+
+// lookup 返回字段的规范名称和类型，字段名不区分大小写（前缀字段中前缀之后的部分除外）
+func (f Fields) lookup(name string) (string, FieldKind, bool) {
+	for field, kind := range f {
+		if !strings.HasSuffix(field, ".") && strings.EqualFold(field, name) {
+			return field, kind, true
+		}
+	}
+	for prefix, kind := range f {
+		if strings.HasSuffix(prefix, ".") && len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			return prefix + name[len(prefix):], kind, true
+		}
+	}
+	return "", "", false
+}
+
+// Names 返回排序后的字段名
+func (f Fields) Names() []string {
+	ret := make([]string, 0, len(f))
+	for name := range f {
+		if strings.HasSuffix(name, ".") {
+			name += "<key>"
+		}
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Record 可被查询的记录
+type Record interface {
+	// Values 返回字段的值，字段可以有多个值（如交易两侧的商品名），任一值满足条件即视为满足
+	Values(field string) []string
+}
+
+// Expr 查询表达式
+type Expr interface {
+	// Match 判断记录是否满足表达式
+	Match(r Record) bool
+	// String 返回表达式的字符串形式
+	String() string
+}
+
+// Op 比较运算符
+type Op string
+
+// Op 的可选值
+const (
+	OpEqual          Op = "="
+	OpNotEqual       Op = "!="
+	OpGreater        Op = ">"
+	OpGreaterOrEqual Op = ">="
+	OpLess           Op = "<"
+	OpLessOrEqual    Op = "<="
+	OpContains       Op = "~"
+	OpNotContains    Op = "!~"
+)
+
+// All 匹配所有记录的表达式
+type All struct{}
+
+var _ Expr = All{}
+
+// Match 判断记录是否满足表达式
+func (All) Match(Record) bool { return true }
+
+// String 返回表达式的字符串形式
+func (All) String() string { return "" }
+
+// And 与表达式
+type And struct {
+	Left, Right Expr
+}
+
+var _ Expr = And{}
+
+// Match 判断记录是否满足表达式
+func (e And) Match(r Record) bool { return e.Left.Match(r) && e.Right.Match(r) }
+
+// String 返回表达式的字符串形式
+func (e And) String() string { return fmt.Sprintf("(%s and %s)", e.Left, e.Right) }
+
+// Or 或表达式
+type Or struct {
+	Left, Right Expr
+}
+
+var _ Expr = Or{}
+
+// Match 判断记录是否满足表达式
+func (e Or) Match(r Record) bool { return e.Left.Match(r) || e.Right.Match(r) }
+
+// String 返回表达式的字符串形式
+func (e Or) String() string { return fmt.Sprintf("(%s or %s)", e.Left, e.Right) }
+
+// Not 非表达式
+type Not struct {
+	Expr Expr
+}
+
+var _ Expr = Not{}
+
+// Match 判断记录是否满足表达式
+func (e Not) Match(r Record) bool { return !e.Expr.Match(r) }
+
+// String 返回表达式的字符串形式
+func (e Not) String() string { return fmt.Sprintf("not %s", e.Expr) }
+
+// Comparison 比较表达式
+//
+// 日期字段按值的精度比较，如 date=2024 匹配 2024 年内的日期， date<2024-03 匹配 2024 年 3 月之前的日期；
+// 字符串字段的 ~ 和 !~ 为不区分大小写的包含匹配；数值字段按数值比较。
+// != 和 !~ 分别为 = 和 ~ 的否定，即字段的所有值都不满足时才匹配
+type Comparison struct {
+	Field string
+	Kind  FieldKind
+	Op    Op
+	Value string
+
+	number decimal.Decimal
+}
+
+var _ Expr = Comparison{}
+
+// Match 判断记录是否满足表达式
+func (e Comparison) Match(r Record) bool {
+	switch e.Op {
+	case OpNotEqual:
+		return !e.match(r, OpEqual)
+	case OpNotContains:
+		return !e.match(r, OpContains)
+	}
+	return e.match(r, e.Op)
+}
+
+// match 判断记录中字段的任一值是否满足比较条件
+func (e Comparison) match(r Record, op Op) bool {
+	for _, v := range r.Values(e.Field) {
+		var cmp int
+		switch e.Kind {
+		case FieldNumber:
+			d, err := decimal.NewFromString(v)
+			if err != nil {
+				continue
+			}
+			cmp = d.Cmp(e.number)
+		case FieldDate:
+			if len(v) > len(e.Value) {
+				v = v[:len(e.Value)]
+			}
+			cmp = strings.Compare(v, e.Value)
+		default:
+			if op == OpContains {
+				if strings.Contains(strings.ToLower(v), strings.ToLower(e.Value)) {
+					return true
+				}
+				continue
+			}
+			cmp = strings.Compare(v, e.Value)
+		}
+
+		var ok bool
+		switch op {
+		case OpEqual:
+			ok = cmp == 0
+		case OpGreater:
+			ok = cmp > 0
+		case OpGreaterOrEqual:
+			ok = cmp >= 0
+		case OpLess:
+			ok = cmp < 0
+		case OpLessOrEqual:
+			ok = cmp <= 0
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回表达式的字符串形式
+func (e Comparison) String() string {
+	return fmt.Sprintf("%s%s%q", e.Field, e.Op, e.Value)
+}
+
+// datePattern 日期值的格式
+var datePattern = regexp.MustCompile(`^\d{4}(-\d{2}(-\d{2})?)?$`)
+
+// newComparison 创建比较表达式并校验
+func newComparison(fields Fields, name string, op Op, value string) (Comparison, error) {
+	field, kind, ok := fields.lookup(name)
+	if !ok {
+		return Comparison{}, fmt.Errorf("unknown field: %q (expected: %s)", name, strings.Join(fields.Names(), ", "))
+	}
+	e := Comparison{Field: field, Kind: kind, Op: op, Value: value}
+	switch kind {
+	case FieldNumber:
+		if op == OpContains || op == OpNotContains {
+			return Comparison{}, fmt.Errorf("operator %q is not supported by number field %q", op, field)
+		}
+		d, err := decimal.NewFromString(value)
+		if err != nil {
+			return Comparison{}, fmt.Errorf("invalid number %q for field %q", value, field)
+		}
+		e.number = d
+	case FieldDate:
+		if op == OpContains || op == OpNotContains {
+			return Comparison{}, fmt.Errorf("operator %q is not supported by date field %q", op, field)
+		}
+		if !datePattern.MatchString(value) {
+			return Comparison{}, fmt.Errorf("invalid date %q for field %q (expected: YYYY, YYYY-MM or YYYY-MM-DD)", value, field)
+		}
+	}
+	return e, nil
+}
+
+// Filter 使用表达式过滤记录
+func Filter[T any](expr Expr, items []T, record func(T) Record) []T {
+	var ret []T
+	for _, item := range items {
+		if expr.Match(record(item)) {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
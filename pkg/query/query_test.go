@@ -0,0 +1,104 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TestParse 测试 Parse 方法
+func TestParse(t *testing.T) {
+	d, _ := time.Parse(time.DateOnly, "2024-03-29")
+	dividend := v1.Transaction{
+		Date:   v1.Date{Time: d},
+		From:   &v1.Goods{Name: "博时现金宝货币B", Custodian: "汇丰银行"},
+		To:     &v1.Goods{Quantity: decimal.RequireFromString("44.73"), Name: "博时现金宝货币B", Custodian: "汇丰银行"},
+		Reason: "利息分红",
+	}
+	deposit := v1.Transaction{
+		Date:    v1.Date{Time: d.AddDate(1, 0, 0)},
+		To:      &v1.Goods{Quantity: decimal.New(2000, 0), Name: "USD", Custodian: "Interactive Brokers"},
+		Reason:  "入金",
+		Comment: "Wire Transfer",
+	}
+
+	cases := []struct {
+		expr     string
+		dividend bool
+		deposit  bool
+	}{
+		{expr: "", dividend: true, deposit: true},
+		{expr: "custodian=汇丰银行 date=2024 reason=利息分红", dividend: true},
+		{expr: "date>2024", deposit: true},
+		{expr: "date<=2024-03", dividend: true},
+		{expr: "date>=2024-03-29 and date<2025-03-29", dividend: true},
+		{expr: "quantity>1000", deposit: true},
+		{expr: "from.quantity=0", dividend: true},
+		{expr: "from.name!=USD", dividend: true, deposit: true},
+		{expr: "name!=USD", dividend: true},
+		{expr: `custodian="Interactive Brokers"`, deposit: true},
+		{expr: "comment~wire", deposit: true},
+		{expr: "comment!~wire", dividend: true},
+		{expr: "not (reason=入金 or reason=出金)", dividend: true},
+		{expr: "REASON=入金 || Reason=利息分红", dividend: true, deposit: true},
+	}
+	for _, c := range cases {
+		expr, err := Parse(c.expr, TransactionFields)
+		if err != nil {
+			t.Errorf("parse %q error: %v", c.expr, err)
+			continue
+		}
+		if ok := expr.Match(NewTransactionRecord(dividend)); ok != c.dividend {
+			t.Errorf("%q (%s) match dividend: %t (expected: %t)", c.expr, expr, ok, c.dividend)
+		}
+		if ok := expr.Match(NewTransactionRecord(deposit)); ok != c.deposit {
+			t.Errorf("%q (%s) match deposit: %t (expected: %t)", c.expr, expr, ok, c.deposit)
+		}
+	}
+
+	for _, expr := range []string{
+		"foo=1",
+		"date=24",
+		"quantity~1",
+		"quantity>abc",
+		"(reason=入金",
+		"reason=",
+		"reason 入金",
+		`reason="入金`,
+		"tag.company=A",
+	} {
+		if _, err := Parse(expr, TransactionFields); err == nil {
+			t.Errorf("expected error when parsing %q", expr)
+		}
+	}
+}
+
+// TestIncomeRecord 测试查询收入项
+func TestIncomeRecord(t *testing.T) {
+	item := v1.IncomeItem{
+		Gross:          decimal.New(30000, 0),
+		InsuranceAndHF: decimal.New(4500, 0),
+		Tax:            decimal.New(1200, 0),
+		Tags:           map[string]string{"company": "A"},
+	}
+	for expr, expected := range map[string]bool{
+		"net=24300":        true,
+		"tag.company=A":    true,
+		"tag.Company=A":    false,
+		"tag=company:A":    true,
+		"tax>1200":         false,
+		"insuranceandhf>0": true,
+	} {
+		e, err := Parse(expr, IncomeFields)
+		if err != nil {
+			t.Errorf("parse %q error: %v", expr, err)
+			continue
+		}
+		if ok := e.Match(NewIncomeRecord(item)); ok != expected {
+			t.Errorf("%q match: %t (expected: %t)", expr, ok, expected)
+		}
+	}
+}
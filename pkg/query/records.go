@@ -0,0 +1,222 @@
+package query
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// TransactionFields 交易可查询的字段
+//
+// 不带 from. 或 to. 前缀的商品字段匹配交易任一侧的商品
+var TransactionFields = Fields{
+	"date":           FieldDate,
+	"name":           FieldString,
+	"from":           FieldString,
+	"to":             FieldString,
+	"from.name":      FieldString,
+	"to.name":        FieldString,
+	"custodian":      FieldString,
+	"from.custodian": FieldString,
+	"to.custodian":   FieldString,
+	"quantity":       FieldNumber,
+	"from.quantity":  FieldNumber,
+	"to.quantity":    FieldNumber,
+	"reason":         FieldString,
+	"comment":        FieldString,
+	"id":             FieldString,
+}
+
+// TransactionRecord 可被查询的交易
+type TransactionRecord v1.Transaction
+
+var _ Record = TransactionRecord{}
+
+// NewTransactionRecord 创建可被查询的交易
+func NewTransactionRecord(t v1.Transaction) Record {
+	return TransactionRecord(t)
+}
+
+// Values 返回字段的值
+func (t TransactionRecord) Values(field string) []string {
+	var sides []*v1.Goods
+	switch field {
+	case "from", "from.name", "from.custodian", "from.quantity":
+		sides = []*v1.Goods{t.From}
+	case "to", "to.name", "to.custodian", "to.quantity":
+		sides = []*v1.Goods{t.To}
+	case "name", "custodian", "quantity":
+		sides = []*v1.Goods{t.From, t.To}
+	case "date":
+		return []string{t.Date.String()}
+	case "reason":
+		return []string{t.Reason}
+	case "comment":
+		return []string{t.Comment}
+	case "id":
+		return []string{t.ID}
+	default:
+		return nil
+	}
+
+	var ret []string
+	for _, g := range sides {
+		if g == nil {
+			continue
+		}
+		switch field {
+		case "from", "to", "from.name", "to.name", "name":
+			ret = append(ret, g.Name)
+		case "from.custodian", "to.custodian", "custodian":
+			ret = append(ret, g.Custodian)
+		case "from.quantity", "to.quantity", "quantity":
+			ret = append(ret, g.Quantity.String())
+		}
+	}
+	return ret
+}
+
+// IncomeFields 收入可查询的字段
+//
+// tag.<key> 匹配指定标签的值， tag 匹配 key:value 形式的任一标签
+var IncomeFields = Fields{
+	"date":                  FieldDate,
+	"gross":                 FieldNumber,
+	"insuranceAndHF":        FieldNumber,
+	"tax":                   FieldNumber,
+	"net":                   FieldNumber,
+	"consumptionProportion": FieldNumber,
+	"tag":                   FieldString,
+	"tag.":                  FieldString,
+	"comment":               FieldString,
+}
+
+// IncomeRecord 可被查询的收入项
+type IncomeRecord v1.IncomeItem
+
+var _ Record = IncomeRecord{}
+
+// NewIncomeRecord 创建可被查询的收入项
+func NewIncomeRecord(item v1.IncomeItem) Record {
+	return IncomeRecord(item)
+}
+
+// Values 返回字段的值
+func (item IncomeRecord) Values(field string) []string {
+	switch field {
+	case "date":
+		return []string{item.Date.String()}
+	case "gross":
+		return []string{item.Gross.String()}
+	case "insuranceAndHF":
+		return []string{item.InsuranceAndHF.String()}
+	case "tax":
+		return []string{item.Tax.String()}
+	case "net":
+		return []string{item.Gross.Sub(item.InsuranceAndHF).Sub(item.Tax).String()}
+	case "consumptionProportion":
+		return []string{item.ConsumptionProportion.String()}
+	case "comment":
+		return []string{item.Comment}
+	case "tag":
+		ret := make([]string, 0, len(item.Tags))
+		for k, v := range item.Tags {
+			ret = append(ret, k+":"+v)
+		}
+		return ret
+	}
+	if len(field) > len("tag.") && field[:len("tag.")] == "tag." {
+		if v, ok := item.Tags[field[len("tag."):]]; ok {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
+// GoodsSummary 交易中商品数量的汇总
+type GoodsSummary struct {
+	// 商品名
+	Name string `json:"name" yaml:"name"`
+	// 托管机构
+	Custodian string `json:"custodian,omitempty" yaml:"custodian,omitempty"`
+	// 涉及该商品的交易数量
+	Count int `json:"count" yaml:"count"`
+	// 流入数量（作为目标商品）
+	In decimal.Decimal `json:"in" yaml:"in"`
+	// 流出数量（作为源商品）
+	Out decimal.Decimal `json:"out" yaml:"out"`
+	// 净流入数量
+	Net decimal.Decimal `json:"net" yaml:"net"`
+}
+
+// SummarizeTransactions 按托管机构和商品汇总交易中的商品数量
+func SummarizeTransactions(transactions []v1.Transaction) []GoodsSummary {
+	type key struct{ custodian, name string }
+	summaries := map[key]*GoodsSummary{}
+	get := func(g *v1.Goods) *GoodsSummary {
+		k := key{custodian: g.Custodian, name: g.Name}
+		if s, ok := summaries[k]; ok {
+			return s
+		}
+		s := &GoodsSummary{Name: g.Name, Custodian: g.Custodian}
+		summaries[k] = s
+		return s
+	}
+	for _, t := range transactions {
+		var from, to *GoodsSummary
+		if t.From != nil {
+			from = get(t.From)
+			from.Count++
+			from.Out = from.Out.Add(t.From.Quantity)
+		}
+		if t.To != nil {
+			to = get(t.To)
+			// 两侧为同一商品（如分红）时只计一次
+			if to != from {
+				to.Count++
+			}
+			to.In = to.In.Add(t.To.Quantity)
+		}
+	}
+
+	ret := make([]GoodsSummary, 0, len(summaries))
+	for _, s := range summaries {
+		s.Net = s.In.Sub(s.Out)
+		ret = append(ret, *s)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Custodian != ret[j].Custodian {
+			return ret[i].Custodian < ret[j].Custodian
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// IncomeSummary 收入汇总
+type IncomeSummary struct {
+	// 收入项数量
+	Count int `json:"count" yaml:"count"`
+	// 税前总额
+	Gross decimal.Decimal `json:"gross" yaml:"gross"`
+	// 保险和住房公积金
+	InsuranceAndHF decimal.Decimal `json:"insuranceAndHF" yaml:"insuranceAndHF"`
+	// 税
+	Tax decimal.Decimal `json:"tax" yaml:"tax"`
+	// 税后净额
+	Net decimal.Decimal `json:"net" yaml:"net"`
+}
+
+// SummarizeIncome 汇总收入
+func SummarizeIncome(items []v1.IncomeItem) IncomeSummary {
+	ret := IncomeSummary{Count: len(items)}
+	for _, item := range items {
+		ret.Gross = ret.Gross.Add(item.Gross)
+		ret.InsuranceAndHF = ret.InsuranceAndHF.Add(item.InsuranceAndHF)
+		ret.Tax = ret.Tax.Add(item.Tax)
+	}
+	ret.Net = ret.Gross.Sub(ret.InsuranceAndHF).Sub(ret.Tax)
+	return ret
+}
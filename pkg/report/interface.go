@@ -11,4 +11,6 @@ type TextOptions struct {
 type Report interface {
 	// Text 输出文本格式的报告
 	Text(w io.Writer, opts TextOptions) error
+	// Data 返回报告数据，用于以 JSON 或 YAML 等结构化格式输出
+	Data() interface{}
 }
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	"github.com/yhlooo/dragon-acct/pkg/query"
+)
+
+// 接口返回的错误
+var (
+	errNotFound         = errors.New("not found")
+	errMethodNotAllowed = errors.New("method not allowed")
+	errNotLoaded        = errors.New("ledger not loaded yet")
+)
+
+// Handler 返回 HTTP 处理器
+//
+// 接口：
+//
+//	GET /                      接口列表及账本加载状态
+//	GET /ui/                   网页仪表盘
+//	GET /assets/report         资产报告（ showHistory 指定是否包含历史持仓）
+//	GET /income/report         收入报告
+//	GET /transactions?filter=  交易（ filter 为 dragon query 的过滤表达式）
+//	GET /income?filter=        收入明细
+//	GET /checkpoints           检查点及各检查点的总体情况
+//	GET /goods                 商品信息
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.Handle("/ui/", http.StripPrefix("/ui/", webHandler()))
+	mux.HandleFunc("/assets/report", s.withState(func(w http.ResponseWriter, r *http.Request, state *State) {
+		// 请求的 showHistory 与服务选项不同时重新分析
+		if v := r.URL.Query().Get("showHistory"); v != "" {
+			showHistory, err := strconv.ParseBool(v)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid showHistory %q: %w", v, err))
+				return
+			}
+			if showHistory != s.opts.ShowHistory {
+				rep, err := s.analyseAssets(r.Context(), state.Data, showHistory)
+				if err != nil {
+					writeError(w, r, http.StatusInternalServerError, err)
+					return
+				}
+				writeJSON(w, r, http.StatusOK, rep.Data())
+				return
+			}
+		}
+		writeJSON(w, r, http.StatusOK, state.Assets.Data())
+	}))
+	mux.HandleFunc("/income/report", s.withState(func(w http.ResponseWriter, r *http.Request, state *State) {
+		writeJSON(w, r, http.StatusOK, state.Income.Data())
+	}))
+	mux.HandleFunc("/transactions", s.withState(func(w http.ResponseWriter, r *http.Request, state *State) {
+		expr, err := query.Parse(r.URL.Query().Get("filter"), query.TransactionFields)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		transactions := query.Filter(expr, state.Data.Assets.Transactions, query.NewTransactionRecord)
+		writeJSON(w, r, http.StatusOK, map[string]interface{}{
+			"count":        len(transactions),
+			"transactions": emptyIfNil(transactions),
+			"sums":         query.SummarizeTransactions(transactions),
+		})
+	}))
+	mux.HandleFunc("/income", s.withState(func(w http.ResponseWriter, r *http.Request, state *State) {
+		expr, err := query.Parse(r.URL.Query().Get("filter"), query.IncomeFields)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		items := query.Filter(expr, state.Data.Income.Details, query.NewIncomeRecord)
+		writeJSON(w, r, http.StatusOK, map[string]interface{}{
+			"count":   len(items),
+			"details": emptyIfNil(items),
+			"sums":    query.SummarizeIncome(items),
+		})
+	}))
+	mux.HandleFunc("/checkpoints", s.withState(func(w http.ResponseWriter, r *http.Request, state *State) {
+		var summaries []analyzersassets.CheckpointData
+		if assets, ok := state.Assets.(*analyzersassets.Report); ok {
+			summaries = assets.ReportData().Checkpoints
+		}
+		writeJSON(w, r, http.StatusOK, map[string]interface{}{
+			"checkpoints": emptyIfNil(state.Data.Assets.Checkpoints),
+			"summaries":   emptyIfNil(summaries),
+		})
+	}))
+	mux.HandleFunc("/goods", s.withState(func(w http.ResponseWriter, r *http.Request, state *State) {
+		writeJSON(w, r, http.StatusOK, emptyIfNil(state.Data.Assets.Goods))
+	}))
+	return mux
+}
+
+// handleIndex 返回接口列表及账本加载状态
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		writeError(w, r, http.StatusNotFound, errNotFound)
+		return
+	}
+	ret := map[string]interface{}{
+		"ui": "/ui/",
+		"endpoints": []string{
+			"/assets/report?showHistory=", "/income/report", "/transactions?filter=", "/income?filter=", "/checkpoints", "/goods",
+		},
+	}
+	if state := s.State(); state != nil {
+		ret["loadedAt"] = state.LoadedAt.Format(time.RFC3339)
+		if state.Err != nil {
+			ret["error"] = state.Err.Error()
+		}
+	}
+	writeJSON(w, r, http.StatusOK, ret)
+}
+
+// withState 校验请求方法及账本加载状态后调用 handler
+func (s *Server) withState(
+	handler func(w http.ResponseWriter, r *http.Request, state *State),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, r, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			return
+		}
+		state := s.State()
+		switch {
+		case state == nil:
+			writeError(w, r, http.StatusServiceUnavailable, errNotLoaded)
+			return
+		case state.Err != nil:
+			writeError(w, r, http.StatusInternalServerError, state.Err)
+			return
+		}
+		w.Header().Set("Last-Modified", state.LoadedAt.UTC().Format(http.TimeFormat))
+		handler(w, r, state)
+	}
+}
+
+// writeJSON 以 JSON 格式返回数据
+func writeJSON(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
+	buf := &bytes.Buffer{}
+	if err := collector.EncodeJSON(buf, data); err != nil {
+		logr.FromContextOrDiscard(r.Context()).Error(err, "encode response error")
+		code = http.StatusInternalServerError
+		buf.Reset()
+		_ = collector.EncodeJSON(buf, map[string]string{"error": err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// writeError 以 JSON 格式返回错误
+func writeError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	writeJSON(w, r, code, map[string]string{"error": err.Error()})
+}
+
+// emptyIfNil 将 nil 切片转为空切片，使其序列化为 [] 而不是 null
+func emptyIfNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+)
+
+// TestServer_Handler 测试 Server.Handler 方法
+func TestServer_Handler(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"assets_goods.csv": "Name,Code,Risk,Price,Flags\nCNY,,R0,1,Base\n",
+		"assets_transactions.csv": "Date,From.Quantity,From.Name,From.Custodian,To.Quantity,To.Name,To.Custodian,Reason,Comment\n" +
+			"2024-01-02,,,,10000,CNY,Bank,工资,\n" +
+			"2024-01-03,100,CNY,Bank,,,,消费,\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write file error: %v", err)
+		}
+	}
+
+	s := New(Options{Dir: dir, Performance: analyzersassets.PeriodYear})
+	handler := s.Handler()
+	get := func(target string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		ret := map[string]interface{}{}
+		_ = json.Unmarshal(w.Body.Bytes(), &ret)
+		return w.Code, ret
+	}
+
+	if code, _ := get("/assets/report"); code != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status code before loading: %d (expected: %d)", code, http.StatusServiceUnavailable)
+	}
+	if err := s.Reload(context.Background()); err != nil {
+		t.Fatalf("reload error: %v", err)
+	}
+
+	code, body := get("/transactions?filter=reason%3D工资")
+	if code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d (expected: %d)", code, http.StatusOK)
+	}
+	if body["count"] != float64(1) {
+		t.Errorf("unexpected count: %v (expected: 1)", body["count"])
+	}
+	if code, _ := get("/transactions?filter=reason"); code != http.StatusBadRequest {
+		t.Errorf("unexpected status code for invalid filter: %d (expected: %d)", code, http.StatusBadRequest)
+	}
+	code, body = get("/assets/report")
+	if code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d (expected: %d)", code, http.StatusOK)
+	}
+	if total, _ := body["total"].(map[string]interface{}); total["value"] != "9900" {
+		t.Errorf("unexpected total: %v (expected value: 9900)", body["total"])
+	}
+	if performance, _ := body["performance"].([]interface{}); len(performance) == 0 {
+		t.Errorf("performance not included in assets report: %v", body["performance"])
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui/", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "app.js") {
+		t.Errorf("unexpected dashboard response: %d %q", w.Code, w.Body.String())
+	}
+	if code, _ := get("/unknown"); code != http.StatusNotFound {
+		t.Errorf("unexpected status code for unknown path: %d (expected: %d)", code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	analyzerincome "github.com/yhlooo/dragon-acct/pkg/analyzers/income"
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+	"github.com/yhlooo/dragon-acct/pkg/report"
+	"github.com/yhlooo/dragon-acct/pkg/utils/watch"
+)
+
+// Options 服务选项
+type Options struct {
+	// 账本目录
+	Dir string
+	// 监听地址
+	Listen string
+	// 检查账本文件变化的间隔，为 0 时不检查
+	WatchInterval time.Duration
+	// 资产报告中显示历史持仓
+	ShowHistory bool
+	// 重复交易处理策略
+	Duplicates collector.DuplicatesPolicy
+	// 定期生成检查点的周期
+	PeriodicCheckpoints analyzersassets.Period
+	// 计算各自然周期表现的周期
+	Performance analyzersassets.Period
+	// 收入分析选项
+	Income analyzerincome.Options
+}
+
+// Server 提供账本数据和报告的 HTTP 服务
+type Server struct {
+	opts Options
+
+	lock  sync.RWMutex
+	state *State
+}
+
+// State 账本及分析结果
+type State struct {
+	// 合并后的账本数据
+	Data *v1.Root
+	// 资产报告
+	Assets report.Report
+	// 收入报告
+	Income report.Report
+	// 加载时间
+	LoadedAt time.Time
+	// 加载错误
+	Err error
+}
+
+// New 创建 Server
+func New(opts Options) *Server {
+	return &Server{opts: opts}
+}
+
+// Reload 重新收集并分析账本
+//
+// 出错时同样更新状态，使接口返回错误，避免返回与账本文件不一致的数据
+func (s *Server) Reload(ctx context.Context) error {
+	state := &State{LoadedAt: time.Now()}
+	state.Data, state.Assets, state.Income, state.Err = s.load(ctx)
+
+	s.lock.Lock()
+	s.state = state
+	s.lock.Unlock()
+	return state.Err
+}
+
+// load 收集并分析账本
+func (s *Server) load(ctx context.Context) (*v1.Root, report.Report, report.Report, error) {
+	data, err := collector.Collect(ctx, s.opts.Dir, collector.Options{Duplicates: s.opts.Duplicates})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("collect error: %w", err)
+	}
+	assets, err := s.analyseAssets(ctx, data, s.opts.ShowHistory)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("analyse assets error: %w", err)
+	}
+	// 分析时可能会修改数据（如排序），使用副本以免影响接口返回的原始数据
+	incomeData := data.Income
+	incomeData.Details = append([]v1.IncomeItem(nil), data.Income.Details...)
+	income, err := analyzerincome.Analyse(ctx, &incomeData, s.opts.Income)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("analyse income error: %w", err)
+	}
+	return data, assets, income, nil
+}
+
+// analyseAssets 分析资产数据，包括住房公积金
+//
+// 分析时可能会修改数据（如排序），使用副本以免影响接口返回的原始数据
+func (s *Server) analyseAssets(ctx context.Context, data *v1.Root, showHistory bool) (report.Report, error) {
+	assets := data.Assets
+	assets.Transactions = append([]v1.Transaction(nil), data.Assets.Transactions...)
+	analyzerincome.AddHousingFund(&assets, &data.Income, s.opts.Income.Insurance)
+	return analyzersassets.Analyse(ctx, &assets, analyzersassets.Options{
+		ShowHistory: showHistory,
+		Periodic:    s.opts.PeriodicCheckpoints,
+		Performance: s.opts.Performance,
+	})
+}
+
+// State 返回当前状态
+func (s *Server) State() *State {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.state
+}
+
+// Run 加载账本并开始提供服务，账本文件变化时重新加载，阻塞直到 ctx 结束
+func (s *Server) Run(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if err := s.Reload(ctx); err != nil {
+		logger.Error(err, "load ledger error")
+	}
+	if s.opts.WatchInterval > 0 {
+		go watch.Poll(ctx, s.opts.Dir, s.opts.WatchInterval, collector.IsSupportedFile, func() {
+			logger.Info("ledger changed, reloading")
+			if err := s.Reload(ctx); err != nil {
+				logger.Error(err, "reload ledger error")
+			}
+		})
+	}
+
+	srv := &http.Server{
+		Addr:              s.opts.Listen,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info(fmt.Sprintf("serving on http://%s , dashboard: http://%s/ui/", s.opts.Listen, s.opts.Listen))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve error: %w", err)
+	}
+	return nil
+}
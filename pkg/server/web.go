@@ -0,0 +1,22 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// webFS 内置的网页仪表盘
+//
+//go:embed web
+var webFS embed.FS
+
+// webHandler 返回网页仪表盘的 HTTP 处理器
+func webHandler() http.Handler {
+	sub, err := fs.Sub(webFS, "web")
+	if err != nil {
+		// 内置文件系统中一定存在 web 目录
+		panic(err)
+	}
+	return http.FileServer(http.FS(sub))
+}
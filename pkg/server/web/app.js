@@ -0,0 +1,325 @@
+'use strict';
+
+// API 与页面位于同一服务，页面路径为 /ui/
+const API = '../';
+
+const COLORS = [
+  '#0969da', '#1a7f37', '#bf8700', '#cf222e', '#8250df',
+  '#1b7c83', '#bc4c00', '#6e7781', '#4d2d00', '#a40e26',
+];
+
+const $ = (id) => document.getElementById(id);
+
+// fetchJSON 请求 API 并解析 JSON ，出错时抛出接口返回的错误信息
+async function fetchJSON(path) {
+  const resp = await fetch(API + path);
+  const body = await resp.json();
+  if (!resp.ok) {
+    throw new Error(body.error || resp.statusText);
+  }
+  return body;
+}
+
+// num 将接口返回的数值字符串转为数字
+function num(v) {
+  return v === undefined || v === null || v === '' ? 0 : Number(v);
+}
+
+function fmt(v, digits = 2) {
+  return num(v).toLocaleString(undefined, { minimumFractionDigits: digits, maximumFractionDigits: digits });
+}
+
+function pct(v) {
+  return (num(v) * 100).toFixed(2) + '%';
+}
+
+function signClass(v) {
+  const n = num(v);
+  return n > 0 ? 'pos' : n < 0 ? 'neg' : '';
+}
+
+// el 创建元素
+function el(tag, attrs = {}, ...children) {
+  const e = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs)) {
+    if (k === 'onclick') {
+      e.addEventListener('click', v);
+    } else {
+      e.setAttribute(k, v);
+    }
+  }
+  for (const c of children) {
+    e.append(c instanceof Node ? c : document.createTextNode(String(c)));
+  }
+  return e;
+}
+
+function svgEl(tag, attrs = {}, text) {
+  const e = document.createElementNS('http://www.w3.org/2000/svg', tag);
+  for (const [k, v] of Object.entries(attrs)) {
+    e.setAttribute(k, v);
+  }
+  if (text !== undefined) {
+    e.textContent = text;
+  }
+  return e;
+}
+
+// renderTable 渲染表格， columns 为 {title, value, num, class} 列表
+function renderTable(table, columns, rows, opts = {}) {
+  table.replaceChildren();
+  const thead = el('thead', {}, el('tr', {}, ...columns.map((c) => el('th', c.num ? { class: 'num' } : {}, c.title))));
+  const tbody = el('tbody');
+  for (const row of rows) {
+    const attrs = opts.rowAttrs ? opts.rowAttrs(row) : {};
+    const tr = el('tr', attrs);
+    for (const c of columns) {
+      const cls = [c.num ? 'num' : '', c.class ? c.class(row) : ''].filter(Boolean).join(' ');
+      tr.append(el('td', cls ? { class: cls } : {}, c.value(row)));
+    }
+    tbody.append(tr);
+  }
+  table.append(thead, tbody);
+  if (opts.footer) {
+    table.append(el('tfoot', {}, el('tr', {}, ...opts.footer.map((v, i) => el('td', columns[i].num ? { class: 'num' } : {}, v)))));
+  }
+}
+
+// renderPie 渲染环形图及图例
+function renderPie(container, items) {
+  container.replaceChildren();
+  const total = items.reduce((s, i) => s + Math.max(i.value, 0), 0);
+  const size = 180;
+  const r = 80;
+  const inner = 48;
+  const svg = svgEl('svg', { width: size, height: size, viewBox: `0 0 ${size} ${size}` });
+  let angle = -Math.PI / 2;
+  items.forEach((item, idx) => {
+    if (item.value <= 0 || total <= 0) {
+      return;
+    }
+    const frac = item.value / total;
+    const color = COLORS[idx % COLORS.length];
+    if (frac >= 0.9999) {
+      svg.append(svgEl('circle', { cx: size / 2, cy: size / 2, r: (r + inner) / 2, fill: 'none', stroke: color, 'stroke-width': r - inner }));
+      return;
+    }
+    const next = angle + frac * 2 * Math.PI;
+    const large = frac > 0.5 ? 1 : 0;
+    const p = (a, rad) => `${size / 2 + rad * Math.cos(a)} ${size / 2 + rad * Math.sin(a)}`;
+    const d = `M ${p(angle, r)} A ${r} ${r} 0 ${large} 1 ${p(next, r)} L ${p(next, inner)} A ${inner} ${inner} 0 ${large} 0 ${p(angle, inner)} Z`;
+    const path = svgEl('path', { d, fill: color });
+    path.append(svgEl('title', {}, `${item.label}: ${fmt(item.value)} (${(frac * 100).toFixed(2)}%)`));
+    svg.append(path);
+    angle = next;
+  });
+  const legend = el('ul', { class: 'legend' });
+  items.forEach((item, idx) => {
+    const ratio = total > 0 ? ((Math.max(item.value, 0) / total) * 100).toFixed(2) + '%' : '-';
+    legend.append(el('li', {},
+      el('span', { class: 'swatch', style: `background:${COLORS[idx % COLORS.length]}` }),
+      `${item.label} ${ratio}`));
+  });
+  container.append(svg, legend);
+}
+
+// chartFrame 创建带坐标轴的图表
+function chartFrame(container, labels, max, min = 0) {
+  container.replaceChildren();
+  const width = Math.max(480, labels.length * 48);
+  const height = 220;
+  const pad = { left: 72, right: 16, top: 12, bottom: 28 };
+  const svg = svgEl('svg', { width, height, viewBox: `0 0 ${width} ${height}` });
+  const plotW = width - pad.left - pad.right;
+  const plotH = height - pad.top - pad.bottom;
+  const range = max - min || 1;
+  const y = (v) => pad.top + plotH - ((v - min) / range) * plotH;
+  const x = (i) => pad.left + (labels.length > 1 ? (i / (labels.length - 1)) * plotW : plotW / 2);
+
+  for (let t = 0; t <= 4; t++) {
+    const v = min + (range * t) / 4;
+    svg.append(svgEl('line', { class: 'axis', x1: pad.left, x2: width - pad.right, y1: y(v), y2: y(v) }));
+    svg.append(svgEl('text', { x: pad.left - 6, y: y(v) + 4, 'text-anchor': 'end' }, fmt(v, 0)));
+  }
+  container.append(svg);
+  return { svg, x, y, plotW, pad, height };
+}
+
+// renderLine 渲染折线图
+function renderLine(container, points) {
+  if (points.length === 0) {
+    container.replaceChildren(el('p', { class: 'hint' }, 'No checkpoints.'));
+    return;
+  }
+  const values = points.map((p) => p.value);
+  const { svg, x, y, height } = chartFrame(container, points, Math.max(...values, 0), Math.min(...values, 0));
+  svg.append(svgEl('polyline', { class: 'line', points: points.map((p, i) => `${x(i)},${y(p.value)}`).join(' ') }));
+  points.forEach((p, i) => {
+    const dot = svgEl('circle', { class: 'dot', cx: x(i), cy: y(p.value), r: 3 });
+    dot.append(svgEl('title', {}, `${p.label}: ${fmt(p.value)}`));
+    svg.append(dot, svgEl('text', { x: x(i), y: height - 8, 'text-anchor': 'middle' }, p.label));
+  });
+}
+
+// renderStackedBars 渲染堆叠柱状图
+function renderStackedBars(container, labels, series) {
+  if (labels.length === 0) {
+    container.replaceChildren(el('p', { class: 'hint' }, 'No income.'));
+    return;
+  }
+  const totals = labels.map((_, i) => series.reduce((s, ser) => s + ser.values[i], 0));
+  const { svg, y, plotW, pad, height } = chartFrame(container, labels, Math.max(...totals, 0));
+  const step = plotW / labels.length;
+  const barW = Math.min(32, step * 0.7);
+  labels.forEach((label, i) => {
+    const cx = pad.left + step * (i + 0.5);
+    let acc = 0;
+    series.forEach((ser, si) => {
+      const v = ser.values[i];
+      const rect = svgEl('rect', {
+        x: cx - barW / 2, y: y(acc + v), width: barW, height: Math.max(y(acc) - y(acc + v), 0),
+        fill: COLORS[si % COLORS.length],
+      });
+      rect.append(svgEl('title', {}, `${label} ${ser.label}: ${fmt(v)}`));
+      svg.append(rect);
+      acc += v;
+    });
+    svg.append(svgEl('text', { x: cx, y: height - 8, 'text-anchor': 'middle' }, label));
+  });
+  const legend = el('ul', { class: 'legend' });
+  series.forEach((ser, si) => legend.append(el('li', {},
+    el('span', { class: 'swatch', style: `background:${COLORS[si % COLORS.length]}` }), ser.label)));
+  container.append(legend);
+}
+
+// quote 将值转为过滤表达式中的字符串
+function quote(v) {
+  return '"' + String(v).replace(/\\/g, '\\\\').replace(/"/g, '\\"') + '"';
+}
+
+// showTransactions 展示商品的交易
+async function showTransactions(goods, tr) {
+  document.querySelectorAll('#goods tr.selected').forEach((e) => e.classList.remove('selected'));
+  tr.classList.add('selected');
+  const name = quote(goods.name);
+  const custodian = quote(goods.custodian || '');
+  const filter = `(from.name=${name} from.custodian=${custodian}) or (to.name=${name} to.custodian=${custodian})`;
+  const data = await fetchJSON('transactions?filter=' + encodeURIComponent(filter));
+  $('transactions-title').textContent = `${goods.name} (${goods.custodian || '-'})`;
+  const side = (g) => (g ? `${fmt(g.quantity, 4).replace(/\.?0+$/, '')} ${g.name}` : '');
+  renderTable($('transactions-table'), [
+    { title: 'Date', value: (t) => t.data },
+    { title: 'From', value: (t) => side(t.from) },
+    { title: 'From Custodian', value: (t) => (t.from ? t.from.custodian || '' : '') },
+    { title: 'To', value: (t) => side(t.to) },
+    { title: 'To Custodian', value: (t) => (t.to ? t.to.custodian || '' : '') },
+    { title: 'Reason', value: (t) => t.reason || '' },
+    { title: 'Comment', value: (t) => t.comment || '' },
+  ], data.transactions);
+  $('transactions').hidden = false;
+  $('transactions').scrollIntoView({ behavior: 'smooth' });
+}
+
+function renderAssets(report) {
+  const total = report.total;
+  $('summary').replaceChildren(
+    ...[
+      ['Total value', fmt(total.value), ''],
+      ['P/L', fmt(total.profitAndLoss), signClass(total.profitAndLoss)],
+      ['RR', pct(total.rateOfReturn), signClass(total.rateOfReturn)],
+      ['XIRR', pct(total.annualizedRateOfReturn), signClass(total.annualizedRateOfReturn)],
+    ].map(([label, value, cls]) => el('div', { class: 'card' },
+      el('div', { class: 'label' }, label), el('div', { class: 'value ' + cls }, value))),
+  );
+
+  renderTable($('goods'), [
+    { title: 'Name', value: (g) => g.name },
+    { title: 'Custodian', value: (g) => g.custodian || '' },
+    { title: 'Code', value: (g) => g.code || '' },
+    { title: 'Risk', value: (g) => g.risk || '' },
+    { title: 'Price', num: true, value: (g) => fmt(g.price) },
+    { title: 'Quantity', num: true, value: (g) => fmt(g.quantity) },
+    { title: 'Value', num: true, value: (g) => fmt(g.value) },
+    { title: 'Ratio', num: true, value: (g) => pct(g.ratio) },
+    { title: 'P/L', num: true, value: (g) => fmt(g.profitAndLoss), class: (g) => signClass(g.profitAndLoss) },
+    { title: 'RR', num: true, value: (g) => pct(g.rateOfReturn), class: (g) => signClass(g.rateOfReturn) },
+    { title: 'XIRR', num: true, value: (g) => pct(g.annualizedRateOfReturn), class: (g) => signClass(g.annualizedRateOfReturn) },
+  ], report.allGoods, {
+    rowAttrs: (g) => ({
+      class: 'clickable' + (num(g.quantity) === 0 ? ' history' : ''),
+      onclick: (e) => showTransactions(g, e.currentTarget).catch(showError),
+    }),
+  });
+
+  renderPie($('chart-goods'), report.holding
+    .filter((g) => num(g.value) > 0)
+    .map((g) => ({ label: `${g.name} (${g.custodian || '-'})`, value: num(g.value) })));
+  renderPie($('chart-risks'), report.risks.map((r) => ({ label: r.risk, value: num(r.value) })));
+  renderPie($('chart-custodians'), report.custodians.map((c) => ({ label: c.custodian || '-', value: num(c.total) })));
+
+  const checkpoints = report.checkpoints || [];
+  renderLine($('chart-checkpoints'), checkpoints.map((c) => ({ label: c.date, value: num(c.value) })));
+  renderTable($('checkpoints'), [
+    { title: 'Date', value: (c) => c.date },
+    { title: 'Total', num: true, value: (c) => fmt(c.value) },
+    { title: 'P/L', num: true, value: (c) => fmt(c.profitAndLoss), class: (c) => signClass(c.profitAndLoss) },
+    { title: 'RR', num: true, value: (c) => pct(c.rateOfReturn), class: (c) => signClass(c.rateOfReturn) },
+    { title: 'XIRR', num: true, value: (c) => pct(c.annualizedRateOfReturn), class: (c) => signClass(c.annualizedRateOfReturn) },
+  ], checkpoints);
+}
+
+function renderIncome(report) {
+  // 按月聚合
+  const months = new Map();
+  for (const item of report.details) {
+    const month = item.date.slice(0, 7);
+    const m = months.get(month) || { takeHome: 0, tax: 0, insuranceAndHF: 0 };
+    m.takeHome += num(item.takeHome);
+    m.tax += num(item.tax);
+    m.insuranceAndHF += num(item.insuranceAndHF);
+    months.set(month, m);
+  }
+  const labels = [...months.keys()].sort();
+  renderStackedBars($('chart-income'), labels, [
+    { label: 'Take home', values: labels.map((l) => months.get(l).takeHome) },
+    { label: 'Tax', values: labels.map((l) => months.get(l).tax) },
+    { label: 'Insurance & HF', values: labels.map((l) => months.get(l).insuranceAndHF) },
+  ]);
+
+  renderTable($('income-groups'), [
+    { title: 'Tag', value: (g) => g.tag },
+    { title: 'Value', value: (g) => g.tagValue },
+    { title: 'Gross', num: true, value: (g) => fmt(g.gross) },
+    { title: 'Insurance & HF', num: true, value: (g) => fmt(g.insuranceAndHF) },
+    { title: 'Tax', num: true, value: (g) => fmt(g.tax) },
+    { title: 'Take Home', num: true, value: (g) => fmt(g.takeHome) },
+    { title: '%Consumption', num: true, value: (g) => pct(g.consumptionProportion) },
+    { title: 'Consumption', num: true, value: (g) => fmt(g.consumption) },
+  ], report.groups || []);
+}
+
+function showError(err) {
+  $('status').textContent = err.message;
+  $('status').className = 'error';
+}
+
+async function load() {
+  try {
+    const showHistory = $('show-history').checked;
+    const [index, assets, income] = await Promise.all([
+      fetchJSON(''),
+      fetchJSON('assets/report?showHistory=' + showHistory),
+      fetchJSON('income/report'),
+    ]);
+    renderAssets(assets);
+    renderIncome(income);
+    $('status').textContent = 'Loaded at ' + new Date(index.loadedAt).toLocaleString();
+    $('status').className = '';
+  } catch (err) {
+    showError(err);
+  }
+}
+
+$('reload').addEventListener('click', load);
+$('show-history').addEventListener('change', load);
+load();
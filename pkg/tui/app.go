@@ -0,0 +1,347 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/rivo/tview"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	analyzerincome "github.com/yhlooo/dragon-acct/pkg/analyzers/income"
+	"github.com/yhlooo/dragon-acct/pkg/collector"
+)
+
+// helpText 快捷键说明
+const helpText = "Tab/1-6 switch  s sort  / filter  h history  Enter transactions  Esc back  r reload  q quit"
+
+// Options 终端界面选项
+type Options struct {
+	// 账本目录
+	Dir string
+	// 显示历史持仓
+	ShowHistory bool
+	// 重复交易处理策略
+	Duplicates collector.DuplicatesPolicy
+	// 定期生成检查点的周期
+	PeriodicCheckpoints analyzersassets.Period
+	// 收入分析选项
+	Income analyzerincome.Options
+}
+
+// App 用于浏览账本的终端界面
+type App struct {
+	opts Options
+	ctx  context.Context
+	// 指定使用的屏幕，为 nil 时使用终端
+	screen tcell.Screen
+
+	app    *tview.Application
+	tabBar *tview.TextView
+	table  *tview.Table
+	filter *tview.InputField
+	status *tview.TextView
+	layout *tview.Flex
+
+	assets   *analyzersassets.Report
+	income   *analyzerincome.Report
+	loadErr  error
+	warnings []string
+
+	views   []*view
+	current int
+	// 展开的商品交易，为 nil 时显示当前标签页
+	detail *view
+	// 当前显示的行（已过滤和排序）
+	rows []Row
+}
+
+// view 标签页
+type view struct {
+	// 名称
+	name string
+	// 生成表格数据
+	build func() Table
+	// 排序列，小于 0 时不排序
+	sortCol int
+	// 降序
+	desc bool
+	// 过滤文本
+	filter string
+}
+
+// New 创建 App
+func New(opts Options) *App {
+	a := &App{opts: opts}
+	a.views = []*view{
+		{name: "Goods", build: a.assetsTable(func(r *analyzersassets.Report) Table {
+			return GoodsTable(r, a.opts.ShowHistory)
+		})},
+		{name: "Holdings", build: a.assetsTable(HoldingTable)},
+		{name: "Risks", build: a.assetsTable(RisksTable)},
+		{name: "Custodians", build: a.assetsTable(CustodiansTable)},
+		{name: "Checkpoints", build: a.assetsTable(CheckpointsTable)},
+		{name: "Income", build: func() Table {
+			if a.income == nil {
+				return Table{}
+			}
+			return IncomeTable(a.income)
+		}},
+	}
+	for _, v := range a.views {
+		v.sortCol = -1
+	}
+	return a
+}
+
+// assetsTable 返回基于资产报告生成表格的函数，报告未加载时返回空表格
+func (a *App) assetsTable(build func(r *analyzersassets.Report) Table) func() Table {
+	return func() Table {
+		if a.assets == nil {
+			return Table{}
+		}
+		return build(a.assets)
+	}
+}
+
+// Run 加载账本并运行终端界面，阻塞直到退出或 ctx 结束
+func (a *App) Run(ctx context.Context) error {
+	a.ctx = ctx
+	a.app = tview.NewApplication()
+	if a.screen != nil {
+		a.app.SetScreen(a.screen)
+	}
+	a.tabBar = tview.NewTextView().SetDynamicColors(true).SetWrap(false)
+	a.table = tview.NewTable().SetSelectable(true, true).SetFixed(1, 0)
+	a.table.SetSelectedFunc(func(row, _ int) { a.expand(row) })
+	a.filter = tview.NewInputField().SetLabel("Filter: ")
+	a.filter.SetChangedFunc(func(text string) {
+		a.activeView().filter = text
+		a.render()
+	})
+	a.filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			a.filter.SetText("")
+		}
+		a.app.SetFocus(a.table)
+	})
+	a.status = tview.NewTextView().SetDynamicColors(true).SetWrap(false)
+	a.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.tabBar, 1, 0, false).
+		AddItem(a.table, 0, 1, true).
+		AddItem(a.filter, 1, 0, false).
+		AddItem(a.status, 1, 0, false)
+	a.app.SetInputCapture(a.handleKey)
+
+	a.load()
+	a.render()
+
+	go func() {
+		<-ctx.Done()
+		a.app.Stop()
+	}()
+	if err := a.app.SetRoot(a.layout, true).SetFocus(a.table).Run(); err != nil {
+		return fmt.Errorf("run tui error: %w", err)
+	}
+	return nil
+}
+
+// load 收集并分析账本
+//
+// 收集过程中的警告记录下来显示在状态栏，以免日志输出破坏界面
+func (a *App) load() {
+	a.warnings = nil
+	logger := funcr.New(func(_, args string) {
+		a.warnings = append(a.warnings, args)
+	}, funcr.Options{})
+	ctx := logr.NewContext(a.ctx, logger)
+
+	a.assets, a.income, a.loadErr = nil, nil, nil
+	data, err := collector.Collect(ctx, a.opts.Dir, collector.Options{Duplicates: a.opts.Duplicates})
+	if err != nil {
+		a.loadErr = fmt.Errorf("collect error: %w", err)
+		return
+	}
+	assetsData := data.Assets
+	analyzerincome.AddHousingFund(&assetsData, &data.Income, a.opts.Income.Insurance)
+	assets, err := analyzersassets.Analyse(ctx, &assetsData, analyzersassets.Options{
+		ShowHistory: a.opts.ShowHistory,
+		Periodic:    a.opts.PeriodicCheckpoints,
+	})
+	if err != nil {
+		a.loadErr = fmt.Errorf("analyse assets error: %w", err)
+		return
+	}
+	income, err := analyzerincome.Analyse(ctx, &data.Income, a.opts.Income)
+	if err != nil {
+		a.loadErr = fmt.Errorf("analyse income error: %w", err)
+		return
+	}
+	a.assets, _ = assets.(*analyzersassets.Report)
+	a.income, _ = income.(*analyzerincome.Report)
+}
+
+// activeView 返回当前显示的视图
+func (a *App) activeView() *view {
+	if a.detail != nil {
+		return a.detail
+	}
+	return a.views[a.current]
+}
+
+// handleKey 处理快捷键
+func (a *App) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if a.filter.HasFocus() {
+		return event
+	}
+	switch event.Key() {
+	case tcell.KeyTab:
+		a.switchTo((a.current + 1) % len(a.views))
+		return nil
+	case tcell.KeyBacktab:
+		a.switchTo((a.current + len(a.views) - 1) % len(a.views))
+		return nil
+	case tcell.KeyEscape:
+		switch {
+		case a.detail != nil:
+			a.detail = nil
+			a.resetFilterInput()
+			a.render()
+		case a.activeView().filter != "":
+			a.filter.SetText("")
+		}
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	switch r := event.Rune(); {
+	case r == 'q':
+		a.app.Stop()
+	case r >= '1' && r < '1'+rune(len(a.views)):
+		a.switchTo(int(r - '1'))
+	case r == 's':
+		_, col := a.table.GetSelection()
+		v := a.activeView()
+		if v.sortCol == col {
+			v.desc = !v.desc
+		} else {
+			v.sortCol, v.desc = col, false
+		}
+		a.render()
+	case r == '/':
+		a.app.SetFocus(a.filter)
+	case r == 'h':
+		a.opts.ShowHistory = !a.opts.ShowHistory
+		a.render()
+	case r == 'r':
+		a.load()
+		a.render()
+	default:
+		return event
+	}
+	return nil
+}
+
+// switchTo 切换到指定标签页
+func (a *App) switchTo(i int) {
+	a.current = i
+	a.detail = nil
+	a.resetFilterInput()
+	a.table.Select(1, 0)
+	a.table.ScrollToBeginning()
+	a.render()
+}
+
+// resetFilterInput 将过滤输入框的内容设为当前视图的过滤文本
+func (a *App) resetFilterInput() {
+	// SetText 会触发 ChangedFunc ，其中会设置当前视图的过滤文本
+	a.filter.SetText(a.activeView().filter)
+}
+
+// expand 展开指定行的交易
+func (a *App) expand(row int) {
+	if a.detail != nil || row < 1 || row > len(a.rows) || a.rows[row-1].Transactions == nil {
+		return
+	}
+	selected := a.rows[row-1]
+	transactions := selected.Transactions
+	a.detail = &view{
+		name:    fmt.Sprintf("Transactions of %s", strings.Join(selected.Cells[:2], " / ")),
+		build:   func() Table { return TransactionsTable(transactions) },
+		sortCol: -1,
+	}
+	a.resetFilterInput()
+	a.table.Select(1, 0)
+	a.table.ScrollToBeginning()
+	a.render()
+}
+
+// render 重新绘制界面
+func (a *App) render() {
+	// 标签栏
+	var tabs []string
+	for i, v := range a.views {
+		if i == a.current {
+			tabs = append(tabs, fmt.Sprintf("[black:white] %d %s [-:-]", i+1, v.name))
+		} else {
+			tabs = append(tabs, fmt.Sprintf(" %d %s ", i+1, v.name))
+		}
+	}
+	if a.detail != nil {
+		tabs = append(tabs, fmt.Sprintf(" > [yellow]%s[-]", tview.Escape(a.detail.name)))
+	}
+	a.tabBar.SetText(strings.Join(tabs, "|"))
+
+	// 表格
+	v := a.activeView()
+	t := v.build().Filtered(v.filter)
+	if v.sortCol >= 0 {
+		t = t.Sorted(v.sortCol, v.desc)
+	}
+	a.rows = t.Rows
+	a.table.Clear()
+	for col, name := range t.Header {
+		if col == v.sortCol {
+			if v.desc {
+				name += " ▼"
+			} else {
+				name += " ▲"
+			}
+		}
+		a.table.SetCell(0, col, tview.NewTableCell(tview.Escape(name)).
+			SetAttributes(tcell.AttrBold).
+			SetAlign(alignOf(t, col)).
+			SetSelectable(false))
+	}
+	for i, row := range t.Rows {
+		for col, text := range row.Cells {
+			a.table.SetCell(i+1, col, tview.NewTableCell(tview.Escape(text)).SetAlign(alignOf(t, col)))
+		}
+	}
+
+	// 状态栏
+	status := fmt.Sprintf("%d row(s)", len(t.Rows))
+	if a.opts.ShowHistory {
+		status += "  [history]"
+	}
+	switch {
+	case a.loadErr != nil:
+		status = fmt.Sprintf("[red]%s[-]", tview.Escape(a.loadErr.Error()))
+	case len(a.warnings) > 0:
+		status += fmt.Sprintf("  [yellow]%d warning(s): %s[-]", len(a.warnings), tview.Escape(a.warnings[0]))
+	}
+	a.status.SetText(status + "  |  " + helpText)
+}
+
+// alignOf 返回列的 tview 对齐方式
+func alignOf(t Table, col int) int {
+	if col < len(t.Align) && t.Align[col] == AlignRight {
+		return tview.AlignRight
+	}
+	return tview.AlignLeft
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// TestApp_Run 测试 App.Run 方法
+func TestApp_Run(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"assets_goods.csv": "Name,Code,Risk,Price,Flags\nCNY,,R0,1,Base\nAAPL,AAPL,R4,120,\n",
+		"assets_transactions.csv": "Date,From.Quantity,From.Name,From.Custodian,To.Quantity,To.Name,To.Custodian,Reason,Comment\n" +
+			"2024-01-02,,,,10000,CNY,Bank,工资,\n" +
+			"2024-01-03,100,CNY,Bank,1,AAPL,Bank,买入,\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write file error: %v", err)
+		}
+	}
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	a := New(Options{Dir: dir})
+	a.screen = screen
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- a.Run(ctx) }()
+
+	// 依次输入按键，并等待屏幕中出现期望的内容
+	steps := []struct {
+		keys     string
+		key      tcell.Key
+		expected string
+	}{
+		{expected: "AAPL"},
+		// 持仓页
+		{keys: "2", expected: "Ratio"},
+		// 展开 CNY 的交易
+		{key: tcell.KeyEnter, expected: "CNY(Bank)"},
+		// 按日期降序排序
+		{keys: "ss", expected: "Date ▼"},
+		// 过滤
+		{keys: "/AAPL", key: tcell.KeyEnter, expected: "1 row(s)"},
+		{key: tcell.KeyEscape, expected: "Holdings"},
+		// 收入页
+		{keys: "6", expected: "Take Home"},
+	}
+	for i, step := range steps {
+		for _, r := range step.keys {
+			screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+		}
+		if step.key != 0 {
+			screen.InjectKey(step.key, 0, tcell.ModNone)
+		}
+		if !waitForText(screen, step.expected) {
+			t.Fatalf("step %d: %q not found on screen:\n%s", i, step.expected, screenText(screen))
+		}
+	}
+
+	screen.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("run error: %v", err)
+		}
+	case <-ctx.Done():
+		t.Errorf("app not stopped")
+	}
+}
+
+// waitForText 等待屏幕中出现指定文本
+func waitForText(screen tcell.SimulationScreen, text string) bool {
+	for i := 0; i < 100; i++ {
+		if strings.Contains(screenText(screen), text) {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return false
+}
+
+// screenText 返回屏幕中的文本
+func screenText(screen tcell.SimulationScreen) string {
+	cells, width, _ := screen.GetContents()
+	buf := &strings.Builder{}
+	for i, c := range cells {
+		if len(c.Runes) > 0 {
+			buf.WriteString(string(c.Runes))
+		}
+		if (i+1)%width == 0 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// 列对齐方式
+const (
+	AlignLeft = iota
+	AlignRight
+)
+
+// Table 表格数据
+type Table struct {
+	// 表头
+	Header []string
+	// 各列对齐方式
+	Align []int
+	// 行
+	Rows []Row
+}
+
+// Row 表格行
+type Row struct {
+	// 各列内容
+	Cells []string
+	// 该行相关的交易，为 nil 时不可展开
+	Transactions []v1.Transaction
+}
+
+// Sorted 返回按指定列排序后的表格
+//
+// 两个单元格都是数字（可带 % 后缀）时按数值比较，否则按字符串比较。排序是稳定的
+func (t Table) Sorted(col int, desc bool) Table {
+	rows := make([]Row, len(t.Rows))
+	copy(rows, t.Rows)
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := cell(rows[i], col), cell(rows[j], col)
+		if desc {
+			a, b = b, a
+		}
+		return compareCells(a, b) < 0
+	})
+	t.Rows = rows
+	return t
+}
+
+// Filtered 返回任一单元格包含 text （不区分大小写）的行组成的表格， text 为空时返回原表格
+func (t Table) Filtered(text string) Table {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return t
+	}
+	var rows []Row
+	for _, row := range t.Rows {
+		for _, c := range row.Cells {
+			if strings.Contains(strings.ToLower(c), text) {
+				rows = append(rows, row)
+				break
+			}
+		}
+	}
+	t.Rows = rows
+	return t
+}
+
+// cell 返回行中指定列的内容，列不存在时返回空字符串
+func cell(row Row, col int) string {
+	if col < 0 || col >= len(row.Cells) {
+		return ""
+	}
+	return row.Cells[col]
+}
+
+// compareCells 比较两个单元格，返回 -1 、 0 或 1
+func compareCells(a, b string) int {
+	da, errA := decimal.NewFromString(strings.TrimSuffix(a, "%"))
+	db, errB := decimal.NewFromString(strings.TrimSuffix(b, "%"))
+	if errA == nil && errB == nil {
+		return da.Cmp(db)
+	}
+	return strings.Compare(a, b)
+}
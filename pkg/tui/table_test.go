@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTable_Sorted 测试 Table.Sorted 方法
+func TestTable_Sorted(t *testing.T) {
+	table := Table{Rows: []Row{
+		{Cells: []string{"b", "10.00", "5.00%"}},
+		{Cells: []string{"a", "9.00", "-1.00%"}},
+		{Cells: []string{"c", "-", "20.00%"}},
+	}}
+	firstColumn := func(t Table) []string {
+		var ret []string
+		for _, row := range t.Rows {
+			ret = append(ret, row.Cells[0])
+		}
+		return ret
+	}
+
+	cases := []struct {
+		col      int
+		desc     bool
+		expected []string
+	}{
+		{col: 0, expected: []string{"a", "b", "c"}},
+		{col: 0, desc: true, expected: []string{"c", "b", "a"}},
+		// 数字按数值比较，非数字按字符串比较
+		{col: 1, expected: []string{"c", "a", "b"}},
+		{col: 2, expected: []string{"a", "b", "c"}},
+		{col: 2, desc: true, expected: []string{"c", "b", "a"}},
+		// 不存在的列保持原顺序
+		{col: 5, expected: []string{"b", "a", "c"}},
+	}
+	for i, c := range cases {
+		if got := firstColumn(table.Sorted(c.col, c.desc)); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, got)
+		}
+	}
+	if got := firstColumn(table); !reflect.DeepEqual(got, []string{"b", "a", "c"}) {
+		t.Errorf("original table modified: %v", got)
+	}
+
+	if got := firstColumn(table.Filtered(" B ")); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("unexpected filtered rows: %v", got)
+	}
+	if got := firstColumn(table.Filtered("%")); len(got) != 3 {
+		t.Errorf("unexpected filtered rows: %v", got)
+	}
+}
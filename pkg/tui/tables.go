@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	analyzersassets "github.com/yhlooo/dragon-acct/pkg/analyzers/assets"
+	analyzerincome "github.com/yhlooo/dragon-acct/pkg/analyzers/income"
+	v1 "github.com/yhlooo/dragon-acct/pkg/models/v1"
+)
+
+// GoodsTable 返回所有商品的表格
+func GoodsTable(r *analyzersassets.Report, showHistory bool) Table {
+	t := Table{
+		Header: []string{"Name", "Custodian", "Code", "Risk", "Price", "Quantity", "Value", "P/L", "RR", "XIRR"},
+		Align: []int{
+			AlignLeft, AlignLeft, AlignLeft, AlignLeft,
+			AlignRight, AlignRight, AlignRight, AlignRight, AlignRight, AlignRight,
+		},
+	}
+	for _, g := range r.AllGoods() {
+		if g.Quantity.IsZero() && !showHistory {
+			continue
+		}
+		t.Rows = append(t.Rows, Row{
+			Cells: []string{
+				g.Name,
+				g.Custodian,
+				g.Code,
+				string(g.Risk),
+				g.Price.StringFixedBank(2),
+				g.Quantity.StringFixedBank(2),
+				g.Value.StringFixedBank(2),
+				g.ProfitAndLoss.StringFixedBank(2),
+				percent(g.RateOfReturn),
+				percent(g.AnnualizedRateOfReturn),
+			},
+			Transactions: emptyIfNil(g.Transactions()),
+		})
+	}
+	return t
+}
+
+// HoldingTable 返回持仓分布的表格
+func HoldingTable(r *analyzersassets.Report) Table {
+	t := Table{
+		Header: []string{"Name", "Custodian", "Value", "Ratio"},
+		Align:  []int{AlignLeft, AlignLeft, AlignRight, AlignRight},
+	}
+	for _, g := range r.HoldingGoods() {
+		t.Rows = append(t.Rows, Row{
+			Cells:        []string{g.Name, g.Custodian, g.Value.StringFixedBank(2), percent(g.Ratio)},
+			Transactions: emptyIfNil(g.Transactions()),
+		})
+	}
+	return t
+}
+
+// RisksTable 返回风险分布的表格
+func RisksTable(r *analyzersassets.Report) Table {
+	t := Table{
+		Header: []string{"Risk", "Value", "Ratio"},
+		Align:  []int{AlignLeft, AlignRight, AlignRight},
+	}
+	for _, g := range r.Risks() {
+		t.Rows = append(t.Rows, Row{
+			Cells: []string{string(g.Risk), g.Value.StringFixedBank(2), percent(g.Ratio)},
+		})
+	}
+	return t
+}
+
+// CustodiansTable 返回托管机构分布的表格
+func CustodiansTable(r *analyzersassets.Report) Table {
+	baseGoods := r.BaseGoods()
+	t := Table{
+		Header: append(append([]string{"Custodian"}, baseGoods...), "Others", "Total", "Ratio"),
+		Align:  []int{AlignLeft},
+	}
+	for range t.Header[1:] {
+		t.Align = append(t.Align, AlignRight)
+	}
+	for _, c := range r.Custodians() {
+		cells := []string{c.Custodian}
+		for _, name := range baseGoods {
+			cells = append(cells, c.BaseGoods[name].StringFixedBank(2))
+		}
+		cells = append(cells, c.Others.StringFixedBank(2), c.Total.StringFixedBank(2), percent(c.Ratio))
+		t.Rows = append(t.Rows, Row{Cells: cells})
+	}
+	return t
+}
+
+// CheckpointsTable 返回检查点的表格
+func CheckpointsTable(r *analyzersassets.Report) Table {
+	t := Table{
+		Header: []string{"Date", "Total", "P/L", "RR", "XIRR"},
+		Align:  []int{AlignLeft, AlignRight, AlignRight, AlignRight, AlignRight},
+	}
+	for _, cp := range r.Checkpoints() {
+		profitAndLoss, rateOfReturn, annualizedRateOfReturn := cp.Report.TotalProfitAndLoss()
+		t.Rows = append(t.Rows, Row{
+			Cells: []string{
+				cp.Date.String(),
+				cp.Report.TotalValue().StringFixedBank(2),
+				profitAndLoss.StringFixedBank(2),
+				percent(rateOfReturn),
+				percent(annualizedRateOfReturn),
+			},
+		})
+	}
+	return t
+}
+
+// IncomeTable 返回收入明细的表格
+func IncomeTable(r *analyzerincome.Report) Table {
+	t := Table{
+		Header: []string{
+			"Date", "Gross", "Insurance & HF", "Tax", "Take Home", "%Consumption", "Consumption", "Tags", "Comment",
+		},
+		Align: []int{
+			AlignLeft, AlignRight, AlignRight, AlignRight, AlignRight, AlignRight, AlignRight, AlignLeft, AlignLeft,
+		},
+	}
+	for _, item := range r.Details() {
+		tags := make([]string, 0, len(item.Tags))
+		for k, v := range item.Tags {
+			tags = append(tags, fmt.Sprintf("%s:%s", k, v))
+		}
+		sort.Strings(tags)
+		t.Rows = append(t.Rows, Row{
+			Cells: []string{
+				item.Date.String(),
+				item.Gross.StringFixedBank(2),
+				item.InsuranceAndHF.StringFixedBank(2),
+				item.Tax.StringFixedBank(2),
+				item.TakeHome.StringFixedBank(2),
+				percent(item.ConsumptionProportion),
+				item.Consumption.StringFixedBank(2),
+				strings.Join(tags, " "),
+				item.Comment,
+			},
+		})
+	}
+	return t
+}
+
+// TransactionsTable 返回交易的表格
+func TransactionsTable(transactions []v1.Transaction) Table {
+	t := Table{
+		Header: []string{"Date", "From", "From Quantity", "To", "To Quantity", "Reason", "Comment"},
+		Align:  []int{AlignLeft, AlignLeft, AlignRight, AlignLeft, AlignRight, AlignLeft, AlignLeft},
+	}
+	for _, tx := range transactions {
+		fromName, fromQuantity := goodsCells(tx.From)
+		toName, toQuantity := goodsCells(tx.To)
+		t.Rows = append(t.Rows, Row{
+			Cells: []string{tx.Date.String(), fromName, fromQuantity, toName, toQuantity, tx.Reason, tx.Comment},
+		})
+	}
+	return t
+}
+
+// goodsCells 返回交易中商品的名称（含托管机构）和数量
+func goodsCells(g *v1.Goods) (name, quantity string) {
+	switch {
+	case g == nil:
+		return "", ""
+	case g.Name == analyzersassets.InternalBaseGoods:
+		// 检查点结转的上期期末价值
+		return "(checkpoint)", g.Quantity.StringFixedBank(2)
+	case g.Custodian != "":
+		return fmt.Sprintf("%s(%s)", g.Name, g.Custodian), g.Quantity.String()
+	}
+	return g.Name, g.Quantity.String()
+}
+
+// percent 将比例格式化为百分数
+func percent(d decimal.Decimal) string {
+	return d.Shift(2).StringFixedBank(2) + "%"
+}
+
+// emptyIfNil 将 nil 切片转为空切片，使行可展开
+func emptyIfNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
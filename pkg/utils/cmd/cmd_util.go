@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/bombsimon/logrusr/v4"
 	"github.com/go-logr/logr"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // SetLogger 设置命令日志，并返回 logr.Logger
@@ -56,3 +58,44 @@ func ChangeWorkingDirectory(cmd *cobra.Command, path string) error {
 
 	return nil
 }
+
+// SnapshotChangedFlags 记录命令行中指定的参数值，返回将这些值重新设置回去的函数
+//
+// 用于从配置文件加载选项后，使命令行参数优先于配置文件。切片类型的参数会被整体替换，
+// 映射类型的参数会合并到配置文件中的值上
+func SnapshotChangedFlags(flags *pflag.FlagSet) (restore func() error) {
+	type changedFlag struct {
+		flag  *pflag.Flag
+		value string
+		slice []string
+	}
+	var changed []changedFlag
+	flags.Visit(func(f *pflag.Flag) {
+		c := changedFlag{flag: f, value: f.Value.String()}
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			c.slice = s.GetSlice()
+		}
+		changed = append(changed, c)
+	})
+
+	return func() error {
+		for _, c := range changed {
+			var err error
+			switch v := c.flag.Value.(type) {
+			case pflag.SliceValue:
+				err = v.Replace(c.slice)
+			default:
+				value := c.value
+				if c.flag.Value.Type() == "stringToString" {
+					// 形如 [k1=v1,k2=v2]
+					value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+				}
+				err = c.flag.Value.Set(value)
+			}
+			if err != nil {
+				return fmt.Errorf("set flag %q to %q error: %w", c.flag.Name, c.value, err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package incometax
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// BasicDeductionPerMonth 每月基本减除费用
+var BasicDeductionPerMonth = decimal.New(5000, 0)
+
+// bracket 税率表的一级
+type bracket struct {
+	// 应纳税所得额上限（含），为零时无上限
+	upper decimal.Decimal
+	// 税率（百分比）
+	rate decimal.Decimal
+	// 速算扣除数
+	quickDeduction decimal.Decimal
+}
+
+// newBrackets 创建税率表，每项为 上限、税率（百分比）、速算扣除数
+func newBrackets(items ...[3]int64) []bracket {
+	ret := make([]bracket, len(items))
+	for i, item := range items {
+		ret[i] = bracket{
+			upper:          decimal.New(item[0], 0),
+			rate:           decimal.New(item[1], -2),
+			quickDeduction: decimal.New(item[2], 0),
+		}
+	}
+	return ret
+}
+
+var (
+	// annualBrackets 综合所得税率表（按年），也用于累计预扣法
+	annualBrackets = newBrackets(
+		[3]int64{36000, 3, 0},
+		[3]int64{144000, 10, 2520},
+		[3]int64{300000, 20, 16920},
+		[3]int64{420000, 25, 31920},
+		[3]int64{660000, 30, 52920},
+		[3]int64{960000, 35, 85920},
+		[3]int64{0, 45, 181920},
+	)
+	// monthlyBrackets 按月换算后的综合所得税率表，用于全年一次性奖金单独计税
+	monthlyBrackets = newBrackets(
+		[3]int64{3000, 3, 0},
+		[3]int64{12000, 10, 210},
+		[3]int64{25000, 20, 1410},
+		[3]int64{35000, 25, 2660},
+		[3]int64{55000, 30, 4410},
+		[3]int64{80000, 35, 7160},
+		[3]int64{0, 45, 15160},
+	)
+)
+
+// lookup 返回 amount 适用的税率表级次
+func lookup(brackets []bracket, amount decimal.Decimal) bracket {
+	for _, b := range brackets {
+		if b.upper.IsZero() || amount.LessThanOrEqual(b.upper) {
+			return b
+		}
+	}
+	return brackets[len(brackets)-1]
+}
+
+// ComprehensiveTax 返回综合所得应纳税所得额 taxable 对应的税额
+//
+// 累计预扣法中 taxable 为累计预扣预缴应纳税所得额，结果为累计应预扣预缴税额
+func ComprehensiveTax(taxable decimal.Decimal) decimal.Decimal {
+	if !taxable.IsPositive() {
+		return decimal.Zero
+	}
+	b := lookup(annualBrackets, taxable)
+	return taxable.Mul(b.rate).Sub(b.quickDeduction).RoundBank(2)
+}
+
+// BonusTax 返回全年一次性奖金单独计税时的税额
+//
+// 以奖金除以 12 个月得到的数额按月度税率表确定税率和速算扣除数
+func BonusTax(bonus decimal.Decimal) decimal.Decimal {
+	if !bonus.IsPositive() {
+		return decimal.Zero
+	}
+	b := lookup(monthlyBrackets, bonus.Div(decimal.New(12, 0)))
+	return bonus.Mul(b.rate).Sub(b.quickDeduction).RoundBank(2)
+}
@@ -0,0 +1,46 @@
+package incometax
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestComprehensiveTax 测试 ComprehensiveTax 方法
+func TestComprehensiveTax(t *testing.T) {
+	for _, c := range []struct {
+		taxable string
+		tax     string
+	}{
+		{taxable: "-100", tax: "0"},
+		{taxable: "0", tax: "0"},
+		{taxable: "36000", tax: "1080"},
+		{taxable: "36001", tax: "1080.1"},
+		{taxable: "200000", tax: "23080"},
+		{taxable: "1000000", tax: "268080"},
+	} {
+		ret := ComprehensiveTax(decimal.RequireFromString(c.taxable))
+		if !ret.Equal(decimal.RequireFromString(c.tax)) {
+			t.Errorf("taxable %s: expected %s, got %s", c.taxable, c.tax, ret)
+		}
+	}
+}
+
+// TestBonusTax 测试 BonusTax 方法
+func TestBonusTax(t *testing.T) {
+	for _, c := range []struct {
+		bonus string
+		tax   string
+	}{
+		{bonus: "0", tax: "0"},
+		{bonus: "36000", tax: "1080"},
+		// 比 36000 多 1 元，税率跳档
+		{bonus: "36001", tax: "3390.1"},
+		{bonus: "100000", tax: "9790"},
+	} {
+		ret := BonusTax(decimal.RequireFromString(c.bonus))
+		if !ret.Equal(decimal.RequireFromString(c.tax)) {
+			t.Errorf("bonus %s: expected %s, got %s", c.bonus, c.tax, ret)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrAborted 输入被中止（如遇到 EOF）
+var ErrAborted = errors.New("input aborted")
+
+// maxHintCandidates 提示中最多展示的候选项数量
+const maxHintCandidates = 8
+
+// NewPrompter 创建 Prompter
+func NewPrompter(r io.Reader, w io.Writer) *Prompter {
+	return &Prompter{r: bufio.NewReader(r), w: w}
+}
+
+// Prompter 基于行的交互式输入
+type Prompter struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+// Ask 输出提示并读取一行输入，输入为空时返回 defaultValue
+func (p *Prompter) Ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		_, _ = fmt.Fprintf(p.w, "%s [%s]: ", question, defaultValue)
+	} else {
+		_, _ = fmt.Fprintf(p.w, "%s: ", question)
+	}
+	line, err := p.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", ErrAborted
+		}
+		return "", fmt.Errorf("read input error: %w", err)
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return defaultValue, nil
+	}
+	return line, nil
+}
+
+// Choose 输出提示并读取一行输入，输入可以是候选项的唯一前缀，此时补全为该候选项
+//
+// 输入 "?" 时列出匹配的候选项并重新输入，输入为空或不是候选项时原样返回，由调用方校验
+func (p *Prompter) Choose(question, defaultValue string, candidates []string) (string, error) {
+	hint := question
+	if len(candidates) > 0 {
+		shown := candidates
+		if len(shown) > maxHintCandidates {
+			shown = shown[:maxHintCandidates]
+		}
+		hint = fmt.Sprintf("%s (%s", question, strings.Join(shown, ", "))
+		if len(candidates) > len(shown) {
+			hint += ", ..., ? to list all"
+		}
+		hint += ")"
+	}
+
+	for {
+		answer, err := p.Ask(hint, defaultValue)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			return "", nil
+		}
+		prefix, list := strings.CutSuffix(answer, "?")
+		matched := Complete(prefix, candidates)
+		if list {
+			_, _ = fmt.Fprintln(p.w, strings.Join(matched, "\n"))
+			continue
+		}
+		if len(matched) == 1 {
+			return matched[0], nil
+		}
+		return answer, nil
+	}
+}
+
+// Confirm 输出提示并读取是或否，输入为空时返回 defaultValue
+func (p *Prompter) Confirm(question string, defaultValue bool) (bool, error) {
+	def := "y/N"
+	if defaultValue {
+		def = "Y/n"
+	}
+	for {
+		answer, err := p.Ask(question+" ("+def+")", "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultValue, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// Complete 返回以 prefix 开头的候选项，存在与 prefix 完全相同的候选项时仅返回该项
+func Complete(prefix string, candidates []string) []string {
+	var ret []string
+	for _, c := range candidates {
+		if c == prefix {
+			return []string{c}
+		}
+		if strings.HasPrefix(c, prefix) {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
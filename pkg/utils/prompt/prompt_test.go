@@ -0,0 +1,49 @@
+package prompt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestPrompter_Choose 测试 Prompter.Choose 方法
+func TestPrompter_Choose(t *testing.T) {
+	candidates := []string{"AAPL", "CNY", "USD", "USDT"}
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "AA\n", expected: "AAPL"},
+		{input: "USD\n", expected: "USD"},
+		{input: "US\n", expected: "US"},
+		{input: "\n", expected: "CNY"},
+		{input: "US?\nUSDT\n", expected: "USDT"},
+		{input: "HKD", expected: "HKD"},
+	}
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		p := NewPrompter(strings.NewReader(c.input), out)
+		ret, err := p.Choose("Goods", "CNY", candidates)
+		if err != nil {
+			t.Errorf("input %q: unexpected error: %v", c.input, err)
+			continue
+		}
+		if ret != c.expected {
+			t.Errorf("input %q: unexpected result: %q (expected: %q)", c.input, ret, c.expected)
+		}
+	}
+
+	// 没有默认值时空输入不补全为唯一的候选项
+	p := NewPrompter(strings.NewReader("\n"), &bytes.Buffer{})
+	ret, err := p.Choose("Tag", "", []string{"bonus"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if ret != "" {
+		t.Errorf("unexpected result for empty input: %q (expected: \"\")", ret)
+	}
+
+	p = NewPrompter(strings.NewReader(""), &bytes.Buffer{})
+	if _, err := p.Choose("Goods", "", candidates); err != ErrAborted {
+		t.Errorf("unexpected error: %v (expected: %v)", err, ErrAborted)
+	}
+}
@@ -0,0 +1,90 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileState 文件状态
+type FileState struct {
+	// 修改时间
+	ModTime time.Time
+	// 大小
+	Size int64
+}
+
+// Snapshot 目录中文件状态的快照，键为文件路径
+type Snapshot map[string]FileState
+
+// Equal 判断两个快照是否相同
+func (s Snapshot) Equal(other Snapshot) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for path, state := range s {
+		o, ok := other[path]
+		if !ok || !o.ModTime.Equal(state.ModTime) || o.Size != state.Size {
+			return false
+		}
+	}
+	return true
+}
+
+// TakeSnapshot 获取目录中（不递归）满足 filter 的文件状态快照， filter 为 nil 时包含所有文件
+func TakeSnapshot(dir string, filter func(path string) bool) (Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("list %q error: %w", dir, err)
+	}
+	ret := Snapshot{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if filter != nil && !filter(path) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			// 文件可能在列出后被删除
+			continue
+		}
+		ret[path] = FileState{ModTime: info.ModTime(), Size: info.Size()}
+	}
+	return ret, nil
+}
+
+// Poll 定期检查目录中（不递归）满足 filter 的文件，文件新增、删除或修改时调用 onChange
+//
+// 文件变化后需保持一个检查间隔不再变化才调用 onChange ，以免连续保存或写入到一半时重复触发。
+// 阻塞直到 ctx 结束。获取快照出错时跳过本次检查
+func Poll(ctx context.Context, dir string, interval time.Duration, filter func(path string) bool, onChange func()) {
+	last, _ := TakeSnapshot(dir, filter)
+	pending := false
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		cur, err := TakeSnapshot(dir, filter)
+		if err != nil {
+			continue
+		}
+		if !cur.Equal(last) {
+			last = cur
+			pending = true
+			continue
+		}
+		if pending {
+			pending = false
+			onChange()
+		}
+	}
+}
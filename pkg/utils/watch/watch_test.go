@@ -0,0 +1,45 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPoll 测试 Poll 方法
+func TestPoll(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.yaml")
+	if err := os.WriteFile(path, []byte("a"), 0o644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var count atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Poll(ctx, dir, 20*time.Millisecond, nil, func() { count.Add(1) })
+	}()
+
+	// 连续多次修改只触发一次
+	time.Sleep(30 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(path, []byte(strings.Repeat("a", i+2)), 0o644); err != nil {
+			t.Fatalf("write file error: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+	<-done
+
+	if got := count.Load(); got != 1 {
+		t.Errorf("expected onChange called once, got %d", got)
+	}
+}